@@ -45,22 +45,21 @@ func main() {
 		<-sigCh
 
 		logger.Info("Received termination signal, shutting down...")
-
-		// Create a timeout context for shutdown
-		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
-		defer shutdownCancel()
-
-		if err := application.Shutdown(shutdownCtx); err != nil {
-			logger.Error("Error during shutdown", "error", err)
-		}
-
 		cancel()
 	}()
 
-	// Start the application
+	// Start the application, it returns once the context is cancelled
 	logger.Info("Starting Flash Cards Language Telegram Bot")
 	if err := application.Start(ctx); err != nil {
 		logger.Error("Application error", "error", err)
 		os.Exit(1)
 	}
+
+	// Create a timeout context for shutdown
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer shutdownCancel()
+
+	if err := application.Shutdown(shutdownCtx); err != nil {
+		logger.Error("Error during shutdown", "error", err)
+	}
 }
@@ -3,6 +3,7 @@ package app
 import (
 	"context"
 	"log/slog"
+	"time"
 
 	"github.com/supercakecrumb/flash-cards-language-tg-bot/internal/domain/services"
 	"github.com/supercakecrumb/flash-cards-language-tg-bot/internal/infrastructure/database"
@@ -18,6 +19,9 @@ type App struct {
 	logger *slog.Logger
 	db     *database.PostgresDB
 	bot    *telegram.Bot
+
+	// dictCache is nil when dictionary lookups aren't cached
+	dictCache *dictionary.CachingService
 }
 
 // NewApp creates a new application instance
@@ -41,33 +45,107 @@ func NewApp(config *Config, logger *slog.Logger) (*App, error) {
 	flashcardRepo := repository.NewFlashCardRepository(db.DB())
 	cardbankRepo := repository.NewCardBankRepository(db.DB())
 	reviewRepo := repository.NewReviewRepository(db.DB())
+	reviewLogRepo := repository.NewReviewLogRepository(db.DB())
 	statisticsRepo := repository.NewStatisticsRepository(db.DB())
 	settingsRepo := repository.NewSettingsRepository(db.DB())
+	conversationStateRepo := repository.NewConversationStateRepository(db.DB())
+	dictionaryCacheRepo := repository.NewDictionaryCacheRepository(db.DB())
 
-	// Initialize dictionary service
-	var dictService dictionary.DictionaryService
-	switch config.Dictionary.Provider {
-	case "freedictionary":
-		dictService = dictionary.NewFreeDictionaryService(config.Dictionary.APIKey)
-	default:
-		dictService = dictionary.NewFreeDictionaryService(config.Dictionary.APIKey)
+	// Initialize dictionary providers, each lookup tries the ones supporting the bank's languages in order
+	if len(config.Dictionary.UnknownProviders) > 0 {
+		logger.Warn("Skipping unknown dictionary providers in DICTIONARY_API",
+			"unknown", config.Dictionary.UnknownProviders,
+			"providers", config.Dictionary.Providers,
+		)
+	}
+	var providers []dictionary.Provider
+	for _, provider := range config.Dictionary.Providers {
+		switch provider {
+		case "freedictionary":
+			providers = append(providers, dictionary.Provider{Name: provider, Service: dictionary.NewFreeDictionaryService(config.Dictionary.APIKey)})
+		case "wiktionary":
+			providers = append(providers, dictionary.Provider{Name: provider, Service: dictionary.NewWiktionaryService()})
+		case "translation":
+			providers = append(providers, dictionary.Provider{Name: provider, Service: dictionary.NewTranslationService(config.Dictionary.TranslationKey)})
+		case "offline":
+			logger.Info("Indexing offline dictionary", "path", config.Dictionary.OfflinePath)
+			offline, err := dictionary.NewOfflineDictionaryService(config.Dictionary.OfflinePath, dictionary.Languages{
+				Source: config.Dictionary.OfflineSourceLanguage,
+				Target: config.Dictionary.OfflineTargetLanguage,
+			})
+			if err != nil {
+				logger.Error("Failed to load offline dictionary", "error", err, "path", config.Dictionary.OfflinePath)
+				return nil, err
+			}
+			providers = append(providers, dictionary.Provider{Name: provider, Service: offline})
+		}
+	}
+	dictService := dictionary.NewFallbackService(providers, dictionary.FallbackConfig{
+		Timeout:          config.Dictionary.Timeout,
+		Retries:          config.Dictionary.Retries,
+		Backoff:          200 * time.Millisecond,
+		BreakerThreshold: 5,
+		BreakerCooldown:  time.Minute,
+		Merge:            config.Dictionary.Merge,
+	}, logger)
+
+	// Cache lookups in front of the providers
+	var dictCache *dictionary.CachingService
+	if config.Dictionary.Cache != "off" {
+		var cacheStore dictionary.CacheStore
+		if config.Dictionary.Cache == "postgres" {
+			cacheStore = dictionary.NewPostgresCacheStore(dictionaryCacheRepo)
+		}
+
+		dictCache = dictionary.NewCachingService(dictService, cacheStore, dictionary.CacheConfig{
+			TTL:         config.Dictionary.CacheTTL,
+			NegativeTTL: config.Dictionary.CacheNegativeTTL,
+			MemorySize:  config.Dictionary.CacheSize,
+		}, logger)
+		dictService = dictCache
 	}
 
-	// Initialize spaced repetition algorithm
+	// Initialize default spaced repetition algorithm
 	algorithm := spaced_repetition.NewSM2Algorithm()
 
 	// Initialize services
 	userService := services.NewUserService(userRepo, logger)
 	flashcardService := services.NewFlashCardService(flashcardRepo, dictService, logger)
 	cardbankService := services.NewCardBankService(cardbankRepo, logger)
-	spacedRepService := services.NewSpacedRepetitionService(reviewRepo, flashcardRepo, algorithm, logger)
+	spacedRepService := services.NewSpacedRepetitionService(reviewRepo, reviewLogRepo, flashcardRepo, settingsRepo, algorithm, logger)
 	statsService := services.NewStatisticsService(statisticsRepo, logger)
 	settingsService := services.NewSettingsService(settingsRepo, logger)
 	adminService := services.NewAdminService(config.AdminIDs, userRepo, logger)
+	importService := services.NewImportService(flashcardRepo, reviewRepo, logger)
+	exportService := services.NewExportService(cardbankRepo, flashcardRepo, reviewRepo, logger)
+
+	// Initialize conversation state store
+	var stateStore telegram.StateStore
+	switch config.State.Store {
+	case "memory":
+		stateStore = telegram.NewMemoryStateStore(config.State.TTL)
+	default:
+		stateStore = telegram.NewPostgresStateStore(conversationStateRepo, config.State.TTL)
+	}
+
+	// Receive updates through a webhook instead of polling if configured
+	var webhook *telegram.WebhookConfig
+	if config.Telegram.Mode == "webhook" {
+		webhook = &telegram.WebhookConfig{
+			URL:         config.Telegram.Webhook.URL,
+			ListenAddr:  config.Telegram.Webhook.ListenAddr,
+			SecretToken: config.Telegram.Webhook.SecretToken,
+			CertFile:    config.Telegram.Webhook.CertFile,
+			KeyFile:     config.Telegram.Webhook.KeyFile,
+		}
+	}
 
 	// Initialize Telegram bot
 	bot, err := telegram.NewBot(
 		config.Telegram.Token,
+		config.Telegram.Workers,
+		config.Telegram.QueueSize,
+		webhook,
 		logger,
 		userService,
 		flashcardService,
@@ -76,27 +154,41 @@ func NewApp(config *Config, logger *slog.Logger) (*App, error) {
 		statsService,
 		settingsService,
 		adminService,
+		importService,
+		exportService,
+		stateStore,
 	)
 	if err != nil {
 		return nil, err
 	}
 
 	return &App{
-		config: config,
-		logger: logger,
-		db:     db,
-		bot:    bot,
+		config:    config,
+		logger:    logger,
+		db:        db,
+		bot:       bot,
+		dictCache: dictCache,
 	}, nil
 }
 
 // Start starts the application
 func (a *App) Start(ctx context.Context) error {
+	// Remove expired dictionary lookups in the background
+	if a.dictCache != nil {
+		go a.dictCache.Run(ctx)
+	}
+
 	// Start the bot
 	return a.bot.Start(ctx)
 }
 
 // Shutdown gracefully shuts down the application
 func (a *App) Shutdown(ctx context.Context) error {
+	// Finish the updates being handled before the database goes away
+	if err := a.bot.Shutdown(ctx); err != nil {
+		a.logger.Warn("Telegram bot did not finish handling updates in time", "error", err)
+	}
+
 	// Close database connection
 	if err := a.db.Close(); err != nil {
 		return err
@@ -2,17 +2,35 @@ package app
 
 import (
 	"errors"
+	"net/url"
 	"os"
+	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
+	"github.com/supercakecrumb/flash-cards-language-tg-bot/internal/domain/models"
+	"github.com/supercakecrumb/flash-cards-language-tg-bot/internal/infrastructure/telegram"
 )
 
+// webhookSecretPattern matches the secret tokens Telegram accepts
+var webhookSecretPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,256}$`)
+
 // Config represents the application configuration
 type Config struct {
 	Telegram struct {
-		Token string
+		Token     string
+		Workers   int // updates handled concurrently
+		QueueSize int // updates queued before receiving pauses
+		Mode      string
+		Webhook   struct {
+			URL         string
+			ListenAddr  string
+			SecretToken string
+			CertFile    string
+			KeyFile     string
+		}
 	}
 	Database struct {
 		Host     string
@@ -22,8 +40,25 @@ type Config struct {
 		Name     string
 	}
 	Dictionary struct {
-		Provider string
-		APIKey   string
+		Providers        []string // in order of preference for each language pair
+		UnknownProviders []string // listed in DICTIONARY_API but not supported, skipped
+		APIKey           string   // Free Dictionary API
+		TranslationKey   string   // MyMemory
+		// OfflinePath is a StarDict .ifo file or a Wiktextract JSONL extract
+		OfflinePath           string
+		OfflineSourceLanguage string
+		OfflineTargetLanguage string
+		Cache                 string // "postgres", "memory" or "off"
+		CacheTTL              time.Duration
+		CacheNegativeTTL      time.Duration
+		CacheSize             int
+		Timeout               time.Duration // per call to a provider
+		Retries               int           // extra calls to a failing provider
+		Merge                 bool          // combine the definitions of all providers
+	}
+	State struct {
+		Store string // "postgres" or "memory"
+		TTL   time.Duration
 	}
 	AdminIDs []int64
 	LogLevel string
@@ -42,6 +77,39 @@ func LoadConfig() (*Config, error) {
 		return nil, errors.New("TELEGRAM_TOKEN environment variable is required")
 	}
 
+	config.Telegram.Workers = telegram.DefaultWorkers
+	if workers := os.Getenv("BOT_WORKERS"); workers != "" {
+		value, err := strconv.Atoi(workers)
+		if err != nil || value <= 0 {
+			return nil, errors.New("BOT_WORKERS must be a positive number")
+		}
+		config.Telegram.Workers = value
+	}
+
+	config.Telegram.QueueSize = telegram.DefaultQueueSize
+	if queueSize := os.Getenv("BOT_QUEUE_SIZE"); queueSize != "" {
+		value, err := strconv.Atoi(queueSize)
+		if err != nil || value <= 0 {
+			return nil, errors.New("BOT_QUEUE_SIZE must be a positive number")
+		}
+		config.Telegram.QueueSize = value
+	}
+
+	// Update delivery configuration
+	config.Telegram.Mode = os.Getenv("BOT_MODE")
+	if config.Telegram.Mode == "" {
+		config.Telegram.Mode = "polling"
+	}
+	switch config.Telegram.Mode {
+	case "polling":
+	case "webhook":
+		if err := loadWebhookConfig(config); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.New("BOT_MODE must be either polling or webhook")
+	}
+
 	// Database configuration
 	config.Database.Host = os.Getenv("DB_HOST")
 	if config.Database.Host == "" {
@@ -71,12 +139,105 @@ func LoadConfig() (*Config, error) {
 	}
 
 	// Dictionary configuration
-	config.Dictionary.Provider = os.Getenv("DICTIONARY_API")
-	if config.Dictionary.Provider == "" {
-		config.Dictionary.Provider = "freedictionary"
+	const defaultProviders = "freedictionary,wiktionary,translation"
+	providers := os.Getenv("DICTIONARY_API")
+	if providers == "" {
+		providers = defaultProviders
+	}
+	for _, provider := range strings.Split(providers, ",") {
+		provider = strings.TrimSpace(provider)
+		switch provider {
+		case "freedictionary", "wiktionary", "translation", "offline":
+			config.Dictionary.Providers = append(config.Dictionary.Providers, provider)
+		case "":
+		default:
+			// Unknown providers are skipped rather than failing startup, they are logged when the app starts
+			config.Dictionary.UnknownProviders = append(config.Dictionary.UnknownProviders, provider)
+			continue
+		}
+
+		if provider == "offline" {
+			if err := loadOfflineDictionaryConfig(config); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if len(config.Dictionary.Providers) == 0 {
+		config.Dictionary.Providers = strings.Split(defaultProviders, ",")
 	}
 
 	config.Dictionary.APIKey = os.Getenv("DICTIONARY_API_KEY")
+	config.Dictionary.TranslationKey = os.Getenv("TRANSLATION_API_KEY")
+
+	config.Dictionary.Timeout = 5 * time.Second
+	if timeout := os.Getenv("DICTIONARY_TIMEOUT"); timeout != "" {
+		config.Dictionary.Timeout, err = time.ParseDuration(timeout)
+		if err != nil || config.Dictionary.Timeout <= 0 {
+			return nil, errors.New("invalid duration format in DICTIONARY_TIMEOUT")
+		}
+	}
+
+	config.Dictionary.Retries = 2
+	if retries := os.Getenv("DICTIONARY_RETRIES"); retries != "" {
+		value, err := strconv.Atoi(retries)
+		if err != nil || value < 0 {
+			return nil, errors.New("DICTIONARY_RETRIES must be zero or a positive number")
+		}
+		config.Dictionary.Retries = value
+	}
+
+	config.Dictionary.Merge = os.Getenv("DICTIONARY_MERGE") == "true"
+
+	config.Dictionary.Cache = os.Getenv("DICTIONARY_CACHE")
+	if config.Dictionary.Cache == "" {
+		config.Dictionary.Cache = "postgres"
+	}
+	if config.Dictionary.Cache != "postgres" && config.Dictionary.Cache != "memory" && config.Dictionary.Cache != "off" {
+		return nil, errors.New("DICTIONARY_CACHE must be postgres, memory or off")
+	}
+
+	config.Dictionary.CacheTTL = 30 * 24 * time.Hour
+	if ttl := os.Getenv("DICTIONARY_CACHE_TTL"); ttl != "" {
+		config.Dictionary.CacheTTL, err = time.ParseDuration(ttl)
+		if err != nil || config.Dictionary.CacheTTL <= 0 {
+			return nil, errors.New("invalid duration format in DICTIONARY_CACHE_TTL")
+		}
+	}
+
+	// Words that weren't found are retried sooner, dictionaries get new entries
+	config.Dictionary.CacheNegativeTTL = 24 * time.Hour
+	if ttl := os.Getenv("DICTIONARY_CACHE_NEGATIVE_TTL"); ttl != "" {
+		config.Dictionary.CacheNegativeTTL, err = time.ParseDuration(ttl)
+		if err != nil || config.Dictionary.CacheNegativeTTL <= 0 {
+			return nil, errors.New("invalid duration format in DICTIONARY_CACHE_NEGATIVE_TTL")
+		}
+	}
+
+	config.Dictionary.CacheSize = 1000
+	if size := os.Getenv("DICTIONARY_CACHE_SIZE"); size != "" {
+		value, err := strconv.Atoi(size)
+		if err != nil || value <= 0 {
+			return nil, errors.New("DICTIONARY_CACHE_SIZE must be a positive number")
+		}
+		config.Dictionary.CacheSize = value
+	}
+
+	// Conversation state configuration
+	config.State.Store = os.Getenv("STATE_STORE")
+	if config.State.Store == "" {
+		config.State.Store = "postgres"
+	}
+	if config.State.Store != "postgres" && config.State.Store != "memory" {
+		return nil, errors.New("STATE_STORE must be either postgres or memory")
+	}
+
+	config.State.TTL = 24 * time.Hour
+	if ttl := os.Getenv("STATE_TTL"); ttl != "" {
+		config.State.TTL, err = time.ParseDuration(ttl)
+		if err != nil || config.State.TTL <= 0 {
+			return nil, errors.New("invalid duration format in STATE_TTL")
+		}
+	}
 
 	// Admin IDs
 	adminIDsStr := os.Getenv("ADMIN_IDS")
@@ -99,3 +260,54 @@ func LoadConfig() (*Config, error) {
 
 	return config, nil
 }
+
+// loadWebhookConfig loads the webhook configuration used when BOT_MODE is webhook
+func loadWebhookConfig(config *Config) error {
+	config.Telegram.Webhook.URL = os.Getenv("WEBHOOK_URL")
+	webhookURL, err := url.Parse(config.Telegram.Webhook.URL)
+	if err != nil || webhookURL.Scheme != "https" || webhookURL.Host == "" {
+		return errors.New("WEBHOOK_URL must be a public https URL in webhook mode")
+	}
+
+	config.Telegram.Webhook.ListenAddr = os.Getenv("WEBHOOK_LISTEN_ADDR")
+	if config.Telegram.Webhook.ListenAddr == "" {
+		config.Telegram.Webhook.ListenAddr = ":8443"
+	}
+
+	// Telegram accepts 1-256 characters A-Z, a-z, 0-9, _ and -
+	config.Telegram.Webhook.SecretToken = os.Getenv("WEBHOOK_SECRET")
+	if !webhookSecretPattern.MatchString(config.Telegram.Webhook.SecretToken) {
+		return errors.New("WEBHOOK_SECRET must be 1-256 letters, digits, _ or - in webhook mode")
+	}
+
+	// TLS is optional, a reverse proxy can terminate it instead
+	config.Telegram.Webhook.CertFile = os.Getenv("WEBHOOK_CERT_FILE")
+	config.Telegram.Webhook.KeyFile = os.Getenv("WEBHOOK_KEY_FILE")
+	if (config.Telegram.Webhook.CertFile == "") != (config.Telegram.Webhook.KeyFile == "") {
+		return errors.New("WEBHOOK_CERT_FILE and WEBHOOK_KEY_FILE must be set together")
+	}
+
+	return nil
+}
+
+// loadOfflineDictionaryConfig loads the local dictionary file used by the offline provider
+func loadOfflineDictionaryConfig(config *Config) error {
+	config.Dictionary.OfflinePath = os.Getenv("OFFLINE_DICTIONARY_PATH")
+	if config.Dictionary.OfflinePath == "" {
+		return errors.New("OFFLINE_DICTIONARY_PATH environment variable is required for the offline dictionary")
+	}
+
+	// The languages of StarDict dictionaries, Wiktextract extracts only use the second one
+	languages := os.Getenv("OFFLINE_DICTIONARY_LANGUAGES")
+	if languages == "" {
+		languages = "en:en"
+	}
+	source, target, ok := strings.Cut(languages, ":")
+	if !ok || !models.IsValidLanguage(source) || !models.IsValidLanguage(target) {
+		return errors.New("OFFLINE_DICTIONARY_LANGUAGES must be two language codes such as de:en")
+	}
+	config.Dictionary.OfflineSourceLanguage = source
+	config.Dictionary.OfflineTargetLanguage = target
+
+	return nil
+}
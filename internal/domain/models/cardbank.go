@@ -1,18 +1,37 @@
 package models
 
 import (
+	"regexp"
 	"time"
 )
 
+// DefaultLanguage is the language of new card banks
+const DefaultLanguage = "en"
+
+// Roles of card bank members
+const (
+	RoleOwner  = "owner"  // created the bank
+	RoleEditor = "editor" // can add, edit and delete cards
+	RoleViewer = "viewer" // can only review cards
+)
+
+// languageCodePattern matches ISO 639-1 and ISO 639-3 language codes
+var languageCodePattern = regexp.MustCompile(`^[a-z]{2,3}$`)
+
 // CardBank represents a collection of flash cards
 type CardBank struct {
-	ID          int       `db:"id"`
-	Name        string    `db:"name"`
-	Description string    `db:"description"`
-	OwnerID     int       `db:"owner_id"`
-	IsPublic    bool      `db:"is_public"`
-	CreatedAt   time.Time `db:"created_at"`
-	UpdatedAt   time.Time `db:"updated_at"`
+	ID          int    `db:"id"`
+	Name        string `db:"name"`
+	Description string `db:"description"`
+	OwnerID     int    `db:"owner_id"`
+	IsPublic    bool   `db:"is_public"`
+	// SourceLanguage is the language of the words, TargetLanguage the language of their definitions
+	SourceLanguage string    `db:"source_language"`
+	TargetLanguage string    `db:"target_language"`
+	CardTemplate   string    `db:"card_template"` // template of cards that don't choose their own
+	ClozeCards     bool      `db:"cloze_cards"`   // whether cards with examples are also reviewed as cloze deletions
+	CreatedAt      time.Time `db:"created_at"`
+	UpdatedAt      time.Time `db:"updated_at"`
 }
 
 // BankMembership represents a user's membership in a card bank
@@ -20,7 +39,7 @@ type BankMembership struct {
 	ID         int       `db:"id"`
 	UserID     int       `db:"user_id"`
 	CardBankID int       `db:"card_bank_id"`
-	Role       string    `db:"role"` // "owner", "editor", "viewer"
+	Role       string    `db:"role"` // RoleOwner, RoleEditor or RoleViewer
 	CreatedAt  time.Time `db:"created_at"`
 	UpdatedAt  time.Time `db:"updated_at"`
 }
@@ -39,15 +58,23 @@ type GroupChat struct {
 func NewCardBank(name, description string, ownerID int, isPublic bool) *CardBank {
 	now := time.Now()
 	return &CardBank{
-		Name:        name,
-		Description: description,
-		OwnerID:     ownerID,
-		IsPublic:    isPublic,
-		CreatedAt:   now,
-		UpdatedAt:   now,
+		Name:           name,
+		Description:    description,
+		OwnerID:        ownerID,
+		IsPublic:       isPublic,
+		SourceLanguage: DefaultLanguage,
+		TargetLanguage: DefaultLanguage,
+		CardTemplate:   DefaultTemplate,
+		CreatedAt:      now,
+		UpdatedAt:      now,
 	}
 }
 
+// IsValidLanguage checks if a language code is a lowercase ISO 639 code such as "en" or "de"
+func IsValidLanguage(code string) bool {
+	return languageCodePattern.MatchString(code)
+}
+
 // NewBankMembership creates a new bank membership
 func NewBankMembership(userID, cardBankID int, role string) *BankMembership {
 	now := time.Now()
@@ -60,6 +87,11 @@ func NewBankMembership(userID, cardBankID int, role string) *BankMembership {
 	}
 }
 
+// CanEdit checks if the member may change the cards of the bank
+func (m *BankMembership) CanEdit() bool {
+	return m.Role == RoleOwner || m.Role == RoleEditor
+}
+
 // NewGroupChat creates a new group chat
 func NewGroupChat(telegramChatID int64, title string, cardBankID int) *GroupChat {
 	now := time.Now()
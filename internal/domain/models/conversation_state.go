@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+)
+
+// ConversationState represents the serialized state of a user's multi-step interaction with the bot
+type ConversationState struct {
+	TelegramID int64     `db:"telegram_id"`
+	State      string    `db:"state"` // JSON, owned by the bot
+	ExpiresAt  time.Time `db:"expires_at"`
+	UpdatedAt  time.Time `db:"updated_at"`
+}
+
+// NewConversationState creates a new conversation state that expires after ttl
+func NewConversationState(telegramID int64, state string, ttl time.Duration) *ConversationState {
+	now := time.Now()
+	return &ConversationState{
+		TelegramID: telegramID,
+		State:      state,
+		ExpiresAt:  now.Add(ttl),
+		UpdatedAt:  now,
+	}
+}
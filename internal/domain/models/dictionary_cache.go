@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+)
+
+// DictionaryCacheEntry represents the cached result of a dictionary lookup
+type DictionaryCacheEntry struct {
+	Key         string    `db:"cache_key"`
+	Definitions string    `db:"definitions"` // JSON, owned by the dictionary cache
+	ExpiresAt   time.Time `db:"expires_at"`
+	CreatedAt   time.Time `db:"created_at"`
+}
+
+// NewDictionaryCacheEntry creates a new dictionary cache entry that expires after ttl
+func NewDictionaryCacheEntry(key, definitions string, ttl time.Duration) *DictionaryCacheEntry {
+	now := time.Now()
+	return &DictionaryCacheEntry{
+		Key:         key,
+		Definitions: definitions,
+		ExpiresAt:   now.Add(ttl),
+		CreatedAt:   now,
+	}
+}
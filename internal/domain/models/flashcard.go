@@ -24,16 +24,54 @@ func (a *StringArray) Scan(value interface{}) error {
 	return json.Unmarshal(b, a)
 }
 
+// Card filters, by the review state of a user's cards
+const (
+	CardFilterNew      = "new"      // never reviewed
+	CardFilterLearning = "learning" // in the learning or relearning steps
+	CardFilterDue      = "due"      // reviewed before and due now
+	CardFilterMature   = "mature"   // scheduled at least MatureInterval days apart
+)
+
+// MatureInterval is the interval in days from which a card counts as mature
+const MatureInterval = 21
+
+// Card templates, the directions a flash card is reviewed in
+const (
+	TemplateForward = "forward" // word → definition
+	TemplateReverse = "reverse" // definition → word
+	TemplateBoth    = "both"    // both directions, scheduled independently
+
+	// DefaultTemplate is how cards were reviewed before templates existed
+	DefaultTemplate = TemplateReverse
+)
+
+// CardSearch selects cards of a bank
+type CardSearch struct {
+	BankID int
+	UserID int    // whose reviews the filter applies to
+	Text   string // words to find in the word, definition or examples, empty for all cards
+	Filter string // one of the CardFilter constants, empty for all cards
+	Limit  int
+	Offset int
+}
+
 // FlashCard represents a vocabulary flash card
 type FlashCard struct {
-	ID         int         `db:"id"`
-	CardBankID int         `db:"card_bank_id"`
-	Word       string      `db:"word"`
-	Definition string      `db:"definition"`
-	Examples   StringArray `db:"examples"`
-	ImageURL   string      `db:"image_url"`
-	CreatedAt  time.Time   `db:"created_at"`
-	UpdatedAt  time.Time   `db:"updated_at"`
+	ID           int         `db:"id"`
+	CardBankID   int         `db:"card_bank_id"`
+	Word         string      `db:"word"`
+	Definition   string      `db:"definition"`
+	Examples     StringArray `db:"examples"`
+	ImageURL     string      `db:"image_url"`
+	Phonetic     string      `db:"phonetic"`      // pronunciation, usually in IPA
+	AudioURL     string      `db:"audio_url"`     // where the pronunciation recording was found
+	AudioFileID  string      `db:"audio_file_id"` // the recording uploaded to Telegram
+	Synonyms     StringArray `db:"synonyms"`
+	Antonyms     StringArray `db:"antonyms"`
+	Template     string      `db:"template"`       // one of the Template constants, empty for the bank's template
+	PartOfSpeech string      `db:"part_of_speech"` // of the chosen definition, empty if unknown
+	CreatedAt    time.Time   `db:"created_at"`
+	UpdatedAt    time.Time   `db:"updated_at"`
 }
 
 // NewFlashCard creates a new flash card
@@ -49,3 +87,33 @@ func NewFlashCard(cardBankID int, word, definition string, examples []string, im
 		UpdatedAt:  now,
 	}
 }
+
+// IsValidTemplate checks if a template is one of the Template constants
+func IsValidTemplate(template string) bool {
+	switch template {
+	case TemplateForward, TemplateReverse, TemplateBoth:
+		return true
+	}
+	return false
+}
+
+// TemplateDirections returns the review directions a template generates
+func TemplateDirections(template string) []string {
+	switch template {
+	case TemplateForward:
+		return []string{DirectionForward}
+	case TemplateBoth:
+		return []string{DirectionForward, DirectionReverse}
+	default:
+		return []string{DirectionReverse}
+	}
+}
+
+// IsValidCardFilter checks if a filter is one of the CardFilter constants or empty
+func IsValidCardFilter(filter string) bool {
+	switch filter {
+	case "", CardFilterNew, CardFilterLearning, CardFilterDue, CardFilterMature:
+		return true
+	}
+	return false
+}
@@ -4,30 +4,66 @@ import (
 	"time"
 )
 
-// Review represents a user's review of a flash card
+// Review states
+const (
+	ReviewStateNew        = "new"        // never answered
+	ReviewStateLearning   = "learning"   // going through the initial learning steps
+	ReviewStateReview     = "review"     // graduated, scheduled in days
+	ReviewStateRelearning = "relearning" // lapsed, going through the relearning steps
+)
+
+// Review directions, the side of a flash card that is shown first
+const (
+	DirectionForward = "forward" // word → definition
+	DirectionReverse = "reverse" // definition → word
+	DirectionCloze   = "cloze"   // an example with the word blanked out → word
+)
+
+// Review represents a user's review of a flash card in one direction
 type Review struct {
 	ID           int       `db:"id"`
 	UserID       int       `db:"user_id"`
 	FlashCardID  int       `db:"flash_card_id"`
+	Direction    string    `db:"direction"` // one of the Direction constants
 	EaseFactor   float64   `db:"ease_factor"`
 	DueDate      time.Time `db:"due_date"`
 	Interval     int       `db:"interval"`    // in days
 	Repetitions  int       `db:"repetitions"` // number of times reviewed
+	Stability    float64   `db:"stability"`   // in days, used by FSRS
+	Difficulty   float64   `db:"difficulty"`  // 1-10, used by FSRS
+	State        string    `db:"state"`       // one of the ReviewState constants
+	Step         int       `db:"step"`        // current learning or relearning step
 	LastReviewed time.Time `db:"last_reviewed"`
 	CreatedAt    time.Time `db:"created_at"`
 	UpdatedAt    time.Time `db:"updated_at"`
 }
 
+// ReviewCard is a flash card to review in one direction
+type ReviewCard struct {
+	FlashCard
+	Direction string `db:"direction"` // empty for cards queued before directions existed, which are reverse
+}
+
+// ReviewAnswer is the outcome of an answer to a review, with what is needed to take it back
+type ReviewAnswer struct {
+	Previous Review // before the answer
+	Review   Review // after the answer
+	LogID    int    // the answer's review log entry
+}
+
 // NewReview creates a new review
-func NewReview(userID, flashCardID int) *Review {
+func NewReview(userID, flashCardID int, direction string) *Review {
 	now := time.Now()
 	return &Review{
 		UserID:       userID,
 		FlashCardID:  flashCardID,
+		Direction:    direction,
 		EaseFactor:   2.5, // Default ease factor
 		DueDate:      now, // Due immediately
 		Interval:     0,
 		Repetitions:  0,
+		State:        ReviewStateNew,
+		Step:         0,
 		LastReviewed: time.Time{}, // Zero time
 		CreatedAt:    now,
 		UpdatedAt:    now,
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"time"
+)
+
+// ReviewLog represents a single answer given by a user during review
+type ReviewLog struct {
+	ID                 int       `db:"id"`
+	UserID             int       `db:"user_id"`
+	FlashCardID        int       `db:"flash_card_id"`
+	Direction          string    `db:"direction"`
+	Rating             int       `db:"rating"`
+	ElapsedDays        int       `db:"elapsed_days"` // days since the previous review
+	PreviousInterval   int       `db:"previous_interval"`
+	NewInterval        int       `db:"new_interval"`
+	PreviousEaseFactor float64   `db:"previous_ease_factor"`
+	NewEaseFactor      float64   `db:"new_ease_factor"`
+	PreviousState      string    `db:"previous_state"`
+	NewState           string    `db:"new_state"`
+	LatencyMs          int       `db:"latency_ms"` // from showing the card to flipping or answering it, 0 if unknown
+	AnsweredAt         time.Time `db:"answered_at"`
+}
+
+// LatencyCapMs limits the answer time counted in latency averages, in milliseconds.
+// Slower answers are usually a user who stepped away, not a hard card.
+const LatencyCapMs = 60000
+
+// CardLatency is how long a user takes on average to answer a card
+type CardLatency struct {
+	FlashCardID int    `db:"flash_card_id"`
+	Word        string `db:"word"`
+	Answers     int    `db:"answers"`
+	AverageMs   int    `db:"average_ms"`
+}
+
+// LatencyStats summarizes how long a user takes to answer the cards of a bank
+type LatencyStats struct {
+	Answers   int
+	AverageMs int
+	CardCount int           // cards with a known answer time
+	Cards     []CardLatency // a page of the cards, slowest first
+}
+
+// AverageLatencyMs averages the known answer times of review log entries, counting at most
+// LatencyCapMs per answer. It returns 0 if no answer time is known.
+func AverageLatencyMs(logs []ReviewLog) int {
+	var total, answers int
+	for _, log := range logs {
+		if log.LatencyMs > 0 {
+			total += min(log.LatencyMs, LatencyCapMs)
+			answers++
+		}
+	}
+	if answers == 0 {
+		return 0
+	}
+	return total / answers
+}
+
+// NewReviewLog creates a new review log entry from the review state before and after an answer
+// and how long the answer took
+func NewReviewLog(previous, current *Review, rating int, latency time.Duration) *ReviewLog {
+	elapsedDays := 0
+	if !previous.LastReviewed.IsZero() {
+		elapsedDays = int(current.LastReviewed.Sub(previous.LastReviewed).Hours() / 24)
+	}
+
+	return &ReviewLog{
+		UserID:             current.UserID,
+		FlashCardID:        current.FlashCardID,
+		Direction:          current.Direction,
+		Rating:             rating,
+		ElapsedDays:        elapsedDays,
+		PreviousInterval:   previous.Interval,
+		NewInterval:        current.Interval,
+		PreviousEaseFactor: previous.EaseFactor,
+		NewEaseFactor:      current.EaseFactor,
+		PreviousState:      previous.State,
+		NewState:           current.State,
+		LatencyMs:          int(latency.Milliseconds()),
+		AnsweredAt:         current.LastReviewed,
+	}
+}
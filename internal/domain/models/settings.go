@@ -9,14 +9,78 @@ import (
 
 // SettingsData represents user settings data stored as JSON
 type SettingsData struct {
-	ActiveCardBankID int    `json:"active_card_bank_id"`
-	ReviewLimit      int    `json:"review_limit"`
-	Language         string `json:"language"`
-	NotificationsOn  bool   `json:"notifications_on"`
-	DarkMode         bool   `json:"dark_mode"`
+	ActiveCardBankID int                 `json:"active_card_bank_id"`
+	ReviewLimit      int                 `json:"review_limit"`
+	Language         string              `json:"language"`
+	NotificationsOn  bool                `json:"notifications_on"`
+	DarkMode         bool                `json:"dark_mode"`
+	Algorithm        AlgorithmConfig     `json:"algorithm"`        // empty type means the default algorithm
+	LearningSteps    []int               `json:"learning_steps"`   // in minutes, nil means the default steps
+	RelearningSteps  []int               `json:"relearning_steps"` // in minutes, nil means the default steps
+	DailyLimits      DailyLimits         `json:"daily_limits"`
+	BankDailyLimits  map[int]DailyLimits `json:"bank_daily_limits"` // per-bank overrides
+	Timezone         string              `json:"timezone"`          // IANA name, empty means UTC
+	TimedGrading     bool                `json:"timed_grading"`     // rate fast good answers easy and slow good or easy ones hard
 	// Add more settings as needed
 }
 
+// Default daily limits
+const (
+	DefaultNewCardsPerDay = 20
+	DefaultReviewsPerDay  = 200
+)
+
+// DailyLimits represents how many cards a user studies per calendar day. A nil limit inherits
+// the default, 0 is a real limit, e.g. no new cards for days of reviews only.
+type DailyLimits struct {
+	NewCards *int `json:"new_cards,omitempty"`
+	Reviews  *int `json:"reviews,omitempty"`
+}
+
+// DailyLimitsForBank returns the effective new card and review limits for a bank, applying the
+// bank override and defaults
+func (s SettingsData) DailyLimitsForBank(bankID int) (int, int) {
+	newCards, reviews := DefaultNewCardsPerDay, DefaultReviewsPerDay
+
+	if s.DailyLimits.NewCards != nil {
+		newCards = *s.DailyLimits.NewCards
+	}
+	if s.DailyLimits.Reviews != nil {
+		reviews = *s.DailyLimits.Reviews
+	}
+
+	if override, ok := s.BankDailyLimits[bankID]; ok {
+		if override.NewCards != nil {
+			newCards = *override.NewCards
+		}
+		if override.Reviews != nil {
+			reviews = *override.Reviews
+		}
+	}
+
+	return newCards, reviews
+}
+
+// Location returns the user's time zone, falling back to UTC
+func (s SettingsData) Location() *time.Location {
+	if s.Timezone == "" {
+		return time.UTC
+	}
+
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+
+	return loc
+}
+
+// AlgorithmConfig represents configuration for a spaced repetition algorithm
+type AlgorithmConfig struct {
+	Type       string                 `json:"type"`
+	Parameters map[string]interface{} `json:"parameters"`
+}
+
 // Value implements the driver.Valuer interface for SettingsData
 func (s SettingsData) Value() (driver.Value, error) {
 	return json.Marshal(s)
@@ -13,12 +13,16 @@ type CardBankService interface {
 	GetCardBank(bankID int) (*models.CardBank, error)
 	GetUserCardBanks(userID int) ([]models.CardBank, error)
 	UpdateCardBank(bank *models.CardBank) error
+	SetLanguages(bankID int, sourceLanguage, targetLanguage string) (*models.CardBank, error)
+	SetCardTemplate(bankID int, template string) (*models.CardBank, error)
+	SetClozeCards(bankID int, enabled bool) (*models.CardBank, error)
 	DeleteCardBank(bankID int) error
 
 	// Membership operations
 	AddUserToBank(userID, bankID int, role string) error
 	RemoveUserFromBank(userID, bankID int) error
 	UserHasAccess(userID, bankID int) (bool, error)
+	UserCanEdit(userID, bankID int) (bool, error)
 
 	// Group chat operations
 	LinkGroupChat(telegramChatID int64, title string, bankID int) error
@@ -51,7 +55,7 @@ func (s *cardBankService) CreateCardBank(name, description string, ownerID int,
 	}
 
 	// Add owner as a member with "owner" role
-	membership := models.NewBankMembership(ownerID, bank.ID, "owner")
+	membership := models.NewBankMembership(ownerID, bank.ID, models.RoleOwner)
 	err = s.repo.CreateMembership(membership)
 	if err != nil {
 		s.logger.Error("Failed to create bank membership for owner", "error", err)
@@ -79,6 +83,73 @@ func (s *cardBankService) UpdateCardBank(bank *models.CardBank) error {
 	return s.repo.Update(bank)
 }
 
+// SetLanguages sets the language of a bank's words and the language of their definitions
+func (s *cardBankService) SetLanguages(bankID int, sourceLanguage, targetLanguage string) (*models.CardBank, error) {
+	s.logger.Info("Setting card bank languages",
+		"bank_id", bankID,
+		"source_language", sourceLanguage,
+		"target_language", targetLanguage,
+	)
+
+	if !models.IsValidLanguage(sourceLanguage) || !models.IsValidLanguage(targetLanguage) {
+		return nil, ErrInvalidInput
+	}
+
+	bank, err := s.repo.GetByID(bankID)
+	if err != nil {
+		return nil, err
+	}
+
+	bank.SourceLanguage = sourceLanguage
+	bank.TargetLanguage = targetLanguage
+	if err := s.repo.Update(bank); err != nil {
+		s.logger.Error("Failed to update card bank languages", "error", err, "bank_id", bankID)
+		return nil, err
+	}
+
+	return bank, nil
+}
+
+// SetCardTemplate sets the template of the bank's cards that don't choose their own
+func (s *cardBankService) SetCardTemplate(bankID int, template string) (*models.CardBank, error) {
+	s.logger.Info("Setting card bank template", "bank_id", bankID, "template", template)
+
+	if !models.IsValidTemplate(template) {
+		return nil, ErrInvalidInput
+	}
+
+	bank, err := s.repo.GetByID(bankID)
+	if err != nil {
+		return nil, err
+	}
+
+	bank.CardTemplate = template
+	if err := s.repo.Update(bank); err != nil {
+		s.logger.Error("Failed to update card bank template", "error", err, "bank_id", bankID)
+		return nil, err
+	}
+
+	return bank, nil
+}
+
+// SetClozeCards turns the cloze cards of the bank's cards with examples on or off
+func (s *cardBankService) SetClozeCards(bankID int, enabled bool) (*models.CardBank, error) {
+	s.logger.Info("Setting card bank cloze cards", "bank_id", bankID, "enabled", enabled)
+
+	bank, err := s.repo.GetByID(bankID)
+	if err != nil {
+		return nil, err
+	}
+
+	bank.ClozeCards = enabled
+	if err := s.repo.Update(bank); err != nil {
+		s.logger.Error("Failed to update card bank cloze cards", "error", err, "bank_id", bankID)
+		return nil, err
+	}
+
+	return bank, nil
+}
+
 // DeleteCardBank deletes a card bank
 func (s *cardBankService) DeleteCardBank(bankID int) error {
 	s.logger.Info("Deleting card bank", "bank_id", bankID)
@@ -105,6 +176,21 @@ func (s *cardBankService) UserHasAccess(userID, bankID int) (bool, error) {
 	return s.repo.UserHasAccess(userID, bankID)
 }
 
+// UserCanEdit checks if a user may change the cards of a card bank, which viewers may not
+func (s *cardBankService) UserCanEdit(userID, bankID int) (bool, error) {
+	s.logger.Debug("Checking if user can edit bank", "user_id", userID, "bank_id", bankID)
+
+	membership, err := s.repo.GetMembership(userID, bankID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return membership.CanEdit(), nil
+}
+
 // LinkGroupChat links a Telegram group chat to a card bank
 func (s *cardBankService) LinkGroupChat(telegramChatID int64, title string, bankID int) error {
 	s.logger.Info("Linking group chat to bank", "chat_id", telegramChatID, "bank_id", bankID)
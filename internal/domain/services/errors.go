@@ -6,12 +6,13 @@ import (
 
 // Common errors
 var (
-	ErrNotFound         = models.ErrNotFound
-	ErrAlreadyExists    = models.ErrAlreadyExists
-	ErrInvalidInput     = models.ErrInvalidInput
-	ErrUnauthorized     = models.ErrUnauthorized
-	ErrInternalError    = models.ErrInternalError
-	ErrDatabaseError    = models.ErrDatabaseError
-	ErrExternalAPIError = models.ErrExternalAPIError
-	ErrInvalidParameter = models.ErrInvalidParameter
+	ErrNotFound            = models.ErrNotFound
+	ErrAlreadyExists       = models.ErrAlreadyExists
+	ErrInvalidInput        = models.ErrInvalidInput
+	ErrUnauthorized        = models.ErrUnauthorized
+	ErrInternalError       = models.ErrInternalError
+	ErrDatabaseError       = models.ErrDatabaseError
+	ErrExternalAPIError    = models.ErrExternalAPIError
+	ErrInvalidParameter    = models.ErrInvalidParameter
+	ErrUnsupportedLanguage = models.ErrUnsupportedLanguage
 )
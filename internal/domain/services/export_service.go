@@ -0,0 +1,148 @@
+package services
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/supercakecrumb/flash-cards-language-tg-bot/internal/domain/models"
+	"github.com/supercakecrumb/flash-cards-language-tg-bot/internal/infrastructure/exporter"
+	"github.com/supercakecrumb/flash-cards-language-tg-bot/internal/repository"
+)
+
+// ExportFile represents an exported card bank ready to be sent to the user
+type ExportFile struct {
+	Name string
+	Data []byte
+}
+
+// ExportService handles exporting card banks to files
+type ExportService interface {
+	ExportBank(userID, bankID int, format string, includeReviews bool) (*ExportFile, error)
+}
+
+type exportService struct {
+	cardbankRepo  repository.CardBankRepository
+	flashcardRepo repository.FlashCardRepository
+	reviewRepo    repository.ReviewRepository
+	logger        *slog.Logger
+}
+
+// NewExportService creates a new export service
+func NewExportService(
+	cardbankRepo repository.CardBankRepository,
+	flashcardRepo repository.FlashCardRepository,
+	reviewRepo repository.ReviewRepository,
+	logger *slog.Logger,
+) ExportService {
+	return &exportService{
+		cardbankRepo:  cardbankRepo,
+		flashcardRepo: flashcardRepo,
+		reviewRepo:    reviewRepo,
+		logger:        logger,
+	}
+}
+
+// ExportBank exports the cards of a bank in the given format.
+// The user's review state is only included in JSON exports when requested.
+func (s *exportService) ExportBank(userID, bankID int, format string, includeReviews bool) (*ExportFile, error) {
+	s.logger.Info("Exporting card bank",
+		"user_id", userID,
+		"bank_id", bankID,
+		"format", format,
+		"include_reviews", includeReviews,
+	)
+
+	bank, err := s.cardbankRepo.GetByID(bankID)
+	if err != nil {
+		s.logger.Error("Failed to get card bank for export", "error", err, "bank_id", bankID)
+		return nil, err
+	}
+
+	cards, err := s.flashcardRepo.GetCardsForBank(bankID)
+	if err != nil {
+		s.logger.Error("Failed to get cards for export", "error", err, "bank_id", bankID)
+		return nil, err
+	}
+
+	reviews := make(map[int]map[string]models.Review)
+	if includeReviews && format == exporter.FormatJSON {
+		bankReviews, err := s.reviewRepo.GetByUserAndBank(userID, bankID)
+		if err != nil {
+			s.logger.Error("Failed to get reviews for export",
+				"error", err,
+				"user_id", userID,
+				"bank_id", bankID,
+			)
+			return nil, err
+		}
+		for _, review := range bankReviews {
+			if reviews[review.FlashCardID] == nil {
+				reviews[review.FlashCardID] = make(map[string]models.Review)
+			}
+			reviews[review.FlashCardID][review.Direction] = review
+		}
+	}
+
+	deck := &exporter.Deck{
+		Name:        bank.Name,
+		Description: bank.Description,
+		ExportedAt:  time.Now(),
+	}
+
+	// Cards come newest first, export them in the order they were added
+	for i := len(cards) - 1; i >= 0; i-- {
+		card := cards[i]
+		exported := exporter.Card{
+			Word:       card.Word,
+			Definition: card.Definition,
+			Examples:   card.Examples,
+			Image:      exporter.ImageReference(card.ImageURL),
+			Template:   card.Template,
+			CreatedAt:  card.CreatedAt,
+		}
+
+		if review, ok := reviews[card.ID][models.DirectionReverse]; ok {
+			exported.Review = reviewToExport(review)
+		}
+		if review, ok := reviews[card.ID][models.DirectionForward]; ok {
+			exported.ForwardReview = reviewToExport(review)
+		}
+		if review, ok := reviews[card.ID][models.DirectionCloze]; ok {
+			exported.ClozeReview = reviewToExport(review)
+		}
+
+		deck.Cards = append(deck.Cards, exported)
+	}
+
+	data, err := exporter.Write(deck, format)
+	if err != nil {
+		s.logger.Error("Failed to write export", "error", err, "bank_id", bankID, "format", format)
+		return nil, err
+	}
+
+	return &ExportFile{
+		Name: exporter.FileName(bank.Name, format),
+		Data: data,
+	}, nil
+}
+
+// reviewToExport converts a review to the exported review state
+func reviewToExport(review models.Review) *exporter.Review {
+	exported := &exporter.Review{
+		State:       review.State,
+		Step:        review.Step,
+		EaseFactor:  review.EaseFactor,
+		Interval:    review.Interval,
+		Repetitions: review.Repetitions,
+		Stability:   review.Stability,
+		Difficulty:  review.Difficulty,
+		DueDate:     review.DueDate,
+	}
+
+	if !review.LastReviewed.IsZero() {
+		lastReviewed := review.LastReviewed
+		exported.LastReviewed = &lastReviewed
+	}
+
+	return exported
+}
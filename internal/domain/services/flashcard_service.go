@@ -1,11 +1,16 @@
 package services
 
 import (
+	"context"
 	"log/slog"
+	"sort"
+	"strings"
 
 	"github.com/supercakecrumb/flash-cards-language-tg-bot/internal/domain/models"
 	"github.com/supercakecrumb/flash-cards-language-tg-bot/internal/infrastructure/dictionary"
 	"github.com/supercakecrumb/flash-cards-language-tg-bot/internal/repository"
+	"github.com/supercakecrumb/flash-cards-language-tg-bot/pkg/answer"
+	"github.com/supercakecrumb/flash-cards-language-tg-bot/pkg/lru"
 )
 
 // Definition represents a word definition from the dictionary
@@ -13,6 +18,10 @@ type Definition struct {
 	ID           string
 	Text         string
 	PartOfSpeech string
+	Phonetic     string
+	AudioURL     string
+	Synonyms     []string
+	Antonyms     []string
 	Examples     []Example
 }
 
@@ -24,23 +33,33 @@ type Example struct {
 
 // FlashCardService handles flash card operations
 type FlashCardService interface {
-	GetDefinitions(word string) ([]Definition, error)
+	GetDefinitions(word, sourceLanguage, targetLanguage string) ([]Definition, error)
+	SupportsLanguages(sourceLanguage, targetLanguage string) bool
 	GetDefinition(definitionID string) (*Definition, error)
 	GetExamples(word, definitionID string) ([]Example, error)
 	GetExample(exampleID string) (*Example, error)
 	CreateFlashCard(card *models.FlashCard) error
 	GetFlashCard(cardID int) (*models.FlashCard, error)
+	GetFlashCardByWord(word string, bankID int) (*models.FlashCard, error)
 	GetFlashCardsByBank(bankID int) ([]models.FlashCard, error)
+	SearchFlashCards(search models.CardSearch) ([]models.FlashCard, int, error)
+	GetDistractors(card *models.FlashCard, count int) ([]models.FlashCard, error)
 	UpdateFlashCard(card *models.FlashCard) error
 	DeleteFlashCard(cardID int) error
 }
 
+// distractorCandidates is the number of random cards distractors are chosen from
+const distractorCandidates = 50
+
+// lookupCacheSize is the number of recently looked up definitions and examples kept for selection
+const lookupCacheSize = 10000
+
 type flashCardService struct {
 	repo            repository.FlashCardRepository
 	dictService     dictionary.DictionaryService
 	logger          *slog.Logger
-	definitionCache map[string]Definition
-	exampleCache    map[string]Example
+	definitionCache *lru.Cache[string, Definition]
+	exampleCache    *lru.Cache[string, Example]
 }
 
 // NewFlashCardService creates a new flash card service
@@ -49,16 +68,23 @@ func NewFlashCardService(repo repository.FlashCardRepository, dictService dictio
 		repo:            repo,
 		dictService:     dictService,
 		logger:          logger,
-		definitionCache: make(map[string]Definition),
-		exampleCache:    make(map[string]Example),
+		definitionCache: lru.New[string, Definition](lookupCacheSize),
+		exampleCache:    lru.New[string, Example](lookupCacheSize),
 	}
 }
 
-// GetDefinitions retrieves definitions for a word from the dictionary API
-func (s *flashCardService) GetDefinitions(word string) ([]Definition, error) {
-	s.logger.Debug("Getting definitions for word", "word", word)
-
-	dictDefinitions, err := s.dictService.GetDefinitions(word)
+// GetDefinitions retrieves definitions of a word in the source language, written in the target language
+func (s *flashCardService) GetDefinitions(word, sourceLanguage, targetLanguage string) ([]Definition, error) {
+	s.logger.Debug("Getting definitions for word",
+		"word", word,
+		"source_language", sourceLanguage,
+		"target_language", targetLanguage,
+	)
+
+	dictDefinitions, err := s.dictService.GetDefinitions(context.Background(), word, dictionary.Languages{
+		Source: sourceLanguage,
+		Target: targetLanguage,
+	})
 	if err != nil {
 		s.logger.Error("Failed to get definitions from dictionary API", "error", err)
 		return nil, err
@@ -70,6 +96,10 @@ func (s *flashCardService) GetDefinitions(word string) ([]Definition, error) {
 			ID:           d.ID,
 			Text:         d.Text,
 			PartOfSpeech: d.PartOfSpeech,
+			Phonetic:     d.Phonetic,
+			AudioURL:     d.AudioURL,
+			Synonyms:     d.Synonyms,
+			Antonyms:     d.Antonyms,
 		}
 
 		var examples []Example
@@ -79,20 +109,28 @@ func (s *flashCardService) GetDefinitions(word string) ([]Definition, error) {
 				Text: e.Text,
 			}
 			examples = append(examples, ex)
-			s.exampleCache[ex.ID] = ex
+			s.exampleCache.Add(ex.ID, ex)
 		}
 
 		def.Examples = examples
 		definitions = append(definitions, def)
-		s.definitionCache[def.ID] = def
+		s.definitionCache.Add(def.ID, def)
 	}
 
 	return definitions, nil
 }
 
+// SupportsLanguages checks if a dictionary can define words of the source language in the target language
+func (s *flashCardService) SupportsLanguages(sourceLanguage, targetLanguage string) bool {
+	return s.dictService.Supports(dictionary.Languages{
+		Source: sourceLanguage,
+		Target: targetLanguage,
+	})
+}
+
 // GetDefinition retrieves a cached definition by ID
 func (s *flashCardService) GetDefinition(definitionID string) (*Definition, error) {
-	def, ok := s.definitionCache[definitionID]
+	def, ok := s.definitionCache.Get(definitionID)
 	if !ok {
 		s.logger.Error("Definition not found in cache", "definition_id", definitionID)
 		return nil, ErrNotFound
@@ -111,7 +149,7 @@ func (s *flashCardService) GetExamples(word, definitionID string) ([]Example, er
 
 // GetExample retrieves a cached example by ID
 func (s *flashCardService) GetExample(exampleID string) (*Example, error) {
-	ex, ok := s.exampleCache[exampleID]
+	ex, ok := s.exampleCache.Get(exampleID)
 	if !ok {
 		s.logger.Error("Example not found in cache", "example_id", exampleID)
 		return nil, ErrNotFound
@@ -131,12 +169,75 @@ func (s *flashCardService) GetFlashCard(cardID int) (*models.FlashCard, error) {
 	return s.repo.GetByID(cardID)
 }
 
+// GetFlashCardByWord retrieves a flash card by its word in a bank
+func (s *flashCardService) GetFlashCardByWord(word string, bankID int) (*models.FlashCard, error) {
+	s.logger.Debug("Getting flash card by word", "word", word, "bank_id", bankID)
+	return s.repo.GetByWord(word, bankID)
+}
+
 // GetFlashCardsByBank retrieves all flash cards in a bank
 func (s *flashCardService) GetFlashCardsByBank(bankID int) ([]models.FlashCard, error) {
 	s.logger.Debug("Getting flash cards for bank", "bank_id", bankID)
 	return s.repo.GetCardsForBank(bankID)
 }
 
+// SearchFlashCards retrieves a page of the cards in a bank that match a search, with the number of matching cards
+func (s *flashCardService) SearchFlashCards(search models.CardSearch) ([]models.FlashCard, int, error) {
+	s.logger.Debug("Searching flash cards",
+		"bank_id", search.BankID,
+		"text", search.Text,
+		"filter", search.Filter,
+		"offset", search.Offset,
+	)
+
+	if !models.IsValidCardFilter(search.Filter) || search.Limit <= 0 || search.Offset < 0 {
+		return nil, 0, ErrInvalidInput
+	}
+
+	return s.repo.Search(search)
+}
+
+// GetDistractors picks cards of the same bank whose words can be confused with a card's word:
+// ideally the same part of speech, then the most similar spelling. There may be fewer than count.
+func (s *flashCardService) GetDistractors(card *models.FlashCard, count int) ([]models.FlashCard, error) {
+	s.logger.Debug("Getting distractors", "card_id", card.ID, "count", count)
+
+	candidates, err := s.repo.GetDistractorCandidates(card, distractorCandidates)
+	if err != nil {
+		s.logger.Error("Failed to get distractor candidates", "error", err, "card_id", card.ID)
+		return nil, err
+	}
+
+	// Candidates come in random order, which the stable sort keeps among equally good ones
+	score := func(candidate models.FlashCard) float64 {
+		word, other := strings.ToLower(card.Word), strings.ToLower(candidate.Word)
+		similarity := 1 - float64(answer.Distance(word, other))/float64(max(len([]rune(word)), len([]rune(other))))
+		if card.PartOfSpeech != "" && candidate.PartOfSpeech == card.PartOfSpeech {
+			return similarity + 1
+		}
+		return similarity
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return score(candidates[i]) > score(candidates[j])
+	})
+
+	// Every option must be a different word
+	seen := map[string]bool{strings.ToLower(card.Word): true}
+	var distractors []models.FlashCard
+	for _, candidate := range candidates {
+		if len(distractors) == count {
+			break
+		}
+		if seen[strings.ToLower(candidate.Word)] {
+			continue
+		}
+		seen[strings.ToLower(candidate.Word)] = true
+		distractors = append(distractors, candidate)
+	}
+
+	return distractors, nil
+}
+
 // UpdateFlashCard updates an existing flash card
 func (s *flashCardService) UpdateFlashCard(card *models.FlashCard) error {
 	s.logger.Debug("Updating flash card", "card_id", card.ID)
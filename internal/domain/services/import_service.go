@@ -0,0 +1,187 @@
+package services
+
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/supercakecrumb/flash-cards-language-tg-bot/internal/domain/models"
+	"github.com/supercakecrumb/flash-cards-language-tg-bot/internal/infrastructure/exporter"
+	"github.com/supercakecrumb/flash-cards-language-tg-bot/internal/infrastructure/importer"
+	"github.com/supercakecrumb/flash-cards-language-tg-bot/internal/repository"
+)
+
+// ImportRowError describes a row that could not be imported
+type ImportRowError struct {
+	Row    int
+	Reason string
+}
+
+// ImportResult summarizes an import or a dry run of one
+type ImportResult struct {
+	Cards    []*models.FlashCard // cards that are (or would be) created
+	Skipped  []string            // words that already exist in the bank
+	Failed   []ImportRowError
+	Imported int
+}
+
+// ImportService handles importing flash cards from files
+type ImportService interface {
+	PreviewImport(bankID int, records []importer.Record) (*ImportResult, error)
+	ImportCards(userID, bankID int, records []importer.Record) (*ImportResult, error)
+}
+
+type importService struct {
+	flashcardRepo repository.FlashCardRepository
+	reviewRepo    repository.ReviewRepository
+	logger        *slog.Logger
+}
+
+// NewImportService creates a new import service
+func NewImportService(flashcardRepo repository.FlashCardRepository, reviewRepo repository.ReviewRepository, logger *slog.Logger) ImportService {
+	return &importService{
+		flashcardRepo: flashcardRepo,
+		reviewRepo:    reviewRepo,
+		logger:        logger,
+	}
+}
+
+// PreviewImport checks the records against the bank without creating any cards
+func (s *importService) PreviewImport(bankID int, records []importer.Record) (*ImportResult, error) {
+	s.logger.Debug("Previewing import", "bank_id", bankID, "records", len(records))
+	result, _, err := s.prepare(bankID, records)
+	return result, err
+}
+
+// ImportCards creates flash cards for the records that are not duplicates.
+// Review state included in the records is restored for the importing user.
+func (s *importService) ImportCards(userID, bankID int, records []importer.Record) (*ImportResult, error) {
+	s.logger.Info("Importing cards", "user_id", userID, "bank_id", bankID, "records", len(records))
+
+	result, imported, err := s.prepare(bankID, records)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, card := range result.Cards {
+		if err := s.flashcardRepo.Create(card); err != nil {
+			s.logger.Error("Failed to create imported card",
+				"error", err,
+				"word", card.Word,
+				"bank_id", bankID,
+			)
+			return result, err
+		}
+		result.Imported++
+
+		exported := map[string]*exporter.Review{
+			models.DirectionReverse: imported[i].Review,
+			models.DirectionForward: imported[i].ForwardReview,
+			models.DirectionCloze:   imported[i].ClozeReview,
+		}
+		for direction, state := range exported {
+			if state == nil {
+				continue
+			}
+
+			review := reviewFromExport(userID, card.ID, direction, state)
+			if err := s.reviewRepo.Create(review); err != nil {
+				s.logger.Warn("Failed to restore review state of imported card",
+					"error", err,
+					"user_id", userID,
+					"card_id", card.ID,
+					"direction", direction,
+				)
+			}
+		}
+	}
+
+	s.logger.Info("Import finished",
+		"bank_id", bankID,
+		"imported", result.Imported,
+		"skipped", len(result.Skipped),
+		"failed", len(result.Failed),
+	)
+
+	return result, nil
+}
+
+// prepare validates the records and sorts them into new cards, duplicates and failures.
+// The returned records line up with the new cards.
+func (s *importService) prepare(bankID int, records []importer.Record) (*ImportResult, []importer.Record, error) {
+	result := &ImportResult{}
+	var imported []importer.Record
+	seen := make(map[string]bool)
+
+	for _, record := range records {
+		word := strings.TrimSpace(strings.ToLower(record.Word))
+		definition := strings.TrimSpace(record.Definition)
+
+		switch {
+		case word == "":
+			result.Failed = append(result.Failed, ImportRowError{Row: record.Row, Reason: "missing word"})
+			continue
+		case definition == "":
+			result.Failed = append(result.Failed, ImportRowError{Row: record.Row, Reason: "missing definition"})
+			continue
+		}
+
+		// Duplicates within the file itself
+		if seen[word] {
+			result.Skipped = append(result.Skipped, word)
+			continue
+		}
+		seen[word] = true
+
+		// Duplicates already in the bank
+		_, err := s.flashcardRepo.GetByWord(word, bankID)
+		if err == nil {
+			result.Skipped = append(result.Skipped, word)
+			continue
+		}
+		if err != repository.ErrNotFound {
+			s.logger.Error("Failed to check for duplicate card",
+				"error", err,
+				"word", word,
+				"bank_id", bankID,
+			)
+			return nil, nil, err
+		}
+
+		card := models.NewFlashCard(bankID, word, definition, record.Examples, record.ImageURL)
+		if models.IsValidTemplate(record.Template) {
+			card.Template = record.Template
+		}
+
+		result.Cards = append(result.Cards, card)
+		imported = append(imported, record)
+	}
+
+	return result, imported, nil
+}
+
+// reviewFromExport converts an exported review state to a review of the card in a direction
+func reviewFromExport(userID, cardID int, direction string, exported *exporter.Review) *models.Review {
+	review := models.NewReview(userID, cardID, direction)
+
+	switch exported.State {
+	case models.ReviewStateNew, models.ReviewStateLearning, models.ReviewStateReview, models.ReviewStateRelearning:
+		review.State = exported.State
+	default:
+		return review
+	}
+
+	review.Step = exported.Step
+	if exported.EaseFactor > 0 {
+		review.EaseFactor = exported.EaseFactor
+	}
+	review.Interval = exported.Interval
+	review.Repetitions = exported.Repetitions
+	review.Stability = exported.Stability
+	review.Difficulty = exported.Difficulty
+	review.DueDate = exported.DueDate
+	if exported.LastReviewed != nil {
+		review.LastReviewed = *exported.LastReviewed
+	}
+
+	return review
+}
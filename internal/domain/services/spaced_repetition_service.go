@@ -1,57 +1,106 @@
 package services
 
 import (
+	"errors"
 	"log/slog"
 	"time"
 
 	"github.com/supercakecrumb/flash-cards-language-tg-bot/internal/domain/models"
 	"github.com/supercakecrumb/flash-cards-language-tg-bot/internal/repository"
+	"github.com/supercakecrumb/flash-cards-language-tg-bot/pkg/cloze"
 	"github.com/supercakecrumb/flash-cards-language-tg-bot/pkg/spaced_repetition"
 )
 
 // SpacedRepetitionService handles spaced repetition operations
 type SpacedRepetitionService interface {
-	GetDueCards(userID, bankID int, limit int) ([]models.FlashCard, error)
-	ProcessReview(userID, cardID int, quality int) error
+	GetDueCards(userID, bankID int, limit int) ([]models.ReviewCard, error)
+	GetDueLearningCards(userID, bankID int, until time.Time) ([]models.ReviewCard, error)
+	GetDailyRemaining(userID, bankID int) (int, int, error) // new cards, reviews
+	HasLimitedCards(userID, bankID int) (bool, error)
+	ProcessReview(userID, cardID int, direction string, quality int, latency time.Duration) (*models.ReviewAnswer, error)
+	UndoReview(userID int, answer *models.ReviewAnswer) error
 	GetReviewStats(userID int) (int, int, error) // total cards, due cards
+	GetReviewHistory(userID, cardID int) ([]models.ReviewLog, error)
+	GetLatencyStats(userID, bankID, limit, offset int) (*models.LatencyStats, error)
+	GetReviews(userID, cardID int) ([]models.Review, error)
 }
 
 type spacedRepetitionService struct {
 	reviewRepo    repository.ReviewRepository
+	reviewLogRepo repository.ReviewLogRepository
 	flashcardRepo repository.FlashCardRepository
-	algorithm     spaced_repetition.Algorithm
+	settingsRepo  repository.SettingsRepository
+	algorithm     spaced_repetition.Algorithm // default for users without an algorithm setting
 	logger        *slog.Logger
 }
 
 // NewSpacedRepetitionService creates a new spaced repetition service
 func NewSpacedRepetitionService(
 	reviewRepo repository.ReviewRepository,
+	reviewLogRepo repository.ReviewLogRepository,
 	flashcardRepo repository.FlashCardRepository,
+	settingsRepo repository.SettingsRepository,
 	algorithm spaced_repetition.Algorithm,
 	logger *slog.Logger,
 ) SpacedRepetitionService {
 	return &spacedRepetitionService{
 		reviewRepo:    reviewRepo,
+		reviewLogRepo: reviewLogRepo,
 		flashcardRepo: flashcardRepo,
+		settingsRepo:  settingsRepo,
 		algorithm:     algorithm,
 		logger:        logger,
 	}
 }
 
-// GetDueCards retrieves cards that are due for review
-func (s *spacedRepetitionService) GetDueCards(userID, bankID int, limit int) ([]models.FlashCard, error) {
+// GetDueCards retrieves cards that are due for review, within the user's daily limits.
+// Every direction of a card is due on its own schedule.
+func (s *spacedRepetitionService) GetDueCards(userID, bankID int, limit int) ([]models.ReviewCard, error) {
 	s.logger.Debug("Getting due cards", "user_id", userID, "bank_id", bankID, "limit", limit)
 
-	// Get due reviews
-	reviews, err := s.reviewRepo.GetDueReviews(userID, bankID, time.Now(), limit)
+	now := time.Now()
+
+	remainingNew, remainingReviews, err := s.GetDailyRemaining(userID, bankID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Learning cards are already in progress and are not limited
+	reviews, err := s.reviewRepo.GetDueLearningReviews(userID, bankID, now)
 	if err != nil {
-		s.logger.Error("Failed to get due reviews", "error", err)
+		s.logger.Error("Failed to get due learning reviews", "error", err)
 		return nil, err
 	}
+	if len(reviews) > limit {
+		reviews = reviews[:limit]
+	}
 
-	// If there are not enough due reviews, get new cards
-	if len(reviews) < limit {
-		newCardsLimit := limit - len(reviews)
+	// Due review cards, up to the daily review limit
+	if reviewsLimit := min(limit-len(reviews), remainingReviews); reviewsLimit > 0 {
+		due, err := s.reviewRepo.GetDueReviewsInState(userID, bankID, models.ReviewStateReview, now, reviewsLimit)
+		if err != nil {
+			s.logger.Error("Failed to get due reviews", "error", err)
+			return nil, err
+		}
+		reviews = append(reviews, due...)
+	}
+
+	// New cards shown before but not answered yet, up to the daily new card limit
+	if newLimit := min(limit-len(reviews), remainingNew); newLimit > 0 {
+		pending, err := s.reviewRepo.GetDueReviewsInState(userID, bankID, models.ReviewStateNew, now, newLimit)
+		if err != nil {
+			s.logger.Error("Failed to get pending new reviews", "error", err)
+			return nil, err
+		}
+		reviews = append(reviews, pending...)
+		remainingNew -= len(pending)
+	}
+
+	// Get cards for due reviews
+	dueCards := s.reviewCards(reviews)
+
+	// If there are not enough due reviews, get new cards within the daily limit
+	if newCardsLimit := min(limit-len(reviews), remainingNew); newCardsLimit > 0 {
 		newCards, err := s.getNewCards(userID, bankID, newCardsLimit)
 		if err != nil {
 			s.logger.Error("Failed to get new cards", "error", err)
@@ -60,7 +109,7 @@ func (s *spacedRepetitionService) GetDueCards(userID, bankID int, limit int) ([]
 
 		// Create initial reviews for new cards
 		for _, card := range newCards {
-			review := models.NewReview(userID, card.ID)
+			review := models.NewReview(userID, card.ID, card.Direction)
 			err := s.reviewRepo.Create(review)
 			if err != nil {
 				s.logger.Error("Failed to create review for new card", "error", err)
@@ -68,76 +117,264 @@ func (s *spacedRepetitionService) GetDueCards(userID, bankID int, limit int) ([]
 			}
 		}
 
-		// Get cards for due reviews
-		var dueCards []models.FlashCard
-		for _, review := range reviews {
-			card, err := s.flashcardRepo.GetByID(review.FlashCardID)
-			if err != nil {
-				s.logger.Error("Failed to get card for review", "error", err)
-				continue
-			}
-			dueCards = append(dueCards, *card)
+		// Combine due cards and new cards
+		dueCards = append(dueCards, newCards...)
+	}
+
+	return dueCards, nil
+}
+
+// GetDailyRemaining retrieves how many new cards and reviews are left today in a bank
+func (s *spacedRepetitionService) GetDailyRemaining(userID, bankID int) (int, int, error) {
+	s.logger.Debug("Getting daily remaining cards", "user_id", userID, "bank_id", bankID)
+
+	settings, err := s.settingsRepo.GetByUserID(userID)
+	if err != nil {
+		settings = models.NewSettings(userID)
+	}
+
+	newLimit, reviewLimit := settings.Settings.DailyLimitsForBank(bankID)
+
+	// Count answers since midnight in the user's time zone
+	now := time.Now().In(settings.Settings.Location())
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	// Timestamps are stored in server local time
+	newCards, reviews, err := s.reviewLogRepo.CountDailyActivity(userID, bankID, dayStart.In(time.Local))
+	if err != nil {
+		s.logger.Error("Failed to count daily activity", "error", err)
+		return 0, 0, err
+	}
+
+	return max(newLimit-newCards, 0), max(reviewLimit-reviews, 0), nil
+}
+
+// HasLimitedCards checks if a bank has cards waiting that today's limits hold back
+func (s *spacedRepetitionService) HasLimitedCards(userID, bankID int) (bool, error) {
+	s.logger.Debug("Checking for limited cards", "user_id", userID, "bank_id", bankID)
+
+	now := time.Now()
+
+	remainingNew, remainingReviews, err := s.GetDailyRemaining(userID, bankID)
+	if err != nil {
+		return false, err
+	}
+
+	if remainingReviews == 0 {
+		due, err := s.reviewRepo.GetDueReviewsInState(userID, bankID, models.ReviewStateReview, now, 1)
+		if err != nil {
+			s.logger.Error("Failed to get due reviews", "error", err)
+			return false, err
 		}
+		if len(due) > 0 {
+			return true, nil
+		}
+	}
 
-		// Combine due cards and new cards
-		return append(dueCards, newCards...), nil
+	if remainingNew == 0 {
+		pending, err := s.reviewRepo.GetDueReviewsInState(userID, bankID, models.ReviewStateNew, now, 1)
+		if err != nil {
+			s.logger.Error("Failed to get pending new reviews", "error", err)
+			return false, err
+		}
+		if len(pending) > 0 {
+			return true, nil
+		}
+
+		newCards, err := s.flashcardRepo.GetNewCards(userID, bankID, 1)
+		if err != nil {
+			s.logger.Error("Failed to get new cards", "error", err)
+			return false, err
+		}
+		if len(newCards) > 0 {
+			return true, nil
+		}
 	}
 
-	// Get cards for due reviews
-	var dueCards []models.FlashCard
+	return false, nil
+}
+
+// getNewCards retrieves the directions of cards that the user hasn't reviewed yet
+func (s *spacedRepetitionService) getNewCards(userID, bankID, limit int) ([]models.ReviewCard, error) {
+	cards, err := s.flashcardRepo.GetNewCards(userID, bankID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var reviewable []models.ReviewCard
+	for _, card := range cards {
+		if isReviewable(card) {
+			reviewable = append(reviewable, card)
+		}
+	}
+	return reviewable, nil
+}
+
+// GetDueLearningCards retrieves learning and relearning cards whose step expires before the given time
+func (s *spacedRepetitionService) GetDueLearningCards(userID, bankID int, until time.Time) ([]models.ReviewCard, error) {
+	s.logger.Debug("Getting due learning cards", "user_id", userID, "bank_id", bankID, "until", until)
+
+	reviews, err := s.reviewRepo.GetDueLearningReviews(userID, bankID, until)
+	if err != nil {
+		s.logger.Error("Failed to get due learning reviews", "error", err)
+		return nil, err
+	}
+
+	return s.reviewCards(reviews), nil
+}
+
+// reviewCards retrieves the cards of reviews, in the direction of each review
+func (s *spacedRepetitionService) reviewCards(reviews []models.Review) []models.ReviewCard {
+	var cards []models.ReviewCard
 	for _, review := range reviews {
 		card, err := s.flashcardRepo.GetByID(review.FlashCardID)
 		if err != nil {
 			s.logger.Error("Failed to get card for review", "error", err)
 			continue
 		}
-		dueCards = append(dueCards, *card)
+		reviewCard := models.ReviewCard{FlashCard: *card, Direction: review.Direction}
+		if isReviewable(reviewCard) {
+			cards = append(cards, reviewCard)
+		}
 	}
-
-	return dueCards, nil
+	return cards
 }
 
-// getNewCards retrieves cards that the user hasn't reviewed yet
-func (s *spacedRepetitionService) getNewCards(userID, bankID, limit int) ([]models.FlashCard, error) {
-	return s.flashcardRepo.GetNewCards(userID, bankID, limit)
+// isReviewable checks that a card can be shown in its direction. Cloze cards need an example
+// that contains the word, which examples without it or edited since don't have.
+func isReviewable(card models.ReviewCard) bool {
+	if card.Direction != models.DirectionCloze {
+		return true
+	}
+	_, ok := cloze.Pick(card.Examples, card.Word)
+	return ok
 }
 
-// ProcessReview processes a card review and updates the review schedule
-func (s *spacedRepetitionService) ProcessReview(userID, cardID int, quality int) error {
-	s.logger.Debug("Processing review", "user_id", userID, "card_id", cardID, "quality", quality)
+// ProcessReview processes a card review in one direction and updates the schedule of that direction.
+// The latency of the answer is kept in the review history, 0 if unknown.
+func (s *spacedRepetitionService) ProcessReview(userID, cardID int, direction string, quality int, latency time.Duration) (*models.ReviewAnswer, error) {
+	s.logger.Debug("Processing review", "user_id", userID, "card_id", cardID, "direction", direction, "quality", quality, "latency", latency)
 
 	// Get existing review or create a new one
-	review, err := s.reviewRepo.GetByUserAndCard(userID, cardID)
+	review, err := s.reviewRepo.GetByUserCardAndDirection(userID, cardID, direction)
 	if err != nil {
 		// Create new review if it doesn't exist
-		review = models.NewReview(userID, cardID)
+		review = models.NewReview(userID, cardID, direction)
 		err = s.reviewRepo.Create(review)
 		if err != nil {
 			s.logger.Error("Failed to create review", "error", err)
-			return err
+			return nil, err
 		}
 	}
 
-	// Calculate next review date using the algorithm
-	dueDate, interval, easeFactor := s.algorithm.CalculateNextReview(review, quality)
+	// Keep a copy of the review before the answer for the history
+	previous := *review
+
+	// Calculate next review date using the user's algorithm and learning steps
+	schedule := s.schedulerForUser(userID).Schedule(review, quality)
 
 	// Update review
-	review.DueDate = dueDate
-	review.Interval = interval
-	review.EaseFactor = easeFactor
-	review.Repetitions++
+	review.DueDate = schedule.DueDate
+	review.Interval = schedule.Interval
+	review.EaseFactor = schedule.EaseFactor
+	review.Stability = schedule.Stability
+	review.Difficulty = schedule.Difficulty
+	review.State = schedule.State
+	review.Step = schedule.Step
 	review.LastReviewed = time.Now()
 
-	// Save updated review
-	err = s.reviewRepo.Update(review)
+	// Answers during learning steps are not counted as repetitions
+	if schedule.State == models.ReviewStateReview {
+		review.Repetitions++
+	}
+
+	// Save updated review together with the answer in the review history
+	log := models.NewReviewLog(&previous, review, quality, latency)
+	err = s.reviewLogRepo.CreateWithReview(review, log)
 	if err != nil {
-		s.logger.Error("Failed to update review", "error", err)
+		s.logger.Error("Failed to save review", "error", err)
+		return nil, err
+	}
+
+	return &models.ReviewAnswer{
+		Previous: previous,
+		Review:   *review,
+		LogID:    log.ID,
+	}, nil
+}
+
+// UndoReview takes back an answer: the review returns to its state before the answer and
+// the answer leaves the review history. Only the latest answer of a card in a direction
+// can be taken back, ErrNotFound is returned for others.
+func (s *spacedRepetitionService) UndoReview(userID int, answer *models.ReviewAnswer) error {
+	s.logger.Info("Undoing review", "user_id", userID, "card_id", answer.Review.FlashCardID, "direction", answer.Review.Direction, "log_id", answer.LogID)
+
+	if answer.Previous.UserID != userID || answer.Previous.ID != answer.Review.ID {
+		return ErrInvalidInput
+	}
+
+	// The answer is only deleted and the review restored if it is still the latest answer
+	previous := answer.Previous
+	if err := s.reviewLogRepo.DeleteLatest(answer.LogID, &previous); err != nil {
+		if !errors.Is(err, ErrNotFound) {
+			s.logger.Error("Failed to undo review", "error", err)
+		}
 		return err
 	}
 
 	return nil
 }
 
+// schedulerForUser builds the scheduler configured in the user's settings
+func (s *spacedRepetitionService) schedulerForUser(userID int) *spaced_repetition.StepScheduler {
+	settings, err := s.settingsRepo.GetByUserID(userID)
+	if err != nil {
+		return spaced_repetition.NewStepScheduler(
+			s.algorithm,
+			spaced_repetition.DefaultLearningSteps,
+			spaced_repetition.DefaultRelearningSteps,
+		)
+	}
+
+	return spaced_repetition.NewStepScheduler(
+		s.algorithmForUser(userID, settings.Settings.Algorithm),
+		stepDurations(settings.Settings.LearningSteps, spaced_repetition.DefaultLearningSteps),
+		stepDurations(settings.Settings.RelearningSteps, spaced_repetition.DefaultRelearningSteps),
+	)
+}
+
+// algorithmForUser builds the spaced repetition algorithm configured in the user's settings
+func (s *spacedRepetitionService) algorithmForUser(userID int, config models.AlgorithmConfig) spaced_repetition.Algorithm {
+	if config.Type == "" {
+		return s.algorithm
+	}
+
+	algorithm, err := spaced_repetition.CreateAlgorithm(config)
+	if err != nil {
+		s.logger.Warn("Invalid algorithm settings, using default algorithm",
+			"error", err,
+			"user_id", userID,
+			"algorithm", config.Type,
+		)
+		return s.algorithm
+	}
+
+	return algorithm
+}
+
+// stepDurations converts steps in minutes to durations, nil means the default steps
+func stepDurations(minutes []int, defaults []time.Duration) []time.Duration {
+	if minutes == nil {
+		return defaults
+	}
+
+	steps := make([]time.Duration, len(minutes))
+	for i, m := range minutes {
+		steps[i] = time.Duration(m) * time.Minute
+	}
+	return steps
+}
+
 // GetReviewStats retrieves review statistics for a user
 func (s *spacedRepetitionService) GetReviewStats(userID int) (int, int, error) {
 	s.logger.Debug("Getting review stats", "user_id", userID)
@@ -156,3 +393,22 @@ func (s *spacedRepetitionService) GetReviewStats(userID int) (int, int, error) {
 
 	return totalCards, dueCards, nil
 }
+
+// GetReviewHistory retrieves the answer history of a card for a user
+func (s *spacedRepetitionService) GetReviewHistory(userID, cardID int) ([]models.ReviewLog, error) {
+	s.logger.Debug("Getting review history", "user_id", userID, "card_id", cardID)
+	return s.reviewLogRepo.GetByUserAndCard(userID, cardID)
+}
+
+// GetLatencyStats retrieves how long a user takes to answer the cards of a bank, with a page of
+// the cards from slowest to fastest
+func (s *spacedRepetitionService) GetLatencyStats(userID, bankID, limit, offset int) (*models.LatencyStats, error) {
+	s.logger.Debug("Getting latency stats", "user_id", userID, "bank_id", bankID, "limit", limit, "offset", offset)
+	return s.reviewLogRepo.GetLatencyStats(userID, bankID, limit, offset)
+}
+
+// GetReviews retrieves a user's reviews of a card, one for every direction they have reviewed
+func (s *spacedRepetitionService) GetReviews(userID, cardID int) ([]models.Review, error) {
+	s.logger.Debug("Getting reviews", "user_id", userID, "card_id", cardID)
+	return s.reviewRepo.GetByUserAndCard(userID, cardID)
+}
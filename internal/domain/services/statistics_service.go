@@ -13,6 +13,7 @@ type StatisticsService interface {
 	GetUserStatistics(userID int) ([]models.Statistics, error)
 	GetBankStatistics(userID, bankID int) (*models.Statistics, error)
 	IncrementReviewed(userID, bankID int) error
+	DecrementReviewed(userID, bankID int) error
 	IncrementLearned(userID, bankID int) error
 	UpdateStreak(userID int) error
 }
@@ -69,6 +70,23 @@ func (s *statisticsService) IncrementReviewed(userID, bankID int) error {
 	return s.repo.Update(stats)
 }
 
+// DecrementReviewed takes back an increment of the cards reviewed count, e.g. for an undone answer
+func (s *statisticsService) DecrementReviewed(userID, bankID int) error {
+	s.logger.Debug("Decrementing cards reviewed", "user_id", userID, "bank_id", bankID)
+
+	stats, err := s.GetBankStatistics(userID, bankID)
+	if err != nil {
+		return err
+	}
+
+	if stats.CardsReviewed > 0 {
+		stats.CardsReviewed--
+	}
+	stats.UpdatedAt = time.Now()
+
+	return s.repo.Update(stats)
+}
+
 // IncrementLearned increments the cards learned count
 func (s *statisticsService) IncrementLearned(userID, bankID int) error {
 	s.logger.Debug("Incrementing cards learned", "user_id", userID, "bank_id", bankID)
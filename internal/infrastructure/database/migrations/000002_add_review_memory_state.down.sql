@@ -0,0 +1,3 @@
+-- Drop FSRS memory state from reviews
+ALTER TABLE reviews DROP COLUMN IF EXISTS difficulty;
+ALTER TABLE reviews DROP COLUMN IF EXISTS stability;
@@ -0,0 +1,3 @@
+-- Add FSRS memory state to reviews
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS stability FLOAT NOT NULL DEFAULT 0;
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS difficulty FLOAT NOT NULL DEFAULT 0;
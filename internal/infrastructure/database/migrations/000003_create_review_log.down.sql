@@ -0,0 +1,6 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_review_log_flash_card_id;
+DROP INDEX IF EXISTS idx_review_log_user_id_answered_at;
+
+-- Drop table
+DROP TABLE IF EXISTS review_log;
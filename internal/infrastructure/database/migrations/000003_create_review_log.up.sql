@@ -0,0 +1,17 @@
+-- Create review_log table
+CREATE TABLE IF NOT EXISTS review_log (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    flash_card_id INTEGER NOT NULL REFERENCES flash_cards(id) ON DELETE CASCADE,
+    rating INTEGER NOT NULL,
+    elapsed_days INTEGER NOT NULL DEFAULT 0,
+    previous_interval INTEGER NOT NULL DEFAULT 0,
+    new_interval INTEGER NOT NULL DEFAULT 0,
+    previous_ease_factor FLOAT NOT NULL DEFAULT 0,
+    new_ease_factor FLOAT NOT NULL DEFAULT 0,
+    answered_at TIMESTAMP NOT NULL
+);
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_review_log_user_id_answered_at ON review_log(user_id, answered_at);
+CREATE INDEX IF NOT EXISTS idx_review_log_flash_card_id ON review_log(flash_card_id);
@@ -0,0 +1,6 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_reviews_state;
+
+-- Drop learning state from reviews
+ALTER TABLE reviews DROP COLUMN IF EXISTS step;
+ALTER TABLE reviews DROP COLUMN IF EXISTS state;
@@ -0,0 +1,9 @@
+-- Add learning state to reviews
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS state VARCHAR(20) NOT NULL DEFAULT 'new';
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS step INTEGER NOT NULL DEFAULT 0;
+
+-- Cards that have been answered before are already in review
+UPDATE reviews SET state = 'review' WHERE repetitions > 0;
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_reviews_state ON reviews(state);
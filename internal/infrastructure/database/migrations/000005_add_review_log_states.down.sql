@@ -0,0 +1,3 @@
+-- Drop review states from review_log
+ALTER TABLE review_log DROP COLUMN IF EXISTS new_state;
+ALTER TABLE review_log DROP COLUMN IF EXISTS previous_state;
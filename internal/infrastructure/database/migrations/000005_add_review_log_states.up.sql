@@ -0,0 +1,3 @@
+-- Add review states to review_log
+ALTER TABLE review_log ADD COLUMN IF NOT EXISTS previous_state VARCHAR(20) NOT NULL DEFAULT '';
+ALTER TABLE review_log ADD COLUMN IF NOT EXISTS new_state VARCHAR(20) NOT NULL DEFAULT '';
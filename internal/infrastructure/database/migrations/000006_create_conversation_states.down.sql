@@ -0,0 +1,5 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_conversation_states_expires_at;
+
+-- Drop table
+DROP TABLE IF EXISTS conversation_states;
@@ -0,0 +1,10 @@
+-- Create conversation_states table for multi-step bot interactions
+CREATE TABLE IF NOT EXISTS conversation_states (
+    telegram_id BIGINT PRIMARY KEY,
+    state JSONB NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_conversation_states_expires_at ON conversation_states(expires_at);
@@ -0,0 +1,3 @@
+-- Drop languages from card banks
+ALTER TABLE card_banks DROP COLUMN IF EXISTS target_language;
+ALTER TABLE card_banks DROP COLUMN IF EXISTS source_language;
@@ -0,0 +1,3 @@
+-- Add the language of the words and the language of their definitions to card banks
+ALTER TABLE card_banks ADD COLUMN IF NOT EXISTS source_language VARCHAR(10) NOT NULL DEFAULT 'en';
+ALTER TABLE card_banks ADD COLUMN IF NOT EXISTS target_language VARCHAR(10) NOT NULL DEFAULT 'en';
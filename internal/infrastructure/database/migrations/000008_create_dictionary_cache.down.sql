@@ -0,0 +1,5 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_dictionary_cache_expires_at;
+
+-- Drop table
+DROP TABLE IF EXISTS dictionary_cache;
@@ -0,0 +1,10 @@
+-- Create dictionary_cache table for dictionary lookups, an empty list caches a word that wasn't found
+CREATE TABLE IF NOT EXISTS dictionary_cache (
+    cache_key TEXT PRIMARY KEY,
+    definitions JSONB NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_dictionary_cache_expires_at ON dictionary_cache(expires_at);
@@ -0,0 +1,4 @@
+-- Drop the pronunciation from flash cards
+ALTER TABLE flash_cards DROP COLUMN IF EXISTS audio_file_id;
+ALTER TABLE flash_cards DROP COLUMN IF EXISTS audio_url;
+ALTER TABLE flash_cards DROP COLUMN IF EXISTS phonetic;
@@ -0,0 +1,4 @@
+-- Add the pronunciation of the word to flash cards
+ALTER TABLE flash_cards ADD COLUMN IF NOT EXISTS phonetic VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE flash_cards ADD COLUMN IF NOT EXISTS audio_url TEXT NOT NULL DEFAULT '';
+ALTER TABLE flash_cards ADD COLUMN IF NOT EXISTS audio_file_id VARCHAR(255) NOT NULL DEFAULT '';
@@ -0,0 +1,3 @@
+-- Drop synonyms and antonyms from flash cards
+ALTER TABLE flash_cards DROP COLUMN IF EXISTS antonyms;
+ALTER TABLE flash_cards DROP COLUMN IF EXISTS synonyms;
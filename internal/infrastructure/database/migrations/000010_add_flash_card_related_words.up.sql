@@ -0,0 +1,3 @@
+-- Add synonyms and antonyms of the word to flash cards
+ALTER TABLE flash_cards ADD COLUMN IF NOT EXISTS synonyms JSONB NOT NULL DEFAULT '[]'::JSONB;
+ALTER TABLE flash_cards ADD COLUMN IF NOT EXISTS antonyms JSONB NOT NULL DEFAULT '[]'::JSONB;
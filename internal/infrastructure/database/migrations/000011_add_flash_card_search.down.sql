@@ -0,0 +1,3 @@
+-- Drop the full-text search vector from flash cards
+DROP INDEX IF EXISTS idx_flash_cards_search_vector;
+ALTER TABLE flash_cards DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,10 @@
+-- Add a full-text search vector over the word, definition and examples of flash cards.
+-- The simple configuration doesn't stem, so it works for words of every language.
+ALTER TABLE flash_cards ADD COLUMN IF NOT EXISTS search_vector TSVECTOR GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', word), 'A') ||
+    setweight(to_tsvector('simple', definition), 'B') ||
+    setweight(jsonb_to_tsvector('simple', examples, '["string"]'), 'C')
+) STORED;
+
+-- Create indexes
+CREATE INDEX IF NOT EXISTS idx_flash_cards_search_vector ON flash_cards USING GIN (search_vector);
@@ -0,0 +1,10 @@
+-- Keep a single review per card, the one in the direction cards were always reviewed in
+ALTER TABLE review_log DROP COLUMN IF EXISTS direction;
+
+DELETE FROM reviews WHERE direction <> 'reverse';
+ALTER TABLE reviews DROP CONSTRAINT IF EXISTS reviews_user_id_flash_card_id_direction_key;
+ALTER TABLE reviews ADD CONSTRAINT reviews_user_id_flash_card_id_key UNIQUE (user_id, flash_card_id);
+ALTER TABLE reviews DROP COLUMN IF EXISTS direction;
+
+ALTER TABLE flash_cards DROP COLUMN IF EXISTS template;
+ALTER TABLE card_banks DROP COLUMN IF EXISTS card_template;
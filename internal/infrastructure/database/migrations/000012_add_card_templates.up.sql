@@ -0,0 +1,10 @@
+-- Cards are reviewed in the directions their template generates, the bank's template unless they choose their own
+ALTER TABLE card_banks ADD COLUMN IF NOT EXISTS card_template VARCHAR(10) NOT NULL DEFAULT 'reverse';
+ALTER TABLE flash_cards ADD COLUMN IF NOT EXISTS template VARCHAR(10) NOT NULL DEFAULT '';
+
+-- Every direction of a card is scheduled on its own. Existing reviews were definition → word.
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS direction VARCHAR(10) NOT NULL DEFAULT 'reverse';
+ALTER TABLE reviews DROP CONSTRAINT IF EXISTS reviews_user_id_flash_card_id_key;
+ALTER TABLE reviews ADD CONSTRAINT reviews_user_id_flash_card_id_direction_key UNIQUE (user_id, flash_card_id, direction);
+
+ALTER TABLE review_log ADD COLUMN IF NOT EXISTS direction VARCHAR(10) NOT NULL DEFAULT 'reverse';
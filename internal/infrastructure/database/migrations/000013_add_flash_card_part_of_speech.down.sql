@@ -0,0 +1,2 @@
+-- Drop the part of speech from flash cards
+ALTER TABLE flash_cards DROP COLUMN IF EXISTS part_of_speech;
@@ -0,0 +1,2 @@
+-- Keep the part of speech of the chosen definition on flash cards
+ALTER TABLE flash_cards ADD COLUMN IF NOT EXISTS part_of_speech VARCHAR(50) NOT NULL DEFAULT '';
@@ -0,0 +1,5 @@
+-- Drop cloze cards with their progress and history
+DELETE FROM review_log WHERE direction = 'cloze';
+DELETE FROM reviews WHERE direction = 'cloze';
+
+ALTER TABLE card_banks DROP COLUMN IF EXISTS cloze_cards;
@@ -0,0 +1,2 @@
+-- Banks can add a cloze card to every card with an example, reviewed apart from the card's other directions
+ALTER TABLE card_banks ADD COLUMN IF NOT EXISTS cloze_cards BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,2 @@
+-- Drop answer times from review_log
+ALTER TABLE review_log DROP COLUMN IF EXISTS latency_ms;
@@ -0,0 +1,2 @@
+-- How long each answer took, from showing the card to flipping or answering it. 0 means unknown.
+ALTER TABLE review_log ADD COLUMN IF NOT EXISTS latency_ms INTEGER NOT NULL DEFAULT 0;
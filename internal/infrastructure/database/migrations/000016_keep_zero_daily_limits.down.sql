@@ -0,0 +1,3 @@
+-- Dropped zeros meant the default and missing limits still do, there is nothing to restore.
+-- Limits set to 0 since are read as the default again.
+SELECT 1;
@@ -0,0 +1,19 @@
+-- Daily limits of 0 used to mean the default and are real limits now, so drop the stored zeros
+UPDATE user_settings
+SET settings = jsonb_set(settings, '{daily_limits}', (
+    SELECT COALESCE(jsonb_object_agg(l.key, l.value), '{}'::jsonb)
+    FROM jsonb_each(settings->'daily_limits') l
+    WHERE l.value <> '0'::jsonb
+))
+WHERE jsonb_typeof(settings->'daily_limits') = 'object';
+
+UPDATE user_settings
+SET settings = jsonb_set(settings, '{bank_daily_limits}', (
+    SELECT COALESCE(jsonb_object_agg(bank.key, (
+        SELECT COALESCE(jsonb_object_agg(l.key, l.value), '{}'::jsonb)
+        FROM jsonb_each(bank.value) l
+        WHERE l.value <> '0'::jsonb
+    )), '{}'::jsonb)
+    FROM jsonb_each(settings->'bank_daily_limits') bank
+))
+WHERE jsonb_typeof(settings->'bank_daily_limits') = 'object';
@@ -0,0 +1,80 @@
+package dictionary
+
+import (
+	"sync"
+	"time"
+)
+
+// circuitBreaker stops calls to a failing provider. After threshold consecutive failures
+// it opens and rejects calls until the cooldown has passed, then lets a single trial call
+// through: success closes it again, failure keeps it open for another cooldown.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	openUntil time.Time
+	trial     bool // a trial call is in progress
+}
+
+// newCircuitBreaker creates a closed circuit breaker
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	if threshold <= 0 {
+		threshold = 1
+	}
+
+	return &circuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+	}
+}
+
+// allow reports whether a call may be made
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.threshold {
+		return true
+	}
+
+	// Open, until the cooldown passes and nobody else is trying
+	if time.Now().Before(b.openUntil) || b.trial {
+		return false
+	}
+
+	b.trial = true
+	return true
+}
+
+// success records a successful call and closes the breaker
+func (b *circuitBreaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	b.trial = false
+}
+
+// failure records a failed call, opening the breaker once the threshold is reached
+func (b *circuitBreaker) failure() (opened bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.trial = false
+
+	if b.failures >= b.threshold {
+		b.openUntil = time.Now().Add(b.cooldown)
+		return true
+	}
+	return false
+}
+
+// abort ends a call that neither succeeded nor failed, such as one cancelled by the caller
+func (b *circuitBreaker) abort() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trial = false
+}
@@ -0,0 +1,242 @@
+package dictionary
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/supercakecrumb/flash-cards-language-tg-bot/internal/domain/models"
+	"github.com/supercakecrumb/flash-cards-language-tg-bot/internal/repository"
+	"github.com/supercakecrumb/flash-cards-language-tg-bot/pkg/lru"
+)
+
+// cacheMaintenanceInterval is how often expired lookups are removed and cache statistics logged
+const cacheMaintenanceInterval = 10 * time.Minute
+
+// CacheConfig configures a caching dictionary service
+type CacheConfig struct {
+	TTL         time.Duration // how long definitions are cached
+	NegativeTTL time.Duration // how long a word without definitions is cached
+	MemorySize  int           // number of lookups kept in memory
+}
+
+// CacheStats counts how lookups were served
+type CacheStats struct {
+	MemoryHits   int64 // served from memory
+	StoreHits    int64 // served from the persistent store
+	NegativeHits int64 // hits for words without definitions, included in the hits above
+	Misses       int64 // looked up in the dictionary
+}
+
+// CacheStore persists cached lookups so they survive restarts.
+// Implementations must be safe for concurrent use.
+type CacheStore interface {
+	Get(key string) ([]Definition, time.Time, bool, error) // definitions, expiry
+	Set(key string, definitions []Definition, ttl time.Duration) error
+	DeleteExpired() (int64, error)
+}
+
+// CachingService implements the DictionaryService interface by caching the lookups of
+// another dictionary service, in memory and optionally in a persistent store
+type CachingService struct {
+	next   DictionaryService
+	store  CacheStore // nil to cache in memory only
+	config CacheConfig
+	logger *slog.Logger
+	memory *lru.Cache[string, cachedLookup]
+
+	memoryHits   atomic.Int64
+	storeHits    atomic.Int64
+	negativeHits atomic.Int64
+	misses       atomic.Int64
+}
+
+// cachedLookup is a lookup kept in memory with its expiry
+type cachedLookup struct {
+	definitions []Definition
+	expiresAt   time.Time
+}
+
+// NewCachingService creates a dictionary service that caches the lookups of next
+func NewCachingService(next DictionaryService, store CacheStore, config CacheConfig, logger *slog.Logger) *CachingService {
+	return &CachingService{
+		next:   next,
+		store:  store,
+		config: config,
+		logger: logger,
+		memory: lru.New[string, cachedLookup](config.MemorySize),
+	}
+}
+
+// Supports reports whether the underlying dictionary supports a language pair
+func (s *CachingService) Supports(languages Languages) bool {
+	return s.next.Supports(languages)
+}
+
+// GetDefinitions retrieves definitions for a word from the cache, or from the dictionary on a miss
+func (s *CachingService) GetDefinitions(ctx context.Context, word string, languages Languages) ([]Definition, error) {
+	key := cacheKey(word, languages)
+
+	if cached, ok := s.memory.Get(key); ok && time.Now().Before(cached.expiresAt) {
+		s.memoryHits.Add(1)
+		s.countNegative(cached.definitions)
+		return cached.definitions, nil
+	}
+
+	if s.store != nil {
+		definitions, expiresAt, found, err := s.store.Get(key)
+		if err != nil {
+			s.logger.Warn("Failed to read dictionary cache", "error", err, "key", key)
+		}
+		if found {
+			s.storeHits.Add(1)
+			s.countNegative(definitions)
+			// Keep the expiry it was stored with, promoting it doesn't make it fresh
+			s.memory.Add(key, cachedLookup{
+				definitions: definitions,
+				expiresAt:   expiresAt,
+			})
+			return definitions, nil
+		}
+	}
+
+	s.misses.Add(1)
+
+	// Errors are not cached, the next lookup tries again
+	definitions, err := s.next.GetDefinitions(ctx, word, languages)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := s.ttl(definitions)
+	s.memory.Add(key, cachedLookup{
+		definitions: definitions,
+		expiresAt:   time.Now().Add(ttl),
+	})
+
+	if s.store != nil {
+		if err := s.store.Set(key, definitions, ttl); err != nil {
+			s.logger.Warn("Failed to write dictionary cache", "error", err, "key", key)
+		}
+	}
+
+	return definitions, nil
+}
+
+// Stats returns how lookups were served since the service was created
+func (s *CachingService) Stats() CacheStats {
+	return CacheStats{
+		MemoryHits:   s.memoryHits.Load(),
+		StoreHits:    s.storeHits.Load(),
+		NegativeHits: s.negativeHits.Load(),
+		Misses:       s.misses.Load(),
+	}
+}
+
+// Run periodically removes expired lookups from the store and logs cache statistics until ctx is done
+func (s *CachingService) Run(ctx context.Context) {
+	ticker := time.NewTicker(cacheMaintenanceInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if s.store != nil {
+				deleted, err := s.store.DeleteExpired()
+				if err != nil {
+					s.logger.Error("Failed to delete expired dictionary lookups", "error", err)
+				} else if deleted > 0 {
+					s.logger.Debug("Deleted expired dictionary lookups", "count", deleted)
+				}
+			}
+
+			stats := s.Stats()
+			s.logger.Info("Dictionary cache statistics",
+				"memory_hits", stats.MemoryHits,
+				"store_hits", stats.StoreHits,
+				"negative_hits", stats.NegativeHits,
+				"misses", stats.Misses,
+				"memory_entries", s.memory.Len(),
+			)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// ttl returns how long a lookup is cached, words without definitions are cached for less time
+func (s *CachingService) ttl(definitions []Definition) time.Duration {
+	if len(definitions) == 0 {
+		return s.config.NegativeTTL
+	}
+	return s.config.TTL
+}
+
+// countNegative counts a hit for a word without definitions
+func (s *CachingService) countNegative(definitions []Definition) {
+	if len(definitions) == 0 {
+		s.negativeHits.Add(1)
+	}
+}
+
+// cacheKey builds the key a lookup is cached by
+func cacheKey(word string, languages Languages) string {
+	return languages.Source + ":" + languages.Target + ":" + strings.TrimSpace(strings.ToLower(word))
+}
+
+// postgresCacheStore keeps cached lookups in the database as JSON
+type postgresCacheStore struct {
+	repo repository.DictionaryCacheRepository
+}
+
+// NewPostgresCacheStore creates a cache store backed by the dictionary cache repository
+func NewPostgresCacheStore(repo repository.DictionaryCacheRepository) CacheStore {
+	return &postgresCacheStore{
+		repo: repo,
+	}
+}
+
+// Get retrieves a cached lookup with its expiry
+func (s *postgresCacheStore) Get(key string) ([]Definition, time.Time, bool, error) {
+	entry, err := s.repo.Get(key)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, time.Time{}, false, nil
+		}
+		return nil, time.Time{}, false, err
+	}
+
+	var definitions []Definition
+	if err := json.Unmarshal([]byte(entry.Definitions), &definitions); err != nil {
+		return nil, time.Time{}, false, err
+	}
+
+	// Timestamps are stored in server local time and read back without a time zone
+	e := entry.ExpiresAt
+	expiresAt := time.Date(e.Year(), e.Month(), e.Day(), e.Hour(), e.Minute(), e.Second(), e.Nanosecond(), time.Local)
+
+	return definitions, expiresAt, true, nil
+}
+
+// Set stores a lookup until ttl passes
+func (s *postgresCacheStore) Set(key string, definitions []Definition, ttl time.Duration) error {
+	// A word without definitions is stored as an empty list rather than null
+	if definitions == nil {
+		definitions = []Definition{}
+	}
+
+	data, err := json.Marshal(definitions)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.Save(models.NewDictionaryCacheEntry(key, string(data), ttl))
+}
+
+// DeleteExpired removes all expired lookups
+func (s *postgresCacheStore) DeleteExpired() (int64, error) {
+	return s.repo.DeleteExpired(time.Now())
+}
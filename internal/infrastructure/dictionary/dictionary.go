@@ -1,10 +1,26 @@
 package dictionary
 
+import (
+	"context"
+	"fmt"
+	"hash/crc32"
+	"html"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/supercakecrumb/flash-cards-language-tg-bot/internal/domain/models"
+)
+
 // Definition represents a word definition from the dictionary API
 type Definition struct {
 	ID           string
 	Text         string
 	PartOfSpeech string
+	Phonetic     string // pronunciation, usually in IPA
+	AudioURL     string // recording of the pronunciation
+	Synonyms     []string
+	Antonyms     []string
 	Examples     []Example
 }
 
@@ -14,7 +30,65 @@ type Example struct {
 	Text string
 }
 
+// Languages is the language of a word and the language its definitions are written in,
+// as ISO 639 codes. Both are the same for monolingual dictionaries.
+type Languages struct {
+	Source string
+	Target string
+}
+
+// String returns the language pair as "source→target"
+func (l Languages) String() string {
+	return l.Source + "→" + l.Target
+}
+
 // DictionaryService defines the interface for dictionary services
 type DictionaryService interface {
-	GetDefinitions(word string) ([]Definition, error)
+	GetDefinitions(ctx context.Context, word string, languages Languages) ([]Definition, error)
+	Supports(languages Languages) bool
+}
+
+// statusError reports an unexpected HTTP status of a dictionary API. Only server errors and
+// rate limiting mean the API is unavailable, other statuses are problems with the request.
+func statusError(status int) error {
+	if status >= http.StatusInternalServerError || status == http.StatusTooManyRequests {
+		return fmt.Errorf("%w: status %d", models.ErrExternalAPIError, status)
+	}
+	return fmt.Errorf("unexpected status %d", status)
+}
+
+// htmlTagPattern matches the HTML tags some dictionaries wrap their definitions in
+var htmlTagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// stripHTML removes HTML tags and entities from dictionary text
+func stripHTML(text string) string {
+	return strings.TrimSpace(html.UnescapeString(htmlTagPattern.ReplaceAllString(text, "")))
+}
+
+// appendWords appends the words that aren't already present, ignoring case and blank words
+func appendWords(words []string, more ...string) []string {
+	for _, word := range more {
+		word = strings.TrimSpace(word)
+		if word == "" {
+			continue
+		}
+
+		duplicate := false
+		for _, existing := range words {
+			if strings.EqualFold(existing, word) {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+// definitionID builds a short definition ID that is unique per provider, language pair and word.
+// The word is hashed to keep callback data within Telegram's 64 byte limit.
+func definitionID(provider string, languages Languages, word string, i, j int) string {
+	return fmt.Sprintf("%s_%s_%s_%08x_%d_%d", provider, languages.Source, languages.Target, crc32.ChecksumIEEE([]byte(word)), i, j)
 }
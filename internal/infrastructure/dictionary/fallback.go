@@ -0,0 +1,262 @@
+package dictionary
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/supercakecrumb/flash-cards-language-tg-bot/internal/domain/models"
+)
+
+// Provider is a dictionary service with the name it is configured and logged by
+type Provider struct {
+	Name    string
+	Service DictionaryService
+}
+
+// FallbackConfig configures how a fallback service calls its providers
+type FallbackConfig struct {
+	Timeout          time.Duration // limit for a single call to a provider
+	Retries          int           // extra calls to a provider after it fails
+	Backoff          time.Duration // delay before the first retry, doubled for every further retry
+	BreakerThreshold int           // consecutive failed lookups that open a provider's circuit breaker
+	BreakerCooldown  time.Duration // how long an open circuit breaker rejects lookups
+	Merge            bool          // combine the definitions of all providers instead of using the first that has any
+}
+
+// FallbackService implements the DictionaryService interface by trying its providers in order
+// of preference. Providers that fail are retried with backoff and then skipped, and a circuit
+// breaker stops calling providers that keep failing.
+type FallbackService struct {
+	providers []fallbackProvider
+	config    FallbackConfig
+	logger    *slog.Logger
+}
+
+// fallbackProvider is a provider with its circuit breaker
+type fallbackProvider struct {
+	Provider
+	breaker *circuitBreaker
+}
+
+// NewFallbackService creates a dictionary service over providers in order of preference
+func NewFallbackService(providers []Provider, config FallbackConfig, logger *slog.Logger) DictionaryService {
+	s := &FallbackService{
+		config: config,
+		logger: logger,
+	}
+
+	for _, provider := range providers {
+		s.providers = append(s.providers, fallbackProvider{
+			Provider: provider,
+			breaker:  newCircuitBreaker(config.BreakerThreshold, config.BreakerCooldown),
+		})
+	}
+
+	return s
+}
+
+// Supports reports whether any provider supports a language pair
+func (s *FallbackService) Supports(languages Languages) bool {
+	for _, provider := range s.providers {
+		if provider.Service.Supports(languages) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetDefinitions retrieves definitions for a word from the first provider that has any,
+// or from all providers when merging. It fails only if no provider could be asked; a word
+// that every provider answered without definitions has none and no error, so it can be cached.
+func (s *FallbackService) GetDefinitions(ctx context.Context, word string, languages Languages) ([]Definition, error) {
+	var definitions []Definition
+	var lastErr, unavailableErr error
+	supported, answered := false, false
+
+	for _, provider := range s.providers {
+		if !provider.Service.Supports(languages) {
+			continue
+		}
+		supported = true
+
+		if !provider.breaker.allow() {
+			s.logger.Debug("Skipping dictionary provider with open circuit breaker", "provider", provider.Name)
+			continue
+		}
+
+		found, err := s.lookup(ctx, provider, word, languages)
+		if err != nil {
+			// The caller gave up, the provider isn't to blame
+			if ctx.Err() != nil {
+				provider.breaker.abort()
+				return nil, ctx.Err()
+			}
+
+			// A provider that rejected the request is up, it isn't counted against it
+			if !isUnavailable(err) {
+				provider.breaker.abort()
+				s.logger.Warn("Dictionary provider rejected the lookup, trying the next one",
+					"error", err,
+					"provider", provider.Name,
+					"word", word,
+				)
+				lastErr = err
+				continue
+			}
+
+			if provider.breaker.failure() {
+				s.logger.Warn("Dictionary provider keeps failing, pausing it",
+					"provider", provider.Name,
+					"cooldown", s.config.BreakerCooldown,
+				)
+			}
+			s.logger.Warn("Dictionary provider failed, trying the next one",
+				"error", err,
+				"provider", provider.Name,
+				"word", word,
+			)
+			lastErr, unavailableErr = err, err
+			continue
+		}
+
+		provider.breaker.success()
+		answered = true
+
+		definitions = mergeDefinitions(definitions, found)
+		if len(definitions) > 0 && !s.config.Merge {
+			break
+		}
+	}
+
+	switch {
+	case !supported:
+		return nil, models.ErrUnsupportedLanguage
+	case !answered && unavailableErr != nil:
+		return nil, fmt.Errorf("%w: %v", models.ErrExternalAPIError, unavailableErr)
+	case !answered && lastErr != nil:
+		// Every provider that was asked rejected the lookup
+		return nil, lastErr
+	case !answered:
+		// Every provider is paused by its circuit breaker
+		return nil, models.ErrExternalAPIError
+	}
+
+	return definitions, nil
+}
+
+// lookup calls a provider, retrying with exponential backoff when it fails
+func (s *FallbackService) lookup(ctx context.Context, provider fallbackProvider, word string, languages Languages) ([]Definition, error) {
+	backoff := s.config.Backoff
+
+	for attempt := 0; ; attempt++ {
+		definitions, err := s.call(ctx, provider, word, languages)
+		if err == nil {
+			return definitions, nil
+		}
+
+		// Only an unavailable provider may answer on a later try
+		if !isUnavailable(err) || attempt >= s.config.Retries {
+			return nil, err
+		}
+
+		s.logger.Debug("Retrying dictionary provider",
+			"error", err,
+			"provider", provider.Name,
+			"attempt", attempt+1,
+			"backoff", backoff,
+		)
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		backoff *= 2
+	}
+}
+
+// call makes a single call to a provider within the configured timeout
+func (s *FallbackService) call(ctx context.Context, provider fallbackProvider, word string, languages Languages) ([]Definition, error) {
+	if s.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.config.Timeout)
+		defer cancel()
+	}
+
+	return provider.Service.GetDefinitions(ctx, word, languages)
+}
+
+// isUnavailable checks if an error means a provider couldn't answer for now: it reported itself
+// unavailable, timed out or couldn't be reached. Other errors won't go away by trying again.
+func isUnavailable(err error) bool {
+	if errors.Is(err, models.ErrExternalAPIError) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	// Transport errors of HTTP clients are net errors
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// mergeDefinitions appends the definitions that aren't already present, comparing their text.
+// The examples of a duplicate are added to the definition already present.
+func mergeDefinitions(definitions, more []Definition) []Definition {
+	for _, definition := range more {
+		key := definitionKey(definition.Text)
+
+		duplicate := -1
+		for i, existing := range definitions {
+			if definitionKey(existing.Text) == key {
+				duplicate = i
+				break
+			}
+		}
+
+		if duplicate < 0 {
+			definitions = append(definitions, definition)
+			continue
+		}
+
+		existing := &definitions[duplicate]
+		if existing.PartOfSpeech == "" {
+			existing.PartOfSpeech = definition.PartOfSpeech
+		}
+		if existing.Phonetic == "" {
+			existing.Phonetic = definition.Phonetic
+		}
+		if existing.AudioURL == "" {
+			existing.AudioURL = definition.AudioURL
+		}
+		// Copy on append, the words may be shared with the provider
+		existing.Synonyms = appendWords(existing.Synonyms[:len(existing.Synonyms):len(existing.Synonyms)], definition.Synonyms...)
+		existing.Antonyms = appendWords(existing.Antonyms[:len(existing.Antonyms):len(existing.Antonyms)], definition.Antonyms...)
+		for _, example := range definition.Examples {
+			if !hasExample(existing.Examples, example.Text) {
+				// Copy on append, the examples may be shared with the provider
+				existing.Examples = append(existing.Examples[:len(existing.Examples):len(existing.Examples)], example)
+			}
+		}
+	}
+
+	return definitions
+}
+
+// definitionKey normalizes a definition for comparison
+func definitionKey(text string) string {
+	return strings.TrimRight(strings.ToLower(strings.Join(strings.Fields(text), " ")), ".;")
+}
+
+// hasExample checks if an example with the same text is present
+func hasExample(examples []Example, text string) bool {
+	for _, example := range examples {
+		if definitionKey(example.Text) == definitionKey(text) {
+			return true
+		}
+	}
+	return false
+}
@@ -1,6 +1,7 @@
 package dictionary
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -31,6 +32,8 @@ type FreeDictionaryResponse []struct {
 			Synonyms   []string `json:"synonyms"`
 			Antonyms   []string `json:"antonyms"`
 		} `json:"definitions"`
+		Synonyms []string `json:"synonyms"`
+		Antonyms []string `json:"antonyms"`
 	} `json:"meanings"`
 }
 
@@ -44,8 +47,18 @@ func NewFreeDictionaryService(apiKey string) DictionaryService {
 	}
 }
 
+// Supports reports whether the Free Dictionary API has definitions for a language pair.
+// It only defines English words in English.
+func (s *FreeDictionaryService) Supports(languages Languages) bool {
+	return languages.Source == "en" && languages.Target == "en"
+}
+
 // GetDefinitions retrieves definitions for a word from the Free Dictionary API
-func (s *FreeDictionaryService) GetDefinitions(word string) ([]Definition, error) {
+func (s *FreeDictionaryService) GetDefinitions(ctx context.Context, word string, languages Languages) ([]Definition, error) {
+	if !s.Supports(languages) {
+		return nil, models.ErrUnsupportedLanguage
+	}
+
 	// Clean up the word
 	word = strings.TrimSpace(strings.ToLower(word))
 
@@ -53,15 +66,23 @@ func (s *FreeDictionaryService) GetDefinitions(word string) ([]Definition, error
 	url := fmt.Sprintf("https://api.dictionaryapi.dev/api/v2/entries/en/%s", word)
 
 	// Make the request
-	resp, err := s.httpClient.Get(url)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.httpClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
-	// Check response status
+	// Check response status, the API answers unknown words with 404
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
 	if resp.StatusCode != http.StatusOK {
-		return nil, models.ErrExternalAPIError
+		return nil, statusError(resp.StatusCode)
 	}
 
 	// Parse the response
@@ -74,6 +95,21 @@ func (s *FreeDictionaryService) GetDefinitions(word string) ([]Definition, error
 	var definitions []Definition
 
 	for _, entry := range apiResp {
+		// The first transcription and recording are used for every meaning
+		phonetic, audioURL := "", ""
+		for _, p := range entry.Phonetics {
+			if phonetic == "" {
+				phonetic = p.Text
+			}
+			if audioURL == "" && p.Audio != "" {
+				audioURL = p.Audio
+				// Older entries link the audio without a scheme
+				if strings.HasPrefix(audioURL, "//") {
+					audioURL = "https:" + audioURL
+				}
+			}
+		}
+
 		for i, meaning := range entry.Meanings {
 			for j, def := range meaning.Definitions {
 				// Create a unique ID for the definition
@@ -84,8 +120,14 @@ func (s *FreeDictionaryService) GetDefinitions(word string) ([]Definition, error
 					ID:           defID,
 					Text:         def.Definition,
 					PartOfSpeech: meaning.PartOfSpeech,
+					Phonetic:     phonetic,
+					AudioURL:     audioURL,
 				}
 
+				// Words related to the whole meaning apply to each of its definitions
+				definition.Synonyms = appendWords(appendWords(nil, def.Synonyms...), meaning.Synonyms...)
+				definition.Antonyms = appendWords(appendWords(nil, def.Antonyms...), meaning.Antonyms...)
+
 				// Add example if available
 				if def.Example != "" {
 					exID := fmt.Sprintf("%s_%d_%d_ex", word, i, j)
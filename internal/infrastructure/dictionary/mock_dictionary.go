@@ -1,5 +1,9 @@
 package dictionary
 
+import (
+	"context"
+)
+
 // MockDictionaryService implements the DictionaryService interface for testing
 type MockDictionaryService struct {
 	Definitions map[string][]Definition
@@ -12,8 +16,13 @@ func NewMockDictionaryService() *MockDictionaryService {
 	}
 }
 
+// Supports reports that the mock has definitions for every language pair
+func (s *MockDictionaryService) Supports(languages Languages) bool {
+	return true
+}
+
 // GetDefinitions returns mock definitions for a word
-func (s *MockDictionaryService) GetDefinitions(word string) ([]Definition, error) {
+func (s *MockDictionaryService) GetDefinitions(ctx context.Context, word string, languages Languages) ([]Definition, error) {
 	if defs, ok := s.Definitions[word]; ok {
 		return defs, nil
 	}
@@ -0,0 +1,43 @@
+package dictionary
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// ErrInvalidDictionaryFile is returned when a local dictionary file can't be read
+var ErrInvalidDictionaryFile = errors.New("invalid dictionary file")
+
+// NewOfflineDictionaryService creates a dictionary service that looks words up in a local
+// file instead of an API: a StarDict dictionary (its .ifo file) or a Wiktextract JSONL
+// extract of Wiktionary. The file is indexed on startup.
+//
+// StarDict dictionaries define words of one language in another, given by languages.
+// Wiktextract extracts may hold words of many languages, defined in languages.Target.
+func NewOfflineDictionaryService(path string, languages Languages) (DictionaryService, error) {
+	switch {
+	case strings.HasSuffix(path, ".ifo"):
+		return NewStarDictService(path, languages)
+	case strings.HasSuffix(path, ".jsonl"), strings.HasSuffix(path, ".json"):
+		return NewWiktextractService(path, languages.Target)
+	default:
+		return nil, ErrInvalidDictionaryFile
+	}
+}
+
+// lineBreakPattern matches the HTML tags that end a line of dictionary markup
+var lineBreakPattern = regexp.MustCompile(`(?i)<br\s*/?>|</(p|div|li|def|ex)>`)
+
+// markupLines converts dictionary markup to its non-empty lines of plain text
+func markupLines(text string) []string {
+	text = lineBreakPattern.ReplaceAllString(text, "\n")
+
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = stripHTML(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,263 @@
+package dictionary
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"html"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/supercakecrumb/flash-cards-language-tg-bot/internal/domain/models"
+)
+
+// StarDictService implements the DictionaryService interface with a StarDict dictionary:
+// an .ifo file with the dictionary's metadata, an .idx index and the .dict (or dictzipped
+// .dict.dz) articles. Every line of an article becomes a definition.
+type StarDictService struct {
+	languages        Languages
+	sameTypeSequence string
+	dict             io.ReaderAt
+	index            map[string][]entryRef // by lowercased word
+}
+
+// NewStarDictService loads the StarDict dictionary described by an .ifo file.
+// StarDict files don't record their languages, so they are given.
+func NewStarDictService(ifoPath string, languages Languages) (DictionaryService, error) {
+	info, err := readStarDictInfo(ifoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	offsetBits := 32
+	if info["idxoffsetbits"] == "64" {
+		offsetBits = 64
+	}
+
+	base := strings.TrimSuffix(ifoPath, ".ifo")
+
+	idx, err := readStarDictFile(base + ".idx")
+	if os.IsNotExist(err) {
+		idx, err = readStarDictFile(base + ".idx.gz")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	index, err := parseStarDictIndex(idx, offsetBits)
+	if err != nil {
+		return nil, err
+	}
+
+	// Articles are read from the file when it's uncompressed, dictzip files are
+	// gzip compatible and are decompressed to memory once
+	var dict io.ReaderAt
+	if file, err := os.Open(base + ".dict"); err == nil {
+		dict = file
+	} else {
+		data, err := readStarDictFile(base + ".dict.dz")
+		if err != nil {
+			return nil, err
+		}
+		dict = bytes.NewReader(data)
+	}
+
+	return &StarDictService{
+		languages:        languages,
+		sameTypeSequence: info["sametypesequence"],
+		dict:             dict,
+		index:            index,
+	}, nil
+}
+
+// readStarDictInfo reads the key=value pairs of an .ifo file
+func readStarDictInfo(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	if !scanner.Scan() || !strings.HasPrefix(scanner.Text(), "StarDict's dict ifo file") {
+		return nil, ErrInvalidDictionaryFile
+	}
+
+	info := make(map[string]string)
+	for scanner.Scan() {
+		if key, value, ok := strings.Cut(scanner.Text(), "="); ok {
+			info[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+
+	return info, scanner.Err()
+}
+
+// readStarDictFile reads a dictionary file, decompressing it if it is gzipped or dictzipped
+func readStarDictFile(path string) ([]byte, error) {
+	if !strings.HasSuffix(path, ".gz") && !strings.HasSuffix(path, ".dz") {
+		return os.ReadFile(path)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return io.ReadAll(reader)
+}
+
+// parseStarDictIndex parses an .idx file: null-terminated words, each followed by
+// the big-endian offset and size of its article
+func parseStarDictIndex(idx []byte, offsetBits int) (map[string][]entryRef, error) {
+	index := make(map[string][]entryRef)
+
+	offsetSize := offsetBits / 8
+	for len(idx) > 0 {
+		end := bytes.IndexByte(idx, 0)
+		if end < 0 || len(idx) < end+1+offsetSize+4 {
+			return nil, ErrInvalidDictionaryFile
+		}
+
+		word := strings.ToLower(string(idx[:end]))
+		idx = idx[end+1:]
+
+		var offset int64
+		if offsetSize == 8 {
+			offset = int64(binary.BigEndian.Uint64(idx))
+		} else {
+			offset = int64(binary.BigEndian.Uint32(idx))
+		}
+		size := int(binary.BigEndian.Uint32(idx[offsetSize:]))
+		idx = idx[offsetSize+4:]
+
+		index[word] = append(index[word], entryRef{offset: offset, size: size})
+	}
+
+	if len(index) == 0 {
+		return nil, ErrInvalidDictionaryFile
+	}
+
+	return index, nil
+}
+
+// Supports reports whether the dictionary is for a language pair
+func (s *StarDictService) Supports(languages Languages) bool {
+	return languages == s.languages
+}
+
+// GetDefinitions retrieves definitions for a word from the dictionary
+func (s *StarDictService) GetDefinitions(ctx context.Context, word string, languages Languages) ([]Definition, error) {
+	if !s.Supports(languages) {
+		return nil, models.ErrUnsupportedLanguage
+	}
+
+	// Clean up the word
+	word = strings.TrimSpace(strings.ToLower(word))
+
+	var definitions []Definition
+	for i, ref := range s.index[word] {
+		data := make([]byte, ref.size)
+		if _, err := s.dict.ReadAt(data, ref.offset); err != nil {
+			return nil, err
+		}
+
+		fields, err := s.parseArticle(data)
+		if err != nil {
+			return nil, err
+		}
+
+		phonetic := ""
+		var lines []string
+		for _, field := range fields {
+			switch field.kind {
+			case 't':
+				phonetic = string(field.data)
+			case 'm', 'l', 'y', 'k', 'w':
+				lines = append(lines, markupLines(html.EscapeString(string(field.data)))...)
+			case 'g', 'h', 'x':
+				lines = append(lines, markupLines(string(field.data))...)
+			}
+		}
+
+		for j, line := range lines {
+			definitions = append(definitions, Definition{
+				ID:       definitionID("sd", languages, word, i, j),
+				Text:     line,
+				Phonetic: phonetic,
+			})
+		}
+	}
+
+	return definitions, nil
+}
+
+// starDictField is one field of an article, its kind is a StarDict type character
+// such as 'm' for plain text, 'h' for HTML or 't' for phonetics
+type starDictField struct {
+	kind byte
+	data []byte
+}
+
+// parseArticle splits an article into its fields. Lower-case types are null-terminated
+// text, upper-case types are binary data prefixed with their size. With a same type
+// sequence the types are omitted and the last field runs to the end of the article.
+func (s *StarDictService) parseArticle(data []byte) ([]starDictField, error) {
+	var fields []starDictField
+
+	next := func(kind byte, last bool) error {
+		var value []byte
+		switch {
+		case last:
+			value, data = data, nil
+		case kind >= 'a' && kind <= 'z':
+			end := bytes.IndexByte(data, 0)
+			if end < 0 {
+				value, data = data, nil
+			} else {
+				value, data = data[:end], data[end+1:]
+			}
+		default:
+			if len(data) < 4 {
+				return ErrInvalidDictionaryFile
+			}
+			size := int(binary.BigEndian.Uint32(data))
+			if len(data) < 4+size {
+				return ErrInvalidDictionaryFile
+			}
+			value, data = data[4:4+size], data[4+size:]
+		}
+
+		fields = append(fields, starDictField{kind: kind, data: value})
+		return nil
+	}
+
+	if s.sameTypeSequence != "" {
+		for i := 0; i < len(s.sameTypeSequence); i++ {
+			if err := next(s.sameTypeSequence[i], i == len(s.sameTypeSequence)-1); err != nil {
+				return nil, err
+			}
+		}
+		return fields, nil
+	}
+
+	for len(data) > 0 {
+		kind := data[0]
+		data = data[1:]
+		if err := next(kind, false); err != nil {
+			return nil, err
+		}
+	}
+
+	return fields, nil
+}
@@ -0,0 +1,119 @@
+package dictionary
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/supercakecrumb/flash-cards-language-tg-bot/internal/domain/models"
+)
+
+// maxTranslations is the number of alternative translations offered for a word
+const maxTranslations = 5
+
+// TranslationService implements the DictionaryService interface using the MyMemory
+// translation API. Its "definitions" are translations of the word into the target language.
+type TranslationService struct {
+	apiKey     string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// TranslationResponse represents the response from the MyMemory API
+type TranslationResponse struct {
+	ResponseData struct {
+		TranslatedText string `json:"translatedText"`
+	} `json:"responseData"`
+	Matches []struct {
+		Translation string `json:"translation"`
+	} `json:"matches"`
+}
+
+// NewTranslationService creates a new bilingual translation service.
+// The API key is optional and raises the daily quota.
+func NewTranslationService(apiKey string) DictionaryService {
+	return &TranslationService{
+		apiKey:  apiKey,
+		baseURL: "https://api.mymemory.translated.net/get",
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// Supports reports whether a language pair can be translated, which needs two different languages
+func (s *TranslationService) Supports(languages Languages) bool {
+	return languages.Source != languages.Target &&
+		models.IsValidLanguage(languages.Source) &&
+		models.IsValidLanguage(languages.Target)
+}
+
+// GetDefinitions retrieves translations of a word, one definition per translation
+func (s *TranslationService) GetDefinitions(ctx context.Context, word string, languages Languages) ([]Definition, error) {
+	if !s.Supports(languages) {
+		return nil, models.ErrUnsupportedLanguage
+	}
+
+	// Clean up the word
+	word = strings.TrimSpace(strings.ToLower(word))
+
+	params := url.Values{}
+	params.Set("q", word)
+	params.Set("langpair", languages.Source+"|"+languages.Target)
+	if s.apiKey != "" {
+		params.Set("key", s.apiKey)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.baseURL+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusError(resp.StatusCode)
+	}
+
+	var apiResp TranslationResponse
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		return nil, err
+	}
+
+	// The best translation comes first, followed by the other matches
+	translations := []string{apiResp.ResponseData.TranslatedText}
+	for _, match := range apiResp.Matches {
+		translations = append(translations, match.Translation)
+	}
+
+	var definitions []Definition
+	seen := make(map[string]bool)
+	for _, translation := range translations {
+		translation = stripHTML(translation)
+		key := strings.ToLower(translation)
+
+		// MyMemory echoes the word back when it has no translation
+		if translation == "" || key == word || seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		definitions = append(definitions, Definition{
+			ID:   definitionID("tr", languages, word, len(definitions), 0),
+			Text: translation,
+		})
+
+		if len(definitions) == maxTranslations {
+			break
+		}
+	}
+
+	return definitions, nil
+}
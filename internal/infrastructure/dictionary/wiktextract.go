@@ -0,0 +1,197 @@
+package dictionary
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/supercakecrumb/flash-cards-language-tg-bot/internal/domain/models"
+)
+
+// WiktextractService implements the DictionaryService interface with a Wiktextract JSONL
+// extract of Wiktionary, as published on kaikki.org. Only the position of every entry is
+// kept in memory, entries are read from the file when a word is looked up.
+type WiktextractService struct {
+	file            *os.File
+	targetLanguage  string
+	sourceLanguages map[string]bool
+	index           map[string][]entryRef // by language code and lowercased word
+}
+
+// entryRef is the position of a dictionary entry in a file
+type entryRef struct {
+	offset int64
+	size   int
+}
+
+// WiktextractEntry is one line of a Wiktextract extract: a word of one language
+// with one part of speech
+type WiktextractEntry struct {
+	Word     string `json:"word"`
+	Pos      string `json:"pos"`
+	LangCode string `json:"lang_code"`
+	Sounds   []struct {
+		IPA    string `json:"ipa"`
+		MP3URL string `json:"mp3_url"`
+		OggURL string `json:"ogg_url"`
+	} `json:"sounds"`
+	Senses []struct {
+		Glosses  []string `json:"glosses"`
+		Examples []struct {
+			Text string `json:"text"`
+		} `json:"examples"`
+		Synonyms []struct {
+			Word string `json:"word"`
+		} `json:"synonyms"`
+		Antonyms []struct {
+			Word string `json:"word"`
+		} `json:"antonyms"`
+	} `json:"senses"`
+}
+
+// NewWiktextractService indexes a Wiktextract extract whose definitions are written in targetLanguage
+func NewWiktextractService(path, targetLanguage string) (DictionaryService, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &WiktextractService{
+		file:            file,
+		targetLanguage:  targetLanguage,
+		sourceLanguages: make(map[string]bool),
+		index:           make(map[string][]entryRef),
+	}
+
+	if err := s.buildIndex(); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// buildIndex records the position of every entry in the file
+func (s *WiktextractService) buildIndex() error {
+	reader := bufio.NewReaderSize(s.file, 1<<20)
+
+	var offset int64
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			var entry struct {
+				Word     string `json:"word"`
+				LangCode string `json:"lang_code"`
+			}
+			if jsonErr := json.Unmarshal(line, &entry); jsonErr == nil && entry.Word != "" && entry.LangCode != "" {
+				key := indexKey(entry.LangCode, entry.Word)
+				s.index[key] = append(s.index[key], entryRef{offset: offset, size: len(line)})
+				s.sourceLanguages[entry.LangCode] = true
+			}
+			offset += int64(len(line))
+		}
+
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(s.index) == 0 {
+		return ErrInvalidDictionaryFile
+	}
+
+	return nil
+}
+
+// Supports reports whether the extract has words of the source language defined in the target language
+func (s *WiktextractService) Supports(languages Languages) bool {
+	return languages.Target == s.targetLanguage && s.sourceLanguages[languages.Source]
+}
+
+// GetDefinitions retrieves definitions for a word from the extract
+func (s *WiktextractService) GetDefinitions(ctx context.Context, word string, languages Languages) ([]Definition, error) {
+	if !s.Supports(languages) {
+		return nil, models.ErrUnsupportedLanguage
+	}
+
+	// Clean up the word
+	word = strings.TrimSpace(strings.ToLower(word))
+
+	var definitions []Definition
+	for i, ref := range s.index[indexKey(languages.Source, word)] {
+		data := make([]byte, ref.size)
+		if _, err := s.file.ReadAt(data, ref.offset); err != nil {
+			return nil, err
+		}
+
+		var entry WiktextractEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return nil, err
+		}
+
+		// The first transcription and recording are used for every sense, MP3 plays on more devices
+		phonetic, audioURL := "", ""
+		for _, sound := range entry.Sounds {
+			if phonetic == "" {
+				phonetic = sound.IPA
+			}
+			if audioURL == "" {
+				audioURL = sound.MP3URL
+			}
+		}
+		if audioURL == "" {
+			for _, sound := range entry.Sounds {
+				if sound.OggURL != "" {
+					audioURL = sound.OggURL
+					break
+				}
+			}
+		}
+
+		for j, sense := range entry.Senses {
+			// The last gloss is the most specific one, the others belong to parent senses
+			if len(sense.Glosses) == 0 {
+				continue
+			}
+
+			defID := definitionID("wx", languages, word, i, j)
+			definition := Definition{
+				ID:           defID,
+				Text:         sense.Glosses[len(sense.Glosses)-1],
+				PartOfSpeech: entry.Pos,
+				Phonetic:     phonetic,
+				AudioURL:     audioURL,
+			}
+
+			for _, synonym := range sense.Synonyms {
+				definition.Synonyms = appendWords(definition.Synonyms, synonym.Word)
+			}
+			for _, antonym := range sense.Antonyms {
+				definition.Antonyms = appendWords(definition.Antonyms, antonym.Word)
+			}
+
+			for k, example := range sense.Examples {
+				definition.Examples = append(definition.Examples, Example{
+					ID:   fmt.Sprintf("%s_ex%d", defID, k),
+					Text: example.Text,
+				})
+			}
+
+			definitions = append(definitions, definition)
+		}
+	}
+
+	return definitions, nil
+}
+
+// indexKey builds the key words are indexed by, lookups are case-insensitive
+func indexKey(language, word string) string {
+	return language + "\x00" + strings.ToLower(word)
+}
@@ -0,0 +1,157 @@
+package dictionary
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/supercakecrumb/flash-cards-language-tg-bot/internal/domain/models"
+)
+
+// wiktionaryUserAgent identifies the bot, as the Wikimedia API policy requires
+const wiktionaryUserAgent = "flash-cards-language-tg-bot/1.0 (https://github.com/supercakecrumb/flash-cards-language-tg-bot)"
+
+// WiktionaryService implements the DictionaryService interface using the English Wiktionary
+// definition API. It defines words of many languages, always in English.
+type WiktionaryService struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// WiktionaryResponse maps language codes to the entries of a word in that language
+type WiktionaryResponse map[string][]struct {
+	PartOfSpeech string `json:"partOfSpeech"`
+	Language     string `json:"language"`
+	Definitions  []struct {
+		Definition     string   `json:"definition"`
+		Examples       []string `json:"examples"`
+		ParsedExamples []struct {
+			Example string `json:"example"`
+		} `json:"parsedExamples"`
+	} `json:"definitions"`
+}
+
+// NewWiktionaryService creates a new Wiktionary service
+func NewWiktionaryService() DictionaryService {
+	return &WiktionaryService{
+		baseURL: "https://en.wiktionary.org/api/rest_v1/page/definition/",
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// Supports reports whether Wiktionary has definitions for a language pair.
+// Definitions are written in English.
+func (s *WiktionaryService) Supports(languages Languages) bool {
+	return languages.Target == "en" && models.IsValidLanguage(languages.Source)
+}
+
+// GetDefinitions retrieves definitions for a word from Wiktionary
+func (s *WiktionaryService) GetDefinitions(ctx context.Context, word string, languages Languages) ([]Definition, error) {
+	if !s.Supports(languages) {
+		return nil, models.ErrUnsupportedLanguage
+	}
+
+	// Clean up the word
+	word = strings.TrimSpace(word)
+
+	apiResp, err := s.lookup(ctx, word)
+	if err != nil {
+		return nil, err
+	}
+
+	// Titles are case-sensitive and words arrive lowercased, German nouns for example are capitalized
+	if len(apiResp[languages.Source]) == 0 {
+		if capitalized := capitalize(word); capitalized != word {
+			if apiResp, err = s.lookup(ctx, capitalized); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Only the entries of the bank's language are relevant
+	var definitions []Definition
+	for i, entry := range apiResp[languages.Source] {
+		for j, def := range entry.Definitions {
+			text := stripHTML(def.Definition)
+			if text == "" {
+				continue
+			}
+
+			defID := definitionID("wk", languages, word, i, j)
+			definition := Definition{
+				ID:           defID,
+				Text:         text,
+				PartOfSpeech: strings.ToLower(entry.PartOfSpeech),
+			}
+
+			examples := def.Examples
+			for _, parsed := range def.ParsedExamples {
+				examples = append(examples, parsed.Example)
+			}
+
+			seen := make(map[string]bool)
+			for _, example := range examples {
+				example = stripHTML(example)
+				if example == "" || seen[example] {
+					continue
+				}
+				seen[example] = true
+
+				definition.Examples = append(definition.Examples, Example{
+					ID:   fmt.Sprintf("%s_ex%d", defID, len(definition.Examples)),
+					Text: example,
+				})
+			}
+
+			definitions = append(definitions, definition)
+		}
+	}
+
+	return definitions, nil
+}
+
+// lookup fetches the Wiktionary entries of a title, a missing title has no entries
+func (s *WiktionaryService) lookup(ctx context.Context, title string) (WiktionaryResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.baseURL+url.PathEscape(title), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", wiktionaryUserAgent)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusError(resp.StatusCode)
+	}
+
+	var apiResp WiktionaryResponse
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		return nil, err
+	}
+
+	return apiResp, nil
+}
+
+// capitalize upper-cases the first letter of a word
+func capitalize(word string) string {
+	r, size := utf8.DecodeRuneInString(word)
+	if r == utf8.RuneError {
+		return word
+	}
+	return string(unicode.ToUpper(r)) + word[size:]
+}
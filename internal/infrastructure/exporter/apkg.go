@@ -0,0 +1,287 @@
+package exporter
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha1"
+	"database/sql"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"html"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// ankiModelID is the ID of the note type in exported packages. It is fixed so
+// that Anki reuses the note type when several exports are imported.
+const ankiModelID int64 = 1700000000001
+
+// ankiSchema creates the tables of an Anki collection (schema version 11)
+const ankiSchema = `
+CREATE TABLE col (
+	id integer PRIMARY KEY, crt integer NOT NULL, mod integer NOT NULL, scm integer NOT NULL,
+	ver integer NOT NULL, dty integer NOT NULL, usn integer NOT NULL, ls integer NOT NULL,
+	conf text NOT NULL, models text NOT NULL, decks text NOT NULL, dconf text NOT NULL, tags text NOT NULL
+);
+CREATE TABLE notes (
+	id integer PRIMARY KEY, guid text NOT NULL, mid integer NOT NULL, mod integer NOT NULL,
+	usn integer NOT NULL, tags text NOT NULL, flds text NOT NULL, sfld integer NOT NULL,
+	csum integer NOT NULL, flags integer NOT NULL, data text NOT NULL
+);
+CREATE TABLE cards (
+	id integer PRIMARY KEY, nid integer NOT NULL, did integer NOT NULL, ord integer NOT NULL,
+	mod integer NOT NULL, usn integer NOT NULL, type integer NOT NULL, queue integer NOT NULL,
+	due integer NOT NULL, ivl integer NOT NULL, factor integer NOT NULL, reps integer NOT NULL,
+	lapses integer NOT NULL, left integer NOT NULL, odue integer NOT NULL, odid integer NOT NULL,
+	flags integer NOT NULL, data text NOT NULL
+);
+CREATE TABLE revlog (
+	id integer PRIMARY KEY, cid integer NOT NULL, usn integer NOT NULL, ease integer NOT NULL,
+	ivl integer NOT NULL, lastIvl integer NOT NULL, factor integer NOT NULL, time integer NOT NULL,
+	type integer NOT NULL
+);
+CREATE TABLE graves (usn integer NOT NULL, oid integer NOT NULL, type integer NOT NULL);
+CREATE INDEX ix_notes_usn ON notes (usn);
+CREATE INDEX ix_cards_usn ON cards (usn);
+CREATE INDEX ix_revlog_usn ON revlog (usn);
+CREATE INDEX ix_cards_nid ON cards (nid);
+CREATE INDEX ix_cards_sched ON cards (did, queue, due);
+CREATE INDEX ix_revlog_cid ON revlog (cid);
+CREATE INDEX ix_notes_csum ON notes (csum);
+`
+
+// WriteAPKG writes a deck as an Anki package with one new card per flash card.
+// Review state is not exported, Anki schedules the cards from scratch.
+func WriteAPKG(deck *Deck) ([]byte, error) {
+	dst, err := os.CreateTemp("", "anki-*.db")
+	if err != nil {
+		return nil, err
+	}
+	path := dst.Name()
+	dst.Close()
+	defer os.Remove(path)
+
+	if err := writeAnkiCollection(path, deck); err != nil {
+		return nil, err
+	}
+
+	collection, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+
+	w, err := archive.Create("collection.anki2")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(collection); err != nil {
+		return nil, err
+	}
+
+	// No media files are bundled
+	w, err = archive.Create("media")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write([]byte("{}")); err != nil {
+		return nil, err
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// writeAnkiCollection creates an Anki collection database at path
+func writeAnkiCollection(path string, deck *Deck) error {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(ankiSchema); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	deckID := now.UnixMilli()
+
+	conf, models, decks, dconf, err := ankiCollectionConfig(deck, deckID, now)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		`INSERT INTO col VALUES (1, ?, ?, ?, 11, 0, 0, 0, ?, ?, ?, ?, '{}')`,
+		now.Truncate(24*time.Hour).Unix(),
+		now.UnixMilli(),
+		now.UnixMilli(),
+		conf,
+		models,
+		decks,
+		dconf,
+	)
+	if err != nil {
+		return err
+	}
+
+	for i, card := range deck.Cards {
+		// Note and card IDs are creation timestamps in milliseconds and must be unique
+		id := now.UnixMilli() + int64(i)
+
+		_, err = tx.Exec(
+			`INSERT INTO notes VALUES (?, ?, ?, ?, -1, '', ?, ?, ?, 0, '')`,
+			id,
+			ankiGUID(deck.Name, card.Word),
+			ankiModelID,
+			now.Unix(),
+			strings.Join(ankiFields(card), "\x1f"),
+			card.Word,
+			ankiChecksum(card.Word),
+		)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(
+			`INSERT INTO cards VALUES (?, ?, ?, 0, ?, -1, 0, 0, ?, 0, 0, 0, 0, 0, 0, 0, 0, '')`,
+			id,
+			id,
+			deckID,
+			now.Unix(),
+			i+1, // position in the new card queue
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ankiFields returns the note fields of a card: word, definition, examples and image
+func ankiFields(card Card) []string {
+	examples := make([]string, len(card.Examples))
+	for i, example := range card.Examples {
+		examples[i] = html.EscapeString(example)
+	}
+
+	// Telegram files can't be linked without the bot token
+	image := ""
+	if card.Image != "" && !strings.HasPrefix(card.Image, TelegramFilePrefix) {
+		image = `<img src="` + html.EscapeString(card.Image) + `">`
+	}
+
+	return []string{
+		html.EscapeString(card.Word),
+		html.EscapeString(card.Definition),
+		strings.Join(examples, "<br>"),
+		image,
+	}
+}
+
+// ankiCollectionConfig builds the JSON configuration columns of the collection
+func ankiCollectionConfig(deck *Deck, deckID int64, now time.Time) (conf, models, decks, dconf string, err error) {
+	field := func(name string, ord int) map[string]interface{} {
+		return map[string]interface{}{
+			"name": name, "ord": ord, "sticky": false, "rtl": false,
+			"font": "Arial", "size": 20, "media": []string{},
+		}
+	}
+
+	deckEntry := func(id int64, name, description string) map[string]interface{} {
+		return map[string]interface{}{
+			"id": id, "name": name, "desc": description, "mod": now.Unix(), "usn": -1,
+			"collapsed": false, "browserCollapsed": false, "dyn": 0, "conf": 1,
+			"newToday": []int{0, 0}, "revToday": []int{0, 0}, "lrnToday": []int{0, 0}, "timeToday": []int{0, 0},
+			"extendNew": 0, "extendRev": 0,
+		}
+	}
+
+	values := []interface{}{
+		map[string]interface{}{
+			"nextPos": len(deck.Cards) + 1, "estTimes": true, "activeDecks": []int64{deckID},
+			"sortType": "noteFld", "timeLim": 0, "sortBackwards": false, "addToCur": true,
+			"curDeck": deckID, "newSpread": 0, "dueCounts": true, "curModel": ankiModelID,
+			"collapseTime": 1200,
+		},
+		map[string]interface{}{
+			strconv.FormatInt(ankiModelID, 10): map[string]interface{}{
+				"id": ankiModelID, "name": "Flash Cards Bot", "type": 0, "mod": now.Unix(), "usn": -1,
+				"sortf": 0, "did": deckID, "tags": []string{}, "vers": []int{},
+				"flds": []interface{}{field("Word", 0), field("Definition", 1), field("Examples", 2), field("Image", 3)},
+				"tmpls": []interface{}{map[string]interface{}{
+					"name": "Card 1", "ord": 0, "did": nil, "bqfmt": "", "bafmt": "",
+					"qfmt": "{{Word}}",
+					"afmt": "{{FrontSide}}<hr id=answer>{{Definition}}" +
+						"{{#Examples}}<br><br><i>{{Examples}}</i>{{/Examples}}" +
+						"{{#Image}}<br><br>{{Image}}{{/Image}}",
+				}},
+				"req":       []interface{}{[]interface{}{0, "any", []int{0}}},
+				"css":       ".card { font-family: arial; font-size: 20px; text-align: center; }",
+				"latexPre":  "\\documentclass[12pt]{article}\n\\special{papersize=3in,5in}\n\\usepackage{amssymb,amsmath}\n\\pagestyle{empty}\n\\setlength{\\parindent}{0in}\n\\begin{document}\n",
+				"latexPost": "\\end{document}",
+			},
+		},
+		map[string]interface{}{
+			"1":                           deckEntry(1, "Default", ""),
+			strconv.FormatInt(deckID, 10): deckEntry(deckID, deck.Name, deck.Description),
+		},
+		map[string]interface{}{
+			"1": map[string]interface{}{
+				"id": 1, "name": "Default", "mod": 0, "usn": 0, "maxTaken": 60, "autoplay": true,
+				"timer": 0, "replayq": true, "dyn": false,
+				"new": map[string]interface{}{
+					"delays": []int{1, 10}, "ints": []int{1, 4, 0}, "initialFactor": 2500,
+					"order": 1, "perDay": 20, "bury": false,
+				},
+				"rev": map[string]interface{}{
+					"perDay": 200, "ease4": 1.3, "ivlFct": 1, "maxIvl": 36500, "bury": false, "hardFactor": 1.2,
+				},
+				"lapse": map[string]interface{}{
+					"delays": []int{10}, "mult": 0, "minInt": 1, "leechFails": 8, "leechAction": 1,
+				},
+			},
+		},
+	}
+
+	encoded := make([]string, len(values))
+	for i, value := range values {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return "", "", "", "", err
+		}
+		encoded[i] = string(data)
+	}
+
+	return encoded[0], encoded[1], encoded[2], encoded[3], nil
+}
+
+// ankiGUID derives a stable note GUID, so importing a newer export updates the notes
+func ankiGUID(deckName, word string) string {
+	sum := sha1.Sum([]byte(deckName + "\x1f" + word))
+	return base64.RawURLEncoding.EncodeToString(sum[:8])
+}
+
+// ankiChecksum computes the note checksum Anki uses for duplicate detection
+func ankiChecksum(sortField string) int64 {
+	sum := sha1.Sum([]byte(sortField))
+	return int64(binary.BigEndian.Uint32(sum[:4]))
+}
@@ -0,0 +1,47 @@
+package exporter
+
+import (
+	"bytes"
+	"encoding/csv"
+)
+
+// WriteCSV writes a deck as CSV with a header row, one column per example.
+// The header is recognized by the CSV import.
+func WriteCSV(deck *Deck) ([]byte, error) {
+	maxExamples := 0
+	for _, card := range deck.Cards {
+		if len(card.Examples) > maxExamples {
+			maxExamples = len(card.Examples)
+		}
+	}
+
+	header := []string{"word", "definition"}
+	for i := 0; i < maxExamples; i++ {
+		header = append(header, "example")
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	if err := writer.Write(header); err != nil {
+		return nil, err
+	}
+
+	for _, card := range deck.Cards {
+		row := make([]string, len(header))
+		row[0] = card.Word
+		row[1] = card.Definition
+		copy(row[2:], card.Examples)
+
+		if err := writer.Write(row); err != nil {
+			return nil, err
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
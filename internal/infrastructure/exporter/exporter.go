@@ -0,0 +1,97 @@
+package exporter
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Supported export formats
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+	FormatAPKG = "apkg"
+)
+
+// FormatVersion is the version of the JSON deck format written by WriteJSON
+const FormatVersion = 1
+
+// TelegramFilePrefix marks image references to files stored on Telegram.
+// Telegram file links contain the bot token, so only the file path is exported.
+const TelegramFilePrefix = "telegram:"
+
+// ErrUnsupportedFormat is returned for unknown export formats
+var ErrUnsupportedFormat = errors.New("unsupported export format")
+
+// Deck is a card bank in the lossless JSON export format
+type Deck struct {
+	Version     int       `json:"version"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	ExportedAt  time.Time `json:"exported_at"`
+	Cards       []Card    `json:"cards"`
+}
+
+// Card is a flash card in the JSON export format
+type Card struct {
+	Word       string    `json:"word"`
+	Definition string    `json:"definition"`
+	Examples   []string  `json:"examples,omitempty"`
+	Image      string    `json:"image,omitempty"`
+	Template   string    `json:"template,omitempty"` // empty for the bank's template
+	CreatedAt  time.Time `json:"created_at"`
+	// Review is the definition → word review, the only direction before templates existed
+	Review        *Review `json:"review,omitempty"`
+	ForwardReview *Review `json:"forward_review,omitempty"` // the word → definition review
+	ClozeReview   *Review `json:"cloze_review,omitempty"`   // the review of the card's cloze deletion
+}
+
+// Review is the exporting user's review state of a card
+type Review struct {
+	State        string     `json:"state"`
+	Step         int        `json:"step"`
+	EaseFactor   float64    `json:"ease_factor"`
+	Interval     int        `json:"interval"`
+	Repetitions  int        `json:"repetitions"`
+	Stability    float64    `json:"stability,omitempty"`
+	Difficulty   float64    `json:"difficulty,omitempty"`
+	DueDate      time.Time  `json:"due_date"`
+	LastReviewed *time.Time `json:"last_reviewed,omitempty"`
+}
+
+// Write serializes a deck in the given format
+func Write(deck *Deck, format string) ([]byte, error) {
+	switch format {
+	case FormatCSV:
+		return WriteCSV(deck)
+	case FormatJSON:
+		return WriteJSON(deck)
+	case FormatAPKG:
+		return WriteAPKG(deck)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+var (
+	telegramFilePattern = regexp.MustCompile(`^https://api\.telegram\.org/file/bot[^/]+/(.+)$`)
+	fileNamePattern     = regexp.MustCompile(`[^\p{L}\p{N}_-]+`)
+)
+
+// ImageReference converts an image URL to a reference that is safe to export
+func ImageReference(url string) string {
+	if match := telegramFilePattern.FindStringSubmatch(url); match != nil {
+		return TelegramFilePrefix + match[1]
+	}
+	return url
+}
+
+// FileName builds the name of an export file from the deck name
+func FileName(deckName, format string) string {
+	name := strings.Trim(fileNamePattern.ReplaceAllString(deckName, "_"), "_")
+	if name == "" {
+		name = "cards"
+	}
+	return name + "." + format
+}
@@ -0,0 +1,14 @@
+package exporter
+
+import (
+	"encoding/json"
+)
+
+// WriteJSON writes a deck in the lossless JSON format
+func WriteJSON(deck *Deck) ([]byte, error) {
+	deck.Version = FormatVersion
+	if deck.Cards == nil {
+		deck.Cards = []Card{}
+	}
+	return json.MarshalIndent(deck, "", "  ")
+}
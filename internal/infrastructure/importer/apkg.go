@@ -0,0 +1,191 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	_ "modernc.org/sqlite"
+)
+
+const (
+	// Anki stores note fields in a single column separated by this character
+	ankiFieldSeparator = "\x1f"
+
+	// maxCollectionSize limits the extracted collection to guard against zip bombs
+	maxCollectionSize = 256 << 20
+)
+
+// ErrUnsupportedPackage is returned for Anki packages without a readable collection
+var ErrUnsupportedPackage = errors.New("unsupported Anki package, export it with \"Support older Anki versions\" enabled")
+
+// ankiModel represents a note type in the Anki collection
+type ankiModel struct {
+	Name   string `json:"name"`
+	Fields []struct {
+		Name string `json:"name"`
+		Ord  int    `json:"ord"`
+	} `json:"flds"`
+}
+
+// ParseAPKG parses an Anki .apkg package into records.
+// The first field of each note becomes the word, the second the definition,
+// and fields named like examples or sentences become examples.
+func ParseAPKG(data []byte) ([]Record, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	// Prefer the newer schema, both are SQLite databases
+	var collection *zip.File
+	for _, name := range []string{"collection.anki21", "collection.anki2"} {
+		for _, f := range archive.File {
+			if f.Name == name {
+				collection = f
+				break
+			}
+		}
+		if collection != nil {
+			break
+		}
+	}
+	if collection == nil {
+		return nil, ErrUnsupportedPackage
+	}
+
+	// SQLite needs a file on disk
+	path, err := extractToTempFile(collection)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(path)
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	models, err := readAnkiModels(db)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(`SELECT mid, flds FROM notes ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []Record
+	row := 0
+	for rows.Next() {
+		var modelID int64
+		var fields string
+		if err := rows.Scan(&modelID, &fields); err != nil {
+			return nil, err
+		}
+		row++
+
+		records = append(records, noteToRecord(row, strings.Split(fields, ankiFieldSeparator), models[modelID]))
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(records) == 0 {
+		return nil, ErrEmptyFile
+	}
+
+	return records, nil
+}
+
+// readAnkiModels reads the note types of the collection, keyed by model ID
+func readAnkiModels(db *sql.DB) (map[int64]ankiModel, error) {
+	var modelsJSON string
+	if err := db.QueryRow(`SELECT models FROM col`).Scan(&modelsJSON); err != nil {
+		return nil, err
+	}
+
+	var raw map[string]ankiModel
+	if err := json.Unmarshal([]byte(modelsJSON), &raw); err != nil {
+		return nil, err
+	}
+
+	models := make(map[int64]ankiModel, len(raw))
+	for id, model := range raw {
+		modelID, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			continue
+		}
+		models[modelID] = model
+	}
+
+	return models, nil
+}
+
+// noteToRecord converts the fields of a note to a record
+func noteToRecord(row int, fields []string, model ankiModel) Record {
+	record := Record{Row: row}
+
+	if len(fields) > 0 {
+		record.Word = cleanField(fields[0])
+	}
+	if len(fields) > 1 {
+		record.Definition = cleanField(fields[1])
+	}
+
+	for _, f := range model.Fields {
+		if f.Ord < 2 || f.Ord >= len(fields) {
+			continue
+		}
+
+		name := strings.ToLower(f.Name)
+		if !strings.Contains(name, "example") && !strings.Contains(name, "sentence") {
+			continue
+		}
+
+		// Examples separated by line breaks become separate examples
+		for _, example := range strings.Split(cleanField(fields[f.Ord]), "\n") {
+			if example != "" {
+				record.Examples = append(record.Examples, example)
+			}
+		}
+	}
+
+	return record
+}
+
+// extractToTempFile writes a zip entry to a temporary file and returns its path
+func extractToTempFile(f *zip.File) (string, error) {
+	src, err := f.Open()
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	dst, err := os.CreateTemp("", "anki-*.db")
+	if err != nil {
+		return "", err
+	}
+	defer dst.Close()
+
+	n, err := io.Copy(dst, io.LimitReader(src, maxCollectionSize+1))
+	if err != nil || n > maxCollectionSize {
+		os.Remove(dst.Name())
+		if err == nil {
+			err = ErrUnsupportedPackage
+		}
+		return "", err
+	}
+
+	return dst.Name(), nil
+}
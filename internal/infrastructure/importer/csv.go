@@ -0,0 +1,243 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ColumnMapping maps table columns (zero-based) to card fields
+type ColumnMapping struct {
+	Word       int
+	Definition int
+	Examples   []int
+	HasHeader  bool
+}
+
+// Header names recognized when detecting the column mapping
+var (
+	wordHeaders       = []string{"word", "front", "term", "expression"}
+	definitionHeaders = []string{"definition", "back", "meaning", "translation"}
+	exampleHeaders    = []string{"example", "examples", "sentence", "sentences", "context"}
+)
+
+// ParseTable parses a CSV or TSV file into rows of fields
+func ParseTable(data []byte, format string) ([][]string, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, ErrEmptyFile
+	}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = detectDelimiter(data, format)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	// Drop rows without any content
+	var result [][]string
+	for _, row := range rows {
+		if strings.TrimSpace(strings.Join(row, "")) != "" {
+			result = append(result, row)
+		}
+	}
+
+	if len(result) == 0 {
+		return nil, ErrEmptyFile
+	}
+
+	return result, nil
+}
+
+// detectDelimiter picks the field delimiter from the format and the first line
+func detectDelimiter(data []byte, format string) rune {
+	if format == FormatTSV {
+		return '\t'
+	}
+
+	firstLine := string(data)
+	if i := strings.IndexByte(firstLine, '\n'); i >= 0 {
+		firstLine = firstLine[:i]
+	}
+
+	switch {
+	case strings.Contains(firstLine, "\t"):
+		return '\t'
+	case strings.Count(firstLine, ";") > strings.Count(firstLine, ","):
+		return ';'
+	default:
+		return ','
+	}
+}
+
+// DetectColumnMapping guesses the column mapping from a header row,
+// defaulting to word, definition and examples in the remaining columns
+func DetectColumnMapping(rows [][]string) ColumnMapping {
+	mapping := ColumnMapping{Word: 0, Definition: 1}
+	if len(rows) == 0 {
+		return mapping
+	}
+
+	header := rows[0]
+	word, definition := -1, -1
+	var examples []int
+
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		switch {
+		case word < 0 && contains(wordHeaders, name):
+			word = i
+		case definition < 0 && contains(definitionHeaders, name):
+			definition = i
+		case contains(exampleHeaders, name):
+			examples = append(examples, i)
+		}
+	}
+
+	if word >= 0 && definition >= 0 {
+		return ColumnMapping{
+			Word:       word,
+			Definition: definition,
+			Examples:   examples,
+			HasHeader:  true,
+		}
+	}
+
+	// No recognizable header, use the remaining columns as examples
+	for i := 2; i < len(header); i++ {
+		mapping.Examples = append(mapping.Examples, i)
+	}
+
+	return mapping
+}
+
+// ParseColumnMapping parses a mapping such as "word=1 definition=2 examples=3,4 header=yes"
+// with one-based column numbers
+func ParseColumnMapping(text string) (ColumnMapping, error) {
+	mapping := ColumnMapping{Word: -1, Definition: -1}
+
+	for _, part := range strings.Fields(strings.ToLower(text)) {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return ColumnMapping{}, ErrInvalidMapping
+		}
+
+		switch key {
+		case "word", "front":
+			column, err := parseColumn(value)
+			if err != nil {
+				return ColumnMapping{}, err
+			}
+			mapping.Word = column
+		case "definition", "back":
+			column, err := parseColumn(value)
+			if err != nil {
+				return ColumnMapping{}, err
+			}
+			mapping.Definition = column
+		case "examples", "example":
+			for _, v := range strings.Split(value, ",") {
+				if v == "" {
+					continue
+				}
+				column, err := parseColumn(v)
+				if err != nil {
+					return ColumnMapping{}, err
+				}
+				mapping.Examples = append(mapping.Examples, column)
+			}
+		case "header":
+			mapping.HasHeader = value == "yes" || value == "true" || value == "1"
+		default:
+			return ColumnMapping{}, ErrInvalidMapping
+		}
+	}
+
+	if mapping.Word < 0 || mapping.Definition < 0 || mapping.Word == mapping.Definition {
+		return ColumnMapping{}, ErrInvalidMapping
+	}
+
+	return mapping, nil
+}
+
+// parseColumn parses a one-based column number
+func parseColumn(value string) (int, error) {
+	column, err := strconv.Atoi(value)
+	if err != nil || column < 1 {
+		return 0, ErrInvalidMapping
+	}
+	return column - 1, nil
+}
+
+// String formats the mapping in the syntax accepted by ParseColumnMapping
+func (m ColumnMapping) String() string {
+	text := fmt.Sprintf("word=%d definition=%d", m.Word+1, m.Definition+1)
+
+	if len(m.Examples) > 0 {
+		examples := make([]string, len(m.Examples))
+		for i, column := range m.Examples {
+			examples[i] = strconv.Itoa(column + 1)
+		}
+		text += " examples=" + strings.Join(examples, ",")
+	}
+
+	if m.HasHeader {
+		text += " header=yes"
+	} else {
+		text += " header=no"
+	}
+
+	return text
+}
+
+// MapRecords converts table rows to records using the column mapping
+func MapRecords(rows [][]string, mapping ColumnMapping) []Record {
+	var records []Record
+
+	for i, row := range rows {
+		if i == 0 && mapping.HasHeader {
+			continue
+		}
+
+		record := Record{
+			Row:        i + 1,
+			Word:       field(row, mapping.Word),
+			Definition: field(row, mapping.Definition),
+		}
+
+		for _, column := range mapping.Examples {
+			if example := field(row, column); example != "" {
+				record.Examples = append(record.Examples, example)
+			}
+		}
+
+		records = append(records, record)
+	}
+
+	return records
+}
+
+// field returns a cleaned field value, or an empty string if the row is too short
+func field(row []string, column int) string {
+	if column < 0 || column >= len(row) {
+		return ""
+	}
+	return cleanField(row[column])
+}
+
+// contains checks if a string is in a list
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package importer
+
+import (
+	"errors"
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/supercakecrumb/flash-cards-language-tg-bot/internal/infrastructure/exporter"
+)
+
+// Supported import formats
+const (
+	FormatCSV  = "csv"
+	FormatTSV  = "tsv"
+	FormatAPKG = "apkg"
+	FormatJSON = "json"
+)
+
+// Common errors
+var (
+	ErrUnsupportedFormat = errors.New("unsupported import format")
+	ErrEmptyFile         = errors.New("import file is empty")
+	ErrInvalidMapping    = errors.New("invalid column mapping")
+	ErrUnsupportedDeck   = errors.New("unsupported deck format version")
+)
+
+// Record represents a card parsed from an import file
+type Record struct {
+	Row           int // row or note number in the source file, for error reporting
+	Word          string
+	Definition    string
+	Examples      []string
+	ImageURL      string           // image reference, see exporter.ImageReference
+	Template      string           // card template, only in JSON exports
+	Review        *exporter.Review // definition → word review state, only in JSON exports
+	ForwardReview *exporter.Review // word → definition review state, only in JSON exports
+	ClozeReview   *exporter.Review // cloze deletion review state, only in JSON exports
+}
+
+// DetectFormat determines the import format from a file name
+func DetectFormat(fileName string) (string, error) {
+	name := strings.ToLower(fileName)
+
+	switch {
+	case strings.HasSuffix(name, ".csv"), strings.HasSuffix(name, ".txt"):
+		return FormatCSV, nil
+	case strings.HasSuffix(name, ".tsv"):
+		return FormatTSV, nil
+	case strings.HasSuffix(name, ".apkg"):
+		return FormatAPKG, nil
+	case strings.HasSuffix(name, ".json"):
+		return FormatJSON, nil
+	default:
+		return "", ErrUnsupportedFormat
+	}
+}
+
+var (
+	lineBreakPattern = regexp.MustCompile(`(?i)<br\s*/?>|</div>|</p>`)
+	tagPattern       = regexp.MustCompile(`<[^>]*>`)
+	soundPattern     = regexp.MustCompile(`\[sound:[^\]]*\]`)
+	spacePattern     = regexp.MustCompile(`[ \t]+`)
+)
+
+// cleanField converts an HTML field value to plain text
+func cleanField(value string) string {
+	value = soundPattern.ReplaceAllString(value, "")
+	value = lineBreakPattern.ReplaceAllString(value, "\n")
+	value = tagPattern.ReplaceAllString(value, "")
+	value = html.UnescapeString(value)
+	value = strings.ReplaceAll(value, " ", " ")
+
+	// Collapse whitespace within lines and drop empty lines
+	var lines []string
+	for _, line := range strings.Split(value, "\n") {
+		line = strings.TrimSpace(spacePattern.ReplaceAllString(line, " "))
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,40 @@
+package importer
+
+import (
+	"encoding/json"
+
+	"github.com/supercakecrumb/flash-cards-language-tg-bot/internal/infrastructure/exporter"
+)
+
+// ParseJSON parses a deck exported in the JSON format into records
+func ParseJSON(data []byte) ([]Record, error) {
+	var deck exporter.Deck
+	if err := json.Unmarshal(data, &deck); err != nil {
+		return nil, err
+	}
+
+	if deck.Version < 1 || deck.Version > exporter.FormatVersion {
+		return nil, ErrUnsupportedDeck
+	}
+
+	if len(deck.Cards) == 0 {
+		return nil, ErrEmptyFile
+	}
+
+	records := make([]Record, len(deck.Cards))
+	for i, card := range deck.Cards {
+		records[i] = Record{
+			Row:           i + 1,
+			Word:          card.Word,
+			Definition:    card.Definition,
+			Examples:      card.Examples,
+			ImageURL:      card.Image,
+			Template:      card.Template,
+			Review:        card.Review,
+			ForwardReview: card.ForwardReview,
+			ClozeReview:   card.ClozeReview,
+		}
+	}
+
+	return records, nil
+}
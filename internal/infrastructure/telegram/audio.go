@@ -0,0 +1,129 @@
+package telegram
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/supercakecrumb/flash-cards-language-tg-bot/internal/domain/models"
+)
+
+// maxAudioSize limits the size of a downloaded pronunciation recording
+const maxAudioSize = 10 << 20
+
+// sendPronunciation sends the pronunciation recording of a card. A recording that hasn't been
+// uploaded yet is downloaded and uploaded to Telegram, and the card keeps the uploaded file,
+// so later playback doesn't depend on the dictionary's URL.
+func (b *Bot) sendPronunciation(chatID int64, card *models.FlashCard) {
+	if card.AudioURL == "" && card.AudioFileID == "" {
+		return
+	}
+
+	var file tgbotapi.RequestFileData
+	if card.AudioFileID != "" {
+		file = tgbotapi.FileID(card.AudioFileID)
+	} else {
+		data, err := downloadAudio(card.AudioURL)
+		if err != nil {
+			b.logger.Warn("Failed to download pronunciation",
+				"error", err,
+				"card_id", card.ID,
+				"url", card.AudioURL,
+			)
+			return
+		}
+		file = tgbotapi.FileBytes{
+			Name:  card.Word + audioExtension(card.AudioURL),
+			Bytes: data,
+		}
+	}
+
+	// Ogg recordings play as voice messages, anything else as audio
+	var chattable tgbotapi.Chattable
+	if isVoiceRecording(card.AudioURL) {
+		voice := tgbotapi.NewVoice(chatID, file)
+		voice.Caption = "🔊 " + card.Word
+		chattable = voice
+	} else {
+		audio := tgbotapi.NewAudio(chatID, file)
+		audio.Caption = "🔊 " + card.Word
+		audio.Title = card.Word
+		chattable = audio
+	}
+
+	sent, err := b.api.Send(chattable)
+	if err != nil {
+		b.logger.Warn("Failed to send pronunciation",
+			"error", err,
+			"card_id", card.ID,
+		)
+		return
+	}
+
+	if card.AudioFileID != "" {
+		return
+	}
+
+	switch {
+	case sent.Voice != nil:
+		card.AudioFileID = sent.Voice.FileID
+	case sent.Audio != nil:
+		card.AudioFileID = sent.Audio.FileID
+	default:
+		return
+	}
+
+	if err := b.flashcardService.UpdateFlashCard(card); err != nil {
+		b.logger.Warn("Failed to store pronunciation",
+			"error", err,
+			"card_id", card.ID,
+		)
+	}
+}
+
+// audioExtension returns the file extension of a recording URL, ignoring its query
+func audioExtension(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(path.Ext(u.Path))
+}
+
+// isVoiceRecording reports whether a recording is in a format Telegram plays as a voice message
+func isVoiceRecording(rawURL string) bool {
+	switch audioExtension(rawURL) {
+	case ".ogg", ".oga", ".opus":
+		return true
+	}
+	return false
+}
+
+// downloadAudio downloads a pronunciation recording
+func downloadAudio(rawURL string) ([]byte, error) {
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Get(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxAudioSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxAudioSize {
+		return nil, fmt.Errorf("audio is larger than %d bytes", maxAudioSize)
+	}
+
+	return data, nil
+}
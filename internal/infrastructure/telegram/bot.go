@@ -2,6 +2,7 @@ package telegram
 
 import (
 	"context"
+	"sync"
 
 	"log/slog"
 
@@ -21,27 +22,45 @@ type Bot struct {
 	statsService     services.StatisticsService
 	settingsService  services.SettingsService
 	adminService     services.AdminService
+	importService    services.ImportService
+	exportService    services.ExportService
 
 	// State management for multi-step operations
-	userStates map[int64]UserState
+	states StateStore
+
+	// Update handling, serialized per user
+	webhook    *WebhookConfig // nil when polling for updates
+	dispatcher *dispatcher
+	stop       chan struct{}
+	stopOnce   sync.Once
 }
 
 // UserState represents the current state of a user's interaction with the bot
 type UserState struct {
-	State         string
-	CurrentWord   string
-	CurrentBank   int
-	SelectedDef   string
-	Examples      []string
-	PhotoURL      string
-	ReviewState   *ReviewState
-	SettingsField string
+	State           string
+	CurrentWord     string
+	CurrentBank     int
+	SelectedDef     string
+	Definition      *services.Definition // selected definition with its examples
+	Examples        []string
+	IncludeSynonyms bool // keep the synonyms of the selected definition on the card
+	IncludeAntonyms bool // keep the antonyms of the selected definition on the card
+	PhotoURL        string
+	ReviewState     *ReviewState
+	SettingsField   string
+	EditCardID      int    // card whose field is being edited
+	EditField       string // word, definition, examples or image
+	ManualBack      string // back of a card being written with /manual
+	Import          *ImportState
 	// Other state fields as needed
 }
 
 // NewBot creates a new Telegram bot
 func NewBot(
 	token string,
+	workers int,
+	queueSize int,
+	webhook *WebhookConfig,
 	logger *slog.Logger,
 	userService services.UserService,
 	flashcardService services.FlashCardService,
@@ -50,13 +69,16 @@ func NewBot(
 	statsService services.StatisticsService,
 	settingsService services.SettingsService,
 	adminService services.AdminService,
+	importService services.ImportService,
+	exportService services.ExportService,
+	states StateStore,
 ) (*Bot, error) {
 	api, err := tgbotapi.NewBotAPI(token)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Bot{
+	bot := &Bot{
 		api:              api,
 		logger:           logger,
 		userService:      userService,
@@ -66,14 +88,61 @@ func NewBot(
 		statsService:     statsService,
 		settingsService:  settingsService,
 		adminService:     adminService,
-		userStates:       make(map[int64]UserState),
-	}, nil
+		importService:    importService,
+		exportService:    exportService,
+		states:           states,
+		webhook:          webhook,
+		stop:             make(chan struct{}),
+	}
+	bot.dispatcher = newDispatcher(workers, queueSize, bot.handleUpdate, logger)
+
+	return bot, nil
 }
 
-// Start starts the Telegram bot
+// Start starts the Telegram bot, receiving updates through the webhook
+// if one is configured and by long polling otherwise
 func (b *Bot) Start(ctx context.Context) error {
 	b.logger.Info("Starting Telegram bot")
 
+	// Stop when the context is done or Shutdown is called
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-b.stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	// Remove conversation states of users who walked away
+	go b.cleanupStates(ctx)
+
+	b.dispatcher.start()
+
+	// Let the workers finish the updates already queued once receiving stops
+	defer b.dispatcher.close()
+
+	if b.webhook != nil {
+		return b.serveWebhook(ctx)
+	}
+	return b.pollUpdates(ctx)
+}
+
+// pollUpdates receives updates by long polling until ctx is done
+func (b *Bot) pollUpdates(ctx context.Context) error {
+	// Telegram refuses getUpdates while a webhook is set
+	info, err := b.api.GetWebhookInfo()
+	if err != nil {
+		return err
+	}
+	if info.IsSet() {
+		b.logger.Warn("Removing webhook to poll for updates", "url", info.URL)
+		if _, err := b.api.Request(tgbotapi.DeleteWebhookConfig{}); err != nil {
+			return err
+		}
+	}
+
 	u := tgbotapi.NewUpdate(0)
 	u.Timeout = 60
 
@@ -82,14 +151,25 @@ func (b *Bot) Start(ctx context.Context) error {
 	for {
 		select {
 		case update := <-updates:
-			go b.handleUpdate(update)
+			if err := b.dispatcher.dispatch(ctx, update); err != nil {
+				b.logger.Warn("Dropped update while stopping", "update_id", update.UpdateID)
+			}
 		case <-ctx.Done():
 			b.logger.Info("Stopping Telegram bot")
+			b.api.StopReceivingUpdates()
 			return nil
 		}
 	}
 }
 
+// Shutdown stops receiving updates and waits for the queued updates to be handled
+func (b *Bot) Shutdown(ctx context.Context) error {
+	b.stopOnce.Do(func() {
+		close(b.stop)
+	})
+	return b.dispatcher.wait(ctx)
+}
+
 // handleUpdate handles a Telegram update
 func (b *Bot) handleUpdate(update tgbotapi.Update) {
 	// Log incoming update
@@ -107,6 +187,8 @@ func (b *Bot) handleUpdate(update tgbotapi.Update) {
 		b.handleCallback(update)
 	case update.Message != nil && update.Message.Photo != nil:
 		b.handlePhoto(update)
+	case update.Message != nil && update.Message.Document != nil:
+		b.handleDocument(update)
 	case update.Message != nil:
 		b.handleMessage(update)
 	}
@@ -0,0 +1,344 @@
+package telegram
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/supercakecrumb/flash-cards-language-tg-bot/internal/domain/models"
+)
+
+const (
+	// cardsPageSize is the number of cards on a page of /cards
+	cardsPageSize = 10
+
+	// maxCardSearchLength limits search text, which is kept in the callback data of the page buttons
+	maxCardSearchLength = 32
+
+	// cardFilterAll stands for no filter in callback data
+	cardFilterAll = "all"
+)
+
+// cardFilters are the filters offered by /cards, in button order
+var cardFilters = []string{
+	cardFilterAll,
+	models.CardFilterNew,
+	models.CardFilterLearning,
+	models.CardFilterDue,
+	models.CardFilterMature,
+}
+
+func (b *Bot) handleCardsCommand(update tgbotapi.Update, user *models.User, args string) {
+	chatID := update.Message.Chat.ID
+
+	// A filter may come first, everything else is searched for, e.g. "/cards due run"
+	filter, text := "", strings.TrimSpace(args)
+	if fields := strings.Fields(args); len(fields) > 0 {
+		first := strings.ToLower(fields[0])
+		if first == cardFilterAll || models.IsValidCardFilter(first) {
+			filter = first
+			text = strings.Join(fields[1:], " ")
+		}
+	}
+	if filter == cardFilterAll {
+		filter = ""
+	}
+
+	b.showCardList(chatID, 0, user, filter, truncateSearch(text), 1)
+}
+
+func (b *Bot) handlePaginationCallback(update tgbotapi.Update, user *models.User, args []string) {
+	chatID := update.CallbackQuery.Message.Chat.ID
+	messageID := update.CallbackQuery.Message.MessageID
+
+	if len(args) < 1 {
+		b.logger.Error("Invalid pagination callback data", "args", args)
+		return
+	}
+
+	switch args[0] {
+	case "cards":
+		// page:cards:<page>:<filter>:<search text, which may contain colons>
+		if len(args) < 3 {
+			b.logger.Error("Invalid cards pagination data", "args", args)
+			return
+		}
+
+		page, err := strconv.Atoi(args[1])
+		if err != nil || page < 1 {
+			b.logger.Error("Invalid page in pagination callback", "args", args)
+			return
+		}
+
+		filter := args[2]
+		if filter == cardFilterAll {
+			filter = ""
+		}
+
+		b.showCardList(chatID, messageID, user, filter, strings.Join(args[3:], ":"), page)
+
+	case "times":
+		// page:times:<page>
+		if len(args) < 2 {
+			b.logger.Error("Invalid answer times pagination data", "args", args)
+			return
+		}
+
+		page, err := strconv.Atoi(args[1])
+		if err != nil || page < 1 {
+			b.logger.Error("Invalid page in pagination callback", "args", args)
+			return
+		}
+
+		b.showLatencyList(chatID, messageID, user, page)
+
+	default:
+		b.logger.Warn("Unknown pagination type", "type", args[0])
+	}
+}
+
+// showCardList shows a page of the cards in the user's active bank that match a search,
+// replacing the message with the given ID when paging through an earlier list
+func (b *Bot) showCardList(chatID int64, messageID int, user *models.User, filter, text string, page int) {
+	// Get user's active card bank
+	settings, err := b.settingsService.GetUserSettings(user.ID)
+	if err != nil {
+		b.logger.Error("Failed to get user settings",
+			"error", err,
+			"user_id", user.ID,
+		)
+		b.sendErrorMessage(chatID, "Failed to get your settings. Please try again.")
+		return
+	}
+
+	activeBankID := settings.Settings.ActiveCardBankID
+
+	// Check if user has access to this bank
+	hasAccess, err := b.cardbankService.UserHasAccess(user.ID, activeBankID)
+	if err != nil || !hasAccess {
+		b.logger.Error("User doesn't have access to active bank",
+			"error", err,
+			"user_id", user.ID,
+			"bank_id", activeBankID,
+		)
+		b.sendErrorMessage(chatID, "You don't have access to your active card bank. Please select another bank using /banks.")
+		return
+	}
+
+	bankName := "your active bank"
+	if bank, err := b.cardbankService.GetCardBank(activeBankID); err == nil {
+		bankName = fmt.Sprintf("\"%s\"", html.EscapeString(bank.Name))
+	}
+
+	cards, total, err := b.flashcardService.SearchFlashCards(models.CardSearch{
+		BankID: activeBankID,
+		UserID: user.ID,
+		Text:   text,
+		Filter: filter,
+		Limit:  cardsPageSize,
+		Offset: (page - 1) * cardsPageSize,
+	})
+	if err != nil {
+		b.logger.Error("Failed to search flash cards",
+			"error", err,
+			"user_id", user.ID,
+			"bank_id", activeBankID,
+			"text", text,
+			"filter", filter,
+		)
+		b.sendErrorMessage(chatID, "Failed to get your cards. Please try again.")
+		return
+	}
+
+	totalPages := (total + cardsPageSize - 1) / cardsPageSize
+
+	listText := fmt.Sprintf("📇 *Cards in %s*\n", bankName)
+	if filter != "" {
+		listText += fmt.Sprintf("Filter: %s\n", filter)
+	}
+	if text != "" {
+		listText += fmt.Sprintf("Search: \"%s\"\n", html.EscapeString(text))
+	}
+
+	switch {
+	case total == 0 && (filter != "" || text != ""):
+		listText += "\nNo cards match. Try another filter or search."
+	case total == 0:
+		listText += "\nThis bank has no cards yet. Send a word to create one."
+	case len(cards) == 0:
+		listText += fmt.Sprintf("\nThere are only %d pages.", totalPages)
+	default:
+		first := (page-1)*cardsPageSize + 1
+		listText += fmt.Sprintf("\nShowing %d–%d of %d:\n", first, first+len(cards)-1, total)
+		for i, card := range cards {
+			listText += fmt.Sprintf("\n%d. *%s* – %s", first+i, html.EscapeString(card.Word), html.EscapeString(truncateText(card.Definition, 60)))
+		}
+		listText += "\n\nChoose a card to see its details. Use /cards [filter] [text] to search."
+	}
+
+	keyboard := b.createCardListKeyboard(cards, filter, text, page, totalPages)
+
+	if messageID != 0 {
+		edit := tgbotapi.NewEditMessageTextAndMarkup(chatID, messageID, listText, keyboard)
+		edit.ParseMode = "HTML"
+		if _, err := b.api.Send(edit); err != nil {
+			b.logger.Warn("Failed to update card list",
+				"error", err,
+				"user_id", user.ID,
+			)
+		}
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chatID, listText)
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = keyboard
+
+	b.api.Send(msg)
+}
+
+// showCardDetails shows everything on a card with the user's review progress
+func (b *Bot) showCardDetails(chatID int64, user *models.User, card *models.FlashCard) {
+	hasAccess, err := b.cardbankService.UserHasAccess(user.ID, card.CardBankID)
+	if err != nil || !hasAccess {
+		b.sendErrorMessage(chatID, "You don't have access to this card.")
+		return
+	}
+
+	text := fmt.Sprintf("📝 *%s*", card.Word)
+	if card.Phonetic != "" {
+		text += " " + card.Phonetic
+	}
+	text += "\n\n*Definition:*\n" + card.Definition
+
+	if len(card.Examples) > 0 {
+		text += "\n\n*Examples:*"
+		for i, ex := range card.Examples {
+			text += fmt.Sprintf("\n%d. %s", i+1, ex)
+		}
+	}
+
+	text += relatedWordsText(*card)
+
+	template := b.cardTemplate(card)
+	text += "\n\n*Template:* " + templateLabel(template)
+
+	reviews, err := b.spacedRepService.GetReviews(user.ID, card.ID)
+	if err != nil {
+		b.logger.Warn("Failed to get reviews",
+			"error", err,
+			"user_id", user.ID,
+			"card_id", card.ID,
+		)
+	} else {
+		// Every direction has its own progress
+		directions := models.TemplateDirections(template)
+		if b.hasClozeCard(card) {
+			directions = append(directions, models.DirectionCloze)
+		}
+		for _, direction := range directions {
+			label := "Progress"
+			if len(directions) > 1 {
+				label = directionLabel(direction)
+			}
+
+			var review *models.Review
+			for i := range reviews {
+				if reviews[i].Direction == direction {
+					review = &reviews[i]
+				}
+			}
+			text += "\n\n" + reviewProgressText(label, review)
+		}
+	}
+
+	history, err := b.spacedRepService.GetReviewHistory(user.ID, card.ID)
+	if err != nil {
+		b.logger.Warn("Failed to get review history",
+			"error", err,
+			"user_id", user.ID,
+			"card_id", card.ID,
+		)
+	} else if average := models.AverageLatencyMs(history); average > 0 {
+		text += "\n\n*Average answer time:* " + formatLatency(average)
+	}
+
+	canEdit, err := b.cardbankService.UserCanEdit(user.ID, card.CardBankID)
+	if err != nil {
+		b.logger.Warn("Failed to check bank permissions",
+			"error", err,
+			"user_id", user.ID,
+			"bank_id", card.CardBankID,
+		)
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "HTML"
+	if canEdit {
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("✏️ Edit", fmt.Sprintf("card:edit:%d", card.ID)),
+				tgbotapi.NewInlineKeyboardButtonData("🗑 Delete", fmt.Sprintf("card:delete:%d", card.ID)),
+			),
+		)
+	}
+
+	b.api.Send(msg)
+
+	if card.ImageURL != "" {
+		photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileURL(card.ImageURL))
+		photo.Caption = "Context image for: " + card.Word
+		b.api.Send(photo)
+	}
+
+	b.sendPronunciation(chatID, card)
+}
+
+// reviewProgressText describes the state of a review, nil if there is none yet
+func reviewProgressText(label string, review *models.Review) string {
+	var text string
+	switch {
+	case review == nil, review.State == models.ReviewStateNew:
+		return fmt.Sprintf("*%s:* not reviewed yet", label)
+	case review.State == models.ReviewStateLearning:
+		text = fmt.Sprintf("*%s:* learning", label)
+	case review.State == models.ReviewStateRelearning:
+		text = fmt.Sprintf("*%s:* relearning", label)
+	default:
+		text = fmt.Sprintf("*%s:* every %d days", label, review.Interval)
+		if review.Interval >= models.MatureInterval {
+			text += " (mature)"
+		}
+	}
+
+	text += fmt.Sprintf("\nReviewed %d times", review.Repetitions)
+	if review.DueDate.After(time.Now()) {
+		text += fmt.Sprintf(", due %s", review.DueDate.Format("Jan 2, 2006 15:04"))
+	} else {
+		text += ", due now"
+	}
+
+	return text
+}
+
+// truncateSearch shortens search text to fit into callback data, keeping whole characters
+func truncateSearch(text string) string {
+	for len(text) > maxCardSearchLength {
+		_, size := utf8.DecodeLastRuneInString(text)
+		text = text[:len(text)-size]
+	}
+	return strings.TrimSpace(text)
+}
+
+// truncateText shortens text to at most limit characters, marking the cut with an ellipsis
+func truncateText(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit-1]) + "…"
+}
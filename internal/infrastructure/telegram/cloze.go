@@ -0,0 +1,134 @@
+package telegram
+
+import (
+	"fmt"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/supercakecrumb/flash-cards-language-tg-bot/internal/domain/models"
+	"github.com/supercakecrumb/flash-cards-language-tg-bot/pkg/cloze"
+)
+
+func (b *Bot) handleClozeCommand(update tgbotapi.Update, user *models.User, args string) {
+	chatID := update.Message.Chat.ID
+
+	// Get user's active card bank
+	settings, err := b.settingsService.GetUserSettings(user.ID)
+	if err != nil {
+		b.logger.Error("Failed to get user settings",
+			"error", err,
+			"user_id", user.ID,
+		)
+		b.sendErrorMessage(chatID, "Failed to get your settings. Please try again.")
+		return
+	}
+
+	activeBankID := settings.Settings.ActiveCardBankID
+
+	// Check if user has access to this bank
+	hasAccess, err := b.cardbankService.UserHasAccess(user.ID, activeBankID)
+	if err != nil || !hasAccess {
+		b.logger.Error("User doesn't have access to active bank",
+			"error", err,
+			"user_id", user.ID,
+			"bank_id", activeBankID,
+		)
+		b.sendErrorMessage(chatID, "You don't have access to your active card bank. Please select another bank using /banks.")
+		return
+	}
+
+	bank, err := b.cardbankService.GetCardBank(activeBankID)
+	if err != nil {
+		b.logger.Error("Failed to get bank",
+			"error", err,
+			"bank_id", activeBankID,
+		)
+		b.sendErrorMessage(chatID, "Failed to get your card bank. Please try again.")
+		return
+	}
+
+	// Without arguments show whether the bank uses cloze cards
+	var enabled bool
+	switch strings.ToLower(strings.TrimSpace(args)) {
+	case "":
+		status := "doesn't use cloze cards"
+		if bank.ClozeCards {
+			status = "uses cloze cards"
+		}
+		b.sendMessage(chatID, fmt.Sprintf("\"%s\" %s.\n\n"+
+			"Cloze cards show an example of a card with the word blanked out and ask you to fill the gap. "+
+			"They are scheduled apart from the card's other directions and are only made for cards with an example that contains the word.\n\n"+
+			"Send /cloze on or /cloze off to change this.",
+			bank.Name, status))
+		return
+	case "on":
+		enabled = true
+	case "off":
+		enabled = false
+	default:
+		b.sendErrorMessage(chatID, "Please choose on or off, e.g. /cloze on")
+		return
+	}
+
+	// Viewers can't change the bank
+	if !b.checkCanEdit(chatID, user, activeBankID) {
+		return
+	}
+
+	bank, err = b.cardbankService.SetClozeCards(bank.ID, enabled)
+	if err != nil {
+		b.logger.Error("Failed to set bank cloze cards",
+			"error", err,
+			"bank_id", activeBankID,
+			"enabled", enabled,
+		)
+		b.sendErrorMessage(chatID, "Failed to change the cloze cards of your card bank. Please try again.")
+		return
+	}
+
+	if bank.ClozeCards {
+		b.sendMessage(chatID, fmt.Sprintf("Cards in \"%s\" with examples now also come as cloze cards. They are introduced like new cards.", bank.Name))
+	} else {
+		b.sendMessage(chatID, fmt.Sprintf("\"%s\" no longer uses cloze cards. Their progress is kept for when you turn them on again.", bank.Name))
+	}
+}
+
+// clozeText shows the example of a cloze card with the word blanked out
+func clozeText(card models.FlashCard) string {
+	c, ok := cloze.Pick(card.Examples, card.Word)
+	if !ok {
+		// Examples were changed during the session, fall back to the definition
+		return fmt.Sprintf("*Definition:*\n%s", card.Definition)
+	}
+	return fmt.Sprintf("🧩 *Fill the gap:*\n%s", c.Text)
+}
+
+// clozeAnswer returns the word a card asks for, as it is written in the example of a cloze card
+func clozeAnswer(card models.ReviewCard) string {
+	if reviewDirection(card) != models.DirectionCloze {
+		return card.Word
+	}
+	if c, ok := cloze.Pick(card.Examples, card.Word); ok {
+		return c.Answer
+	}
+	return card.Word
+}
+
+// hasClozeCard checks if a card is also reviewed as a cloze card
+func (b *Bot) hasClozeCard(card *models.FlashCard) bool {
+	if _, ok := cloze.Pick(card.Examples, card.Word); !ok {
+		return false
+	}
+
+	bank, err := b.cardbankService.GetCardBank(card.CardBankID)
+	if err != nil {
+		b.logger.Warn("Failed to get bank of card",
+			"error", err,
+			"card_id", card.ID,
+			"bank_id", card.CardBankID,
+		)
+		return false
+	}
+
+	return bank.ClozeCards
+}
@@ -0,0 +1,176 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Default dispatcher limits
+const (
+	DefaultWorkers   = 16
+	DefaultQueueSize = 256
+)
+
+// errDispatcherClosed is returned when an update arrives after the dispatcher was closed
+var errDispatcherClosed = errors.New("dispatcher is closed")
+
+// dispatcher hands updates to a bounded pool of workers. Updates from the same
+// user are handled one at a time in the order they arrived, updates from
+// different users are handled concurrently.
+type dispatcher struct {
+	handle  func(tgbotapi.Update)
+	logger  *slog.Logger
+	workers int
+
+	// slots bounds the number of queued and running updates, Dispatch blocks when it is full
+	slots chan struct{}
+
+	// work receives the keys of users with pending updates and no worker handling them
+	work chan int64
+
+	mu      sync.Mutex
+	pending map[int64][]tgbotapi.Update // a key is present while its updates are being handled
+	closed  bool
+
+	wg sync.WaitGroup
+}
+
+// newDispatcher creates a dispatcher with the given number of workers and queue size
+func newDispatcher(workers, queueSize int, handle func(tgbotapi.Update), logger *slog.Logger) *dispatcher {
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+	if queueSize <= 0 {
+		queueSize = DefaultQueueSize
+	}
+
+	return &dispatcher{
+		handle:  handle,
+		logger:  logger,
+		workers: workers,
+		slots:   make(chan struct{}, queueSize),
+		// Every key in work holds at least one slot, so sends never block
+		work:    make(chan int64, queueSize),
+		pending: make(map[int64][]tgbotapi.Update),
+	}
+}
+
+// start starts the workers
+func (d *dispatcher) start() {
+	for i := 0; i < d.workers; i++ {
+		d.wg.Add(1)
+		go d.worker()
+	}
+}
+
+// dispatch queues an update, blocking while the queue is full
+func (d *dispatcher) dispatch(ctx context.Context, update tgbotapi.Update) error {
+	select {
+	case d.slots <- struct{}{}:
+	default:
+		d.logger.Warn("Update queue is full, waiting for workers", "update_id", update.UpdateID)
+		select {
+		case d.slots <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	key := updateKey(update)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.closed {
+		<-d.slots
+		return errDispatcherClosed
+	}
+
+	queue, active := d.pending[key]
+	d.pending[key] = append(queue, update)
+
+	// A worker is already handling this user and will pick the update up
+	if !active {
+		d.work <- key
+	}
+
+	return nil
+}
+
+// close stops accepting work, workers exit once the queued updates are handled
+func (d *dispatcher) close() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !d.closed {
+		d.closed = true
+		close(d.work)
+	}
+}
+
+// wait waits for the workers to finish after close, or for ctx to be done
+func (d *dispatcher) wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// worker handles the pending updates of one user at a time
+func (d *dispatcher) worker() {
+	defer d.wg.Done()
+
+	for key := range d.work {
+		for {
+			d.mu.Lock()
+			queue := d.pending[key]
+			if len(queue) == 0 {
+				delete(d.pending, key)
+				d.mu.Unlock()
+				break
+			}
+			update := queue[0]
+			d.pending[key] = queue[1:]
+			d.mu.Unlock()
+
+			d.safeHandle(update)
+			<-d.slots
+		}
+	}
+}
+
+// safeHandle handles an update, recovering from panics so one bad update doesn't stop the bot
+func (d *dispatcher) safeHandle(update tgbotapi.Update) {
+	defer func() {
+		if r := recover(); r != nil {
+			d.logger.Error("Panic while handling update",
+				"panic", r,
+				"update_id", update.UpdateID,
+				"stack", string(debug.Stack()),
+			)
+		}
+	}()
+
+	d.handle(update)
+}
+
+// updateKey returns the key updates are serialized by: the user, or the chat for updates without one
+func updateKey(update tgbotapi.Update) int64 {
+	if userID := getUserID(update); userID != 0 {
+		return userID
+	}
+	return getChatID(update)
+}
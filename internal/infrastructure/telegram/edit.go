@@ -0,0 +1,476 @@
+package telegram
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/supercakecrumb/flash-cards-language-tg-bot/internal/domain/models"
+	"github.com/supercakecrumb/flash-cards-language-tg-bot/internal/repository"
+)
+
+// clearValue is the input that removes the examples or the image of a card
+const clearValue = "-"
+
+func (b *Bot) handleEditCommand(update tgbotapi.Update, user *models.User, args string) {
+	chatID := update.Message.Chat.ID
+
+	card, ok := b.findCardForCommand(chatID, user, args, "edit")
+	if !ok {
+		return
+	}
+
+	b.showCardEditor(chatID, card)
+}
+
+func (b *Bot) handleDeleteCommand(update tgbotapi.Update, user *models.User, args string) {
+	chatID := update.Message.Chat.ID
+
+	card, ok := b.findCardForCommand(chatID, user, args, "delete")
+	if !ok {
+		return
+	}
+
+	b.confirmCardDeletion(chatID, card)
+}
+
+// findCardForCommand finds the card named in the arguments of /edit or /delete in the active bank
+func (b *Bot) findCardForCommand(chatID int64, user *models.User, args, command string) (*models.FlashCard, bool) {
+	word := strings.TrimSpace(strings.ToLower(args))
+	if word == "" {
+		b.sendMessage(chatID, fmt.Sprintf("Please provide the word of the card: /%s word", command))
+		return nil, false
+	}
+
+	// Get user's active card bank
+	settings, err := b.settingsService.GetUserSettings(user.ID)
+	if err != nil {
+		b.logger.Error("Failed to get user settings",
+			"error", err,
+			"user_id", user.ID,
+		)
+		b.sendErrorMessage(chatID, "Failed to get your settings. Please try again.")
+		return nil, false
+	}
+
+	activeBankID := settings.Settings.ActiveCardBankID
+	if !b.checkCanEdit(chatID, user, activeBankID) {
+		return nil, false
+	}
+
+	card, err := b.flashcardService.GetFlashCardByWord(word, activeBankID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			b.sendErrorMessage(chatID, fmt.Sprintf("There is no card for \"%s\" in your active bank.", word))
+			return nil, false
+		}
+		b.logger.Error("Failed to get flash card",
+			"error", err,
+			"word", word,
+			"bank_id", activeBankID,
+		)
+		b.sendErrorMessage(chatID, "Failed to get the card. Please try again.")
+		return nil, false
+	}
+
+	return card, true
+}
+
+// checkCanEdit checks if a user may change the cards of a bank, telling them why not otherwise
+func (b *Bot) checkCanEdit(chatID int64, user *models.User, bankID int) bool {
+	canEdit, err := b.cardbankService.UserCanEdit(user.ID, bankID)
+	if err != nil {
+		b.logger.Error("Failed to check bank permissions",
+			"error", err,
+			"user_id", user.ID,
+			"bank_id", bankID,
+		)
+		b.sendErrorMessage(chatID, "Failed to check your permissions. Please try again.")
+		return false
+	}
+
+	if !canEdit {
+		hasAccess, err := b.cardbankService.UserHasAccess(user.ID, bankID)
+		if err != nil || !hasAccess {
+			b.sendErrorMessage(chatID, "You don't have access to your active card bank. Please select another bank using /banks.")
+			return false
+		}
+		b.sendErrorMessage(chatID, "You can only review the cards of this bank. Ask its owner to make you an editor to change them.")
+		return false
+	}
+
+	return true
+}
+
+func (b *Bot) handleCardCallback(update tgbotapi.Update, user *models.User, args []string) {
+	chatID := update.CallbackQuery.Message.Chat.ID
+
+	if len(args) < 2 {
+		b.logger.Error("Invalid card callback data", "args", args)
+		return
+	}
+
+	action := args[0]
+	cardID, err := strconv.Atoi(args[1])
+	if err != nil {
+		b.logger.Error("Invalid card ID in callback", "args", args)
+		return
+	}
+
+	card, err := b.flashcardService.GetFlashCard(cardID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			b.sendErrorMessage(chatID, "This card no longer exists.")
+			return
+		}
+		b.logger.Error("Failed to get flash card",
+			"error", err,
+			"card_id", cardID,
+		)
+		b.sendErrorMessage(chatID, "Failed to get the card. Please try again.")
+		return
+	}
+
+	// Viewers may look at cards but not change them
+	if action == "view" {
+		b.showCardDetails(chatID, user, card)
+		return
+	}
+
+	if !b.checkCanEdit(chatID, user, card.CardBankID) {
+		return
+	}
+
+	switch action {
+	case "edit":
+		b.showCardEditor(chatID, card)
+
+	case "field":
+		if len(args) < 3 {
+			b.logger.Error("Invalid card field callback data", "args", args)
+			return
+		}
+
+		prompt, ok := cardFieldPrompt(args[2])
+		if !ok {
+			b.logger.Error("Unknown card field", "field", args[2])
+			return
+		}
+
+		// Keep the rest of the state, an edit can interrupt a review session
+		state, _ := b.getState(user.TelegramID)
+		if state.ReviewState == nil {
+			state = UserState{}
+		}
+		state.State = "awaiting_card_edit"
+		state.EditCardID = card.ID
+		state.EditField = args[2]
+		b.setState(user.TelegramID, state)
+
+		b.sendMessage(chatID, prompt)
+
+	case "template":
+		b.showCardTemplates(chatID, card)
+
+	case "set_template":
+		if len(args) < 3 {
+			b.logger.Error("Invalid card template callback data", "args", args)
+			return
+		}
+		b.setCardTemplate(chatID, user, card, args[2])
+
+	case "delete":
+		b.confirmCardDeletion(chatID, card)
+
+	case "confirm_delete":
+		b.deleteCard(chatID, user, card)
+
+	case "done":
+		state, exists := b.getState(user.TelegramID)
+		if exists && state.ReviewState != nil {
+			b.endCardEdit(user, state)
+			b.resumeReview(chatID, user, state)
+			return
+		}
+		if exists && state.State == "awaiting_card_edit" {
+			b.clearState(user.TelegramID)
+		}
+		b.sendMessage(chatID, fmt.Sprintf("✅ Finished editing *%s*.", card.Word))
+	}
+}
+
+// cardFieldPrompt returns the input prompt for a card field
+func cardFieldPrompt(field string) (string, bool) {
+	switch field {
+	case "word":
+		return "Please send the new word.", true
+	case "definition":
+		return "Please send the new definition.", true
+	case "examples":
+		return "Please send the examples, one per line, or \"-\" to remove them.", true
+	case "image":
+		return "Please send the new context photo, or \"-\" to remove it.", true
+	}
+	return "", false
+}
+
+// showCardEditor shows a card with buttons to change its fields or delete it
+func (b *Bot) showCardEditor(chatID int64, card *models.FlashCard) {
+	text := fmt.Sprintf("✏️ *Editing \"%s\"*\n\n*Definition:*\n%s", card.Word, card.Definition)
+
+	if len(card.Examples) > 0 {
+		text += "\n\n*Examples:*"
+		for i, ex := range card.Examples {
+			text += fmt.Sprintf("\n%d. %s", i+1, ex)
+		}
+	}
+
+	if card.ImageURL != "" {
+		text += "\n\n*Context photo added!*"
+	}
+
+	if card.Template != "" {
+		text += "\n\n*Template:* " + templateLabel(card.Template)
+	}
+
+	text += "\n\nWhat do you want to change?"
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = b.createCardEditorKeyboard(card.ID)
+
+	b.api.Send(msg)
+}
+
+// confirmCardDeletion asks the user to confirm deleting a card
+func (b *Bot) confirmCardDeletion(chatID int64, card *models.FlashCard) {
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("🗑 Delete the card *%s*? Its review history is deleted too, for everyone who studies this bank.", card.Word))
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = b.createDeleteConfirmationKeyboard(card.ID)
+
+	b.api.Send(msg)
+}
+
+// deleteCard deletes a card and drops it from a review session in progress
+func (b *Bot) deleteCard(chatID int64, user *models.User, card *models.FlashCard) {
+	err := b.flashcardService.DeleteFlashCard(card.ID)
+	if err != nil {
+		b.logger.Error("Failed to delete flash card",
+			"error", err,
+			"card_id", card.ID,
+		)
+		b.sendErrorMessage(chatID, "Failed to delete the card. Please try again.")
+		return
+	}
+
+	b.sendMessage(chatID, fmt.Sprintf("🗑 Card *%s* deleted.", card.Word))
+
+	state, exists := b.getState(user.TelegramID)
+	if !exists {
+		return
+	}
+	if state.ReviewState == nil {
+		if state.State == "awaiting_card_edit" {
+			b.clearState(user.TelegramID)
+		}
+		return
+	}
+
+	// Drop the card from the rest of the session, keeping the position of the current card
+	reviewState := state.ReviewState
+	var cards []models.ReviewCard
+	current := reviewState.CurrentCard
+	for i, c := range reviewState.Cards {
+		if c.ID == card.ID {
+			if i < reviewState.CurrentCard {
+				current--
+			}
+			continue
+		}
+		cards = append(cards, c)
+	}
+
+	deletedCurrent := reviewState.CurrentCard < len(reviewState.Cards) && reviewState.Cards[reviewState.CurrentCard].ID == card.ID
+	reviewState.Cards = cards
+	reviewState.CurrentCard = current
+	if deletedCurrent {
+		reviewState.IsFlipped = false
+	}
+
+	if reviewState.CurrentCard >= len(reviewState.Cards) {
+		b.clearState(user.TelegramID)
+		b.sendMessage(chatID, "🎉 Review session completed!")
+		return
+	}
+
+	// Edits interrupt a review session, deleting ends the edit
+	state.State = "reviewing"
+	b.setState(user.TelegramID, state)
+
+	if deletedCurrent {
+		b.showReviewCard(chatID, user, reviewState.Cards[reviewState.CurrentCard], reviewState.Mode, false)
+	}
+}
+
+// resumeReview shows the current card of an interrupted review session again
+func (b *Bot) resumeReview(chatID int64, user *models.User, state UserState) {
+	reviewState := state.ReviewState
+	if reviewState.CurrentCard >= len(reviewState.Cards) {
+		b.clearState(user.TelegramID)
+		return
+	}
+
+	b.showReviewCard(chatID, user, reviewState.Cards[reviewState.CurrentCard], reviewState.Mode, reviewState.IsFlipped)
+}
+
+func (b *Bot) handleCardEditInput(update tgbotapi.Update, user *models.User, text string) {
+	chatID := update.Message.Chat.ID
+
+	state, _ := b.getState(user.TelegramID)
+	card, ok := b.editedCard(chatID, user, state)
+	if !ok {
+		return
+	}
+
+	text = strings.TrimSpace(text)
+
+	switch state.EditField {
+	case "word":
+		word := strings.ToLower(text)
+		if word == "" {
+			b.sendErrorMessage(chatID, "The word can't be empty. Please send the new word.")
+			return
+		}
+
+		// Words are unique within a bank
+		existing, err := b.flashcardService.GetFlashCardByWord(word, card.CardBankID)
+		if err == nil && existing.ID != card.ID {
+			b.sendErrorMessage(chatID, fmt.Sprintf("There already is a card for \"%s\" in this bank. Please send another word.", word))
+			return
+		}
+
+		// The pronunciation belongs to the old word
+		if word != card.Word {
+			card.Phonetic = ""
+			card.AudioURL = ""
+			card.AudioFileID = ""
+		}
+		card.Word = word
+
+	case "definition":
+		if text == "" {
+			b.sendErrorMessage(chatID, "The definition can't be empty. Please send the new definition.")
+			return
+		}
+		card.Definition = text
+
+	case "examples":
+		var examples []string
+		if text != clearValue {
+			for _, line := range strings.Split(text, "\n") {
+				if line = strings.TrimSpace(line); line != "" {
+					examples = append(examples, line)
+				}
+			}
+		}
+		card.Examples = examples
+
+	case "image":
+		if text != clearValue {
+			b.sendMessage(chatID, "Please send a photo, or \"-\" to remove the context photo.")
+			return
+		}
+		card.ImageURL = ""
+
+	default:
+		b.logger.Error("Unknown card field in state", "field", state.EditField)
+		b.clearState(user.TelegramID)
+		return
+	}
+
+	b.saveCardEdit(chatID, user, state, card)
+}
+
+func (b *Bot) handleCardImageInput(update tgbotapi.Update, user *models.User) {
+	chatID := update.Message.Chat.ID
+
+	state, _ := b.getState(user.TelegramID)
+	card, ok := b.editedCard(chatID, user, state)
+	if !ok {
+		return
+	}
+
+	photoURL, err := b.largestPhotoURL(update.Message.Photo)
+	if err != nil {
+		b.logger.Error("Failed to get photo file",
+			"error", err,
+			"card_id", card.ID,
+		)
+		b.sendErrorMessage(chatID, "Failed to process the photo. Please try again.")
+		return
+	}
+
+	card.ImageURL = photoURL
+	b.saveCardEdit(chatID, user, state, card)
+}
+
+// editedCard returns the card whose field the user is editing, checking they still may edit it
+func (b *Bot) editedCard(chatID int64, user *models.User, state UserState) (*models.FlashCard, bool) {
+	card, err := b.flashcardService.GetFlashCard(state.EditCardID)
+	if err != nil {
+		b.logger.Error("Failed to get edited flash card",
+			"error", err,
+			"card_id", state.EditCardID,
+		)
+		b.endCardEdit(user, state)
+		b.sendErrorMessage(chatID, "This card no longer exists.")
+		return nil, false
+	}
+
+	if !b.checkCanEdit(chatID, user, card.CardBankID) {
+		b.endCardEdit(user, state)
+		return nil, false
+	}
+
+	return card, true
+}
+
+// saveCardEdit saves an edited card and shows the editor again
+func (b *Bot) saveCardEdit(chatID int64, user *models.User, state UserState, card *models.FlashCard) {
+	err := b.flashcardService.UpdateFlashCard(card)
+	if err != nil {
+		b.logger.Error("Failed to update flash card",
+			"error", err,
+			"card_id", card.ID,
+		)
+		b.sendErrorMessage(chatID, "Failed to save the card. Please try again.")
+		return
+	}
+
+	// A review session in progress shows the edited card from now on
+	if state.ReviewState != nil {
+		for i := range state.ReviewState.Cards {
+			if state.ReviewState.Cards[i].ID == card.ID {
+				state.ReviewState.Cards[i].FlashCard = *card
+			}
+		}
+	}
+	b.endCardEdit(user, state)
+
+	b.sendMessage(chatID, "✅ Card updated.")
+	b.showCardEditor(chatID, card)
+}
+
+// endCardEdit leaves the card editing state, going back to an interrupted review session
+func (b *Bot) endCardEdit(user *models.User, state UserState) {
+	if state.ReviewState == nil {
+		b.clearState(user.TelegramID)
+		return
+	}
+
+	state.State = "reviewing"
+	state.EditCardID = 0
+	state.EditField = ""
+	b.setState(user.TelegramID, state)
+}
@@ -0,0 +1,106 @@
+package telegram
+
+import (
+	"fmt"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/supercakecrumb/flash-cards-language-tg-bot/internal/domain/models"
+	"github.com/supercakecrumb/flash-cards-language-tg-bot/internal/infrastructure/exporter"
+)
+
+func (b *Bot) handleExportCommand(update tgbotapi.Update, user *models.User, args string) {
+	chatID := update.Message.Chat.ID
+
+	// Export right away if a format was given, e.g. "/export json reviews"
+	fields := strings.Fields(strings.ToLower(args))
+	if len(fields) > 0 {
+		includeReviews := len(fields) > 1 && fields[1] == "reviews"
+		b.exportActiveBank(chatID, user, fields[0], includeReviews)
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chatID, "📤 Choose the export format for your active card bank:")
+	msg.ReplyMarkup = b.createExportKeyboard()
+
+	b.api.Send(msg)
+}
+
+func (b *Bot) handleExportCallback(update tgbotapi.Update, user *models.User, args []string) {
+	chatID := update.CallbackQuery.Message.Chat.ID
+
+	includeReviews := len(args) > 1 && args[1] == "reviews"
+	b.exportActiveBank(chatID, user, args[0], includeReviews)
+}
+
+// exportActiveBank sends the user's active card bank as a document
+func (b *Bot) exportActiveBank(chatID int64, user *models.User, format string, includeReviews bool) {
+	switch format {
+	case exporter.FormatCSV, exporter.FormatJSON, exporter.FormatAPKG:
+	default:
+		b.sendErrorMessage(chatID, "Unknown export format. Please use csv, json or apkg.")
+		return
+	}
+
+	// Get user's active card bank
+	settings, err := b.settingsService.GetUserSettings(user.ID)
+	if err != nil {
+		b.logger.Error("Failed to get user settings",
+			"error", err,
+			"user_id", user.ID,
+		)
+		b.sendErrorMessage(chatID, "Failed to get your settings. Please try again.")
+		return
+	}
+
+	activeBankID := settings.Settings.ActiveCardBankID
+
+	// Check if user has access to this bank
+	hasAccess, err := b.cardbankService.UserHasAccess(user.ID, activeBankID)
+	if err != nil || !hasAccess {
+		b.logger.Error("User doesn't have access to active bank",
+			"error", err,
+			"user_id", user.ID,
+			"bank_id", activeBankID,
+		)
+		b.sendErrorMessage(chatID, "You don't have access to your active card bank. Please select another bank using /banks.")
+		return
+	}
+
+	file, err := b.exportService.ExportBank(user.ID, activeBankID, format, includeReviews)
+	if err != nil {
+		b.logger.Error("Failed to export bank",
+			"error", err,
+			"user_id", user.ID,
+			"bank_id", activeBankID,
+			"format", format,
+		)
+		b.sendErrorMessage(chatID, "Failed to export your card bank. Please try again.")
+		return
+	}
+
+	caption := "Here is your card bank."
+	switch {
+	case format == exporter.FormatJSON && includeReviews:
+		caption = "Here is your card bank with your review progress. Send it to /import to restore it."
+	case format == exporter.FormatJSON:
+		caption = "Here is your card bank. Send it to /import to restore it."
+	case format == exporter.FormatAPKG:
+		caption = "Here is your card bank. Open it in Anki with File → Import."
+	}
+
+	doc := tgbotapi.NewDocument(chatID, tgbotapi.FileBytes{
+		Name:  file.Name,
+		Bytes: file.Data,
+	})
+	doc.Caption = caption
+
+	if _, err := b.api.Send(doc); err != nil {
+		b.logger.Error("Failed to send export",
+			"error", err,
+			"user_id", user.ID,
+			"file_name", file.Name,
+		)
+		b.sendErrorMessage(chatID, fmt.Sprintf("Failed to send %s. Please try again.", file.Name))
+	}
+}
@@ -1,12 +1,16 @@
 package telegram
 
 import (
+	"errors"
 	"fmt"
+	"html"
 	"strconv"
 	"strings"
+	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/supercakecrumb/flash-cards-language-tg-bot/internal/domain/models"
+	"github.com/supercakecrumb/flash-cards-language-tg-bot/internal/domain/services"
 	"github.com/supercakecrumb/flash-cards-language-tg-bot/pkg/spaced_repetition"
 )
 
@@ -58,6 +62,24 @@ func (b *Bot) handleCommand(update tgbotapi.Update) {
 		b.handleJoinBankCommand(update, user, args)
 	case "settings":
 		b.handleSettingsCommand(update, user)
+	case "import":
+		b.handleImportCommand(update, user)
+	case "export":
+		b.handleExportCommand(update, user, args)
+	case "language":
+		b.handleLanguageCommand(update, user, args)
+	case "template":
+		b.handleTemplateCommand(update, user, args)
+	case "cloze":
+		b.handleClozeCommand(update, user, args)
+	case "edit":
+		b.handleEditCommand(update, user, args)
+	case "delete":
+		b.handleDeleteCommand(update, user, args)
+	case "cards":
+		b.handleCardsCommand(update, user, args)
+	case "manual":
+		b.handleManualCommand(update, user, args)
 	case "admin":
 		b.handleAdminCommand(update, user, args)
 	default:
@@ -119,6 +141,12 @@ func (b *Bot) handleCallback(update tgbotapi.Update) {
 		b.handleSettingsCallback(update, user, parts[1:])
 	case "page":
 		b.handlePaginationCallback(update, user, parts[1:])
+	case "imp":
+		b.handleImportCallback(update, user, parts[1:])
+	case "exp":
+		b.handleExportCallback(update, user, parts[1:])
+	case "card":
+		b.handleCardCallback(update, user, parts[1:])
 	default:
 		b.logger.Warn("Unknown callback type", "type", callbackType)
 	}
@@ -144,7 +172,7 @@ func (b *Bot) handleMessage(update tgbotapi.Update) {
 	isGroup := update.Message.Chat.IsGroup() || update.Message.Chat.IsSuperGroup()
 
 	// Get user's current state
-	state, exists := b.userStates[user.TelegramID]
+	state, exists := b.getState(user.TelegramID)
 
 	// Handle based on state or default to word addition in private chats
 	if exists {
@@ -155,8 +183,20 @@ func (b *Bot) handleMessage(update tgbotapi.Update) {
 			b.handleBankNameInput(update, user, text)
 		case "awaiting_settings":
 			b.handleSettingsInput(update, user, text)
+		case "awaiting_import_columns":
+			b.handleImportColumnsInput(update, user, text)
+		case "awaiting_card_edit":
+			b.handleCardEditInput(update, user, text)
+		case "awaiting_manual_front", "awaiting_manual_back", "awaiting_manual_examples":
+			b.handleManualInput(update, user, text)
 		case "awaiting_admin_input":
 			b.handleAdminInput(update, user, text)
+		case "reviewing":
+			if state.ReviewState != nil && state.ReviewState.Mode == reviewModeTyped && !state.ReviewState.IsFlipped {
+				b.handleTypedAnswer(update, user, text)
+			} else if !isGroup {
+				b.handleWordInput(update, user, text)
+			}
 		default:
 			// If in private chat and no specific state, treat as word to add
 			if !isGroup {
@@ -192,11 +232,13 @@ func (b *Bot) handlePhoto(update tgbotapi.Update) {
 	}
 
 	// Get user's current state
-	state, exists := b.userStates[user.TelegramID]
+	state, exists := b.getState(user.TelegramID)
 
 	// Only process photos if we're expecting one for a flash card
 	if exists && state.State == "awaiting_photo" {
 		b.handleContextPhoto(update, user)
+	} else if exists && state.State == "awaiting_card_edit" && state.EditField == "image" {
+		b.handleCardImageInput(update, user)
 	} else {
 		b.sendMessage(chatID, "I can only process photos when you're creating a flash card. Please send a word first.")
 	}
@@ -209,10 +251,11 @@ func (b *Bot) handleStartCommand(update tgbotapi.Update, user *models.User) {
 
 	welcomeText := `Welcome to the Flash Cards Language Bot! 🎉
 
-This bot helps you learn English vocabulary using a spaced repetition system similar to Anki.
+This bot helps you learn vocabulary using a spaced repetition system similar to Anki.
 
 To get started:
 • Send any English word to create a flash card
+• Use /language to learn words of another language
 • Use /review to practice your vocabulary
 • Use /banks to manage your card collections
 • Use /stats to see your learning progress
@@ -231,8 +274,14 @@ func (b *Bot) handleHelpCommand(update tgbotapi.Update, user *models.User) {
 *Basic Commands:*
 • Send any word - Create a flash card for this word
 • /add [word] - Explicitly add a word as a flash card
-• /review - Start a review session with due cards
-• /stats - View your learning statistics
+• /manual - Write a card yourself, for phrases, idioms or words no dictionary knows
+• Send "word — definition | example" - Create a card without a dictionary lookup, one card per line (use /manual in groups)
+• /review - Start a review session with due cards, ↩️ Undo takes back the last rating
+• /review synonyms - Review cards by their synonyms instead of their definitions
+• /review typed - Type the word instead of flipping the card, small typos and accents are forgiven
+• /review quiz - Choose the word for each definition out of four words of the bank
+• /stats - View your learning statistics and answer times
+• /stats times - See the average answer time of every card
 • /help - Show this help message
 
 *Card Banks:*
@@ -240,6 +289,14 @@ func (b *Bot) handleHelpCommand(update tgbotapi.Update, user *models.User) {
 • /create_bank [name] - Create a new card bank
 • /share_bank [username] - Share a bank with another user
 • /join_bank [code] - Join a shared card bank
+• /import - Import cards from a CSV, TSV, JSON or Anki file
+• /export [csv|json|apkg] - Export the active bank as a file
+• /language [word language] [definition language] - Set the languages of the active bank, e.g. /language de en
+• /template [reverse|forward|both] - Review the active bank definition → word, word → definition or both
+• /cloze [on|off] - Also review cards as examples with the word blanked out
+• /cards [new|learning|due|mature] [text] - Browse and search the cards of the active bank
+• /edit [word] - Change the word, definition, examples or image of a card
+• /delete [word] - Delete a card
 
 *Settings:*
 • /settings - Configure your preferences
@@ -269,22 +326,22 @@ func (b *Bot) handleAddWordCommand(update tgbotapi.Update, user *models.User, ar
 
 	// If no word provided in command, prompt user and set state
 	if args == "" {
-		b.userStates[user.TelegramID] = UserState{
+		b.setState(user.TelegramID, UserState{
 			State: "awaiting_word",
-		}
-		b.sendMessage(chatID, "Please send the English word you want to add as a flash card.")
+		})
+		b.sendMessage(chatID, "Please send the word you want to add as a flash card.")
 		return
 	}
 
 	// Process the word provided in command
-	b.processWord(user, chatID, args)
+	b.processWord(user, chatID, args, update.Message.Chat.IsPrivate())
 }
 
 func (b *Bot) handleWordInput(update tgbotapi.Update, user *models.User, text string) {
 	chatID := update.Message.Chat.ID
 
 	// Process the word
-	b.processWord(user, chatID, text)
+	b.processWord(user, chatID, text, update.Message.Chat.IsPrivate())
 }
 
 func (b *Bot) handleGroupWordInput(update tgbotapi.Update, user *models.User, text string, bankID int) {
@@ -296,13 +353,21 @@ func (b *Bot) handleGroupWordInput(update tgbotapi.Update, user *models.User, te
 		State:       "awaiting_word",
 		CurrentBank: bankID,
 	}
-	b.userStates[user.TelegramID] = state
+	b.setState(user.TelegramID, state)
 
-	// Process the word
-	b.processWord(user, chatID, text)
+	// Process the word, group messages with dashes are too common to be taken as cards
+	b.processWord(user, chatID, text, false)
 }
 
-func (b *Bot) processWord(user *models.User, chatID int64, word string) {
+// processWord looks up a word to make a card of it. When manual is set, cards written out
+// as "word — definition" are created without a dictionary.
+func (b *Bot) processWord(user *models.User, chatID int64, word string, manual bool) {
+	var cards []manualCard
+	var invalid []string
+	if manual {
+		cards, invalid = parseManualCards(word)
+	}
+
 	// Clean up the word
 	word = strings.TrimSpace(strings.ToLower(word))
 
@@ -319,7 +384,7 @@ func (b *Bot) processWord(user *models.User, chatID int64, word string) {
 
 	// Use the bank ID from user state if available, otherwise use the active bank from settings
 	bankID := settings.Settings.ActiveCardBankID
-	state, exists := b.userStates[user.TelegramID]
+	state, exists := b.getState(user.TelegramID)
 	if exists && state.CurrentBank != 0 {
 		bankID = state.CurrentBank
 	}
@@ -336,21 +401,57 @@ func (b *Bot) processWord(user *models.User, chatID int64, word string) {
 		return
 	}
 
+	// Viewers can't add cards
+	if !b.checkCanEdit(chatID, user, bankID) {
+		return
+	}
+
+	if len(cards) > 0 {
+		b.clearState(user.TelegramID)
+		b.createManualCards(chatID, user, bankID, cards, invalid)
+		return
+	}
+
+	// The bank's languages decide which dictionary is used
+	bank, err := b.cardbankService.GetCardBank(bankID)
+	if err != nil {
+		b.logger.Error("Failed to get bank",
+			"error", err,
+			"bank_id", bankID,
+		)
+		b.sendErrorMessage(chatID, "Failed to get your card bank. Please try again.")
+		return
+	}
+
 	// Get definitions from dictionary service
 	b.sendMessage(chatID, fmt.Sprintf("Looking up definitions for \"%s\"...", word))
 
-	definitions, err := b.flashcardService.GetDefinitions(word)
+	definitions, err := b.flashcardService.GetDefinitions(word, bank.SourceLanguage, bank.TargetLanguage)
+	if errors.Is(err, services.ErrUnsupportedLanguage) {
+		b.sendErrorMessage(chatID, fmt.Sprintf("No dictionary supports %s → %s. Use /language to change the languages of this bank.", bank.SourceLanguage, bank.TargetLanguage))
+		return
+	}
+	if errors.Is(err, services.ErrExternalAPIError) {
+		b.logger.Warn("Dictionaries are unavailable",
+			"error", err,
+			"word", word,
+		)
+		b.sendErrorMessage(chatID, "The dictionaries are unavailable right now. Please try again in a few minutes.")
+		return
+	}
 	if err != nil {
 		b.logger.Error("Failed to get definitions",
 			"error", err,
 			"word", word,
+			"source_language", bank.SourceLanguage,
+			"target_language", bank.TargetLanguage,
 		)
 		b.sendErrorMessage(chatID, "Failed to get definitions for this word. Please try another word.")
 		return
 	}
 
 	if len(definitions) == 0 {
-		b.sendMessage(chatID, "No definitions found for this word. Please check the spelling or try another word.")
+		b.sendMessage(chatID, "No definitions found for this word. Please check the spelling, or use /manual to write the card yourself.")
 		return
 	}
 
@@ -360,7 +461,7 @@ func (b *Bot) processWord(user *models.User, chatID int64, word string) {
 		CurrentWord: word,
 		CurrentBank: bankID,
 	}
-	b.userStates[user.TelegramID] = state
+	b.setState(user.TelegramID, state)
 
 	// Send definitions with inline keyboard
 	text := fmt.Sprintf("📝 *Definitions for \"%s\"*\n\nPlease select the definition you want to use:", word)
@@ -383,17 +484,12 @@ func (b *Bot) handleDefinitionCallback(update tgbotapi.Update, user *models.User
 	definitionID := args[0]
 
 	// Get user state
-	state, exists := b.userStates[user.TelegramID]
+	state, exists := b.getState(user.TelegramID)
 	if !exists || state.CurrentWord == "" {
 		b.sendErrorMessage(chatID, "Session expired. Please start again by sending a word.")
 		return
 	}
 
-	// Update user state
-	state.State = "selecting_examples"
-	state.SelectedDef = definitionID
-	b.userStates[user.TelegramID] = state
-
 	// Get examples for this definition
 	examples, err := b.flashcardService.GetExamples(state.CurrentWord, definitionID)
 	if err != nil {
@@ -417,8 +513,27 @@ func (b *Bot) handleDefinitionCallback(update tgbotapi.Update, user *models.User
 		return
 	}
 
+	// Related words belong to a definition, choosing another one starts without them
+	if state.SelectedDef != definitionID {
+		state.IncludeSynonyms = false
+		state.IncludeAntonyms = false
+	}
+
+	// Update user state, keeping the definition so the card can be created after a restart
+	state.State = "selecting_examples"
+	state.SelectedDef = definitionID
+	state.Definition = definition
+	b.setState(user.TelegramID, state)
+
 	// Send message about selected definition
-	selectionText := fmt.Sprintf("You selected: *%s*\n\nNow, please select examples you want to include:", definition.Text)
+	selectionText := fmt.Sprintf("You selected: *%s*", definition.Text)
+	if len(definition.Synonyms) > 0 {
+		selectionText += "\n\n*Synonyms:* " + strings.Join(definition.Synonyms, ", ")
+	}
+	if len(definition.Antonyms) > 0 {
+		selectionText += "\n\n*Antonyms:* " + strings.Join(definition.Antonyms, ", ")
+	}
+	selectionText += "\n\nNow, please select examples you want to include:"
 
 	msg := tgbotapi.NewMessage(chatID, selectionText)
 	msg.ParseMode = "HTML"
@@ -429,16 +544,9 @@ func (b *Bot) handleDefinitionCallback(update tgbotapi.Update, user *models.User
 		// No examples available
 		text := "No examples available for this definition. You can add your own context later."
 
-		// Add buttons to continue or add photo
-		keyboard := tgbotapi.NewInlineKeyboardMarkup(
-			tgbotapi.NewInlineKeyboardRow(
-				tgbotapi.NewInlineKeyboardButtonData("Add context photo", "ex:photo"),
-				tgbotapi.NewInlineKeyboardButtonData("Create card", "ex:create"),
-			),
-		)
-
+		// Add buttons to continue, add a photo or include related words
 		msg := tgbotapi.NewMessage(chatID, text)
-		msg.ReplyMarkup = keyboard
+		msg.ReplyMarkup = b.createExamplesKeyboard(nil, state)
 
 		b.api.Send(msg)
 		return
@@ -449,7 +557,7 @@ func (b *Bot) handleDefinitionCallback(update tgbotapi.Update, user *models.User
 
 	examplesMsg := tgbotapi.NewMessage(chatID, examplesText)
 	examplesMsg.ParseMode = "HTML"
-	examplesMsg.ReplyMarkup = b.createExamplesKeyboard(examples, state.Examples)
+	examplesMsg.ReplyMarkup = b.createExamplesKeyboard(examples, state)
 
 	b.api.Send(examplesMsg)
 }
@@ -465,7 +573,7 @@ func (b *Bot) handleExampleCallback(update tgbotapi.Update, user *models.User, a
 	action := args[0]
 
 	// Get user state
-	state, exists := b.userStates[user.TelegramID]
+	state, exists := b.getState(user.TelegramID)
 	if !exists || state.CurrentWord == "" || state.SelectedDef == "" {
 		b.sendErrorMessage(chatID, "Session expired. Please start again by sending a word.")
 		return
@@ -492,10 +600,10 @@ func (b *Bot) handleExampleCallback(update tgbotapi.Update, user *models.User, a
 
 		if !found {
 			state.Examples = append(state.Examples, exampleID)
-			b.userStates[user.TelegramID] = state
+			b.setState(user.TelegramID, state)
 
 			// Get the example
-			example, err := b.flashcardService.GetExample(exampleID)
+			example, err := b.selectedExample(state, exampleID)
 			if err != nil {
 				b.logger.Error("Failed to get example",
 					"error", err,
@@ -508,7 +616,7 @@ func (b *Bot) handleExampleCallback(update tgbotapi.Update, user *models.User, a
 			b.sendMessage(chatID, fmt.Sprintf("Example selected: \"%s\"\n\nYou have selected %d examples.", example.Text, len(state.Examples)))
 
 			// Send updated examples keyboard
-			examples, err := b.flashcardService.GetExamples(state.CurrentWord, state.SelectedDef)
+			examples, err := b.selectedExamples(state)
 			if err != nil {
 				b.logger.Error("Failed to get examples for keyboard update",
 					"error", err,
@@ -522,15 +630,47 @@ func (b *Bot) handleExampleCallback(update tgbotapi.Update, user *models.User, a
 
 			examplesMsg := tgbotapi.NewMessage(chatID, examplesText)
 			examplesMsg.ParseMode = "HTML"
-			examplesMsg.ReplyMarkup = b.createExamplesKeyboard(examples, state.Examples)
+			examplesMsg.ReplyMarkup = b.createExamplesKeyboard(examples, state)
 
 			b.api.Send(examplesMsg)
 		}
 
+	case "synonyms", "antonyms":
+		// User toggled the related words of the definition
+		var text string
+		if action == "synonyms" {
+			state.IncludeSynonyms = !state.IncludeSynonyms
+			text = "Synonyms won't be added to the card."
+			if state.IncludeSynonyms {
+				text = "Synonyms will be added to the card."
+			}
+		} else {
+			state.IncludeAntonyms = !state.IncludeAntonyms
+			text = "Antonyms won't be added to the card."
+			if state.IncludeAntonyms {
+				text = "Antonyms will be added to the card."
+			}
+		}
+		b.setState(user.TelegramID, state)
+
+		examples, err := b.selectedExamples(state)
+		if err != nil {
+			b.logger.Error("Failed to get examples for keyboard update",
+				"error", err,
+				"word", state.CurrentWord,
+				"def_id", state.SelectedDef,
+			)
+			return
+		}
+
+		msg := tgbotapi.NewMessage(chatID, text)
+		msg.ReplyMarkup = b.createExamplesKeyboard(examples, state)
+		b.api.Send(msg)
+
 	case "photo":
 		// User wants to add a context photo
 		state.State = "awaiting_photo"
-		b.userStates[user.TelegramID] = state
+		b.setState(user.TelegramID, state)
 
 		b.sendMessage(chatID, "Please send a photo that provides context for this word.")
 
@@ -540,16 +680,54 @@ func (b *Bot) handleExampleCallback(update tgbotapi.Update, user *models.User, a
 	}
 }
 
+// selectedExamples returns the examples of the selected definition, preferring the copy kept in the user state
+func (b *Bot) selectedExamples(state UserState) ([]services.Example, error) {
+	if state.Definition != nil {
+		return state.Definition.Examples, nil
+	}
+	return b.flashcardService.GetExamples(state.CurrentWord, state.SelectedDef)
+}
+
+// selectedExample returns an example of the selected definition, preferring the copy kept in the user state
+func (b *Bot) selectedExample(state UserState, exampleID string) (*services.Example, error) {
+	if state.Definition != nil {
+		for _, ex := range state.Definition.Examples {
+			if ex.ID == exampleID {
+				return &ex, nil
+			}
+		}
+	}
+	return b.flashcardService.GetExample(exampleID)
+}
+
 func (b *Bot) handleContextPhoto(update tgbotapi.Update, user *models.User) {
 	chatID := update.Message.Chat.ID
 
-	// Get the largest photo
-	photos := update.Message.Photo
-	if len(photos) == 0 {
+	photoURL, err := b.largestPhotoURL(update.Message.Photo)
+	if err != nil {
+		b.logger.Error("Failed to get photo file",
+			"error", err,
+			"user_id", user.ID,
+		)
 		b.sendErrorMessage(chatID, "Failed to process the photo. Please try again.")
 		return
 	}
 
+	// Update user state
+	state, _ := b.getState(user.TelegramID)
+	state.PhotoURL = photoURL
+	b.setState(user.TelegramID, state)
+
+	// Create flash card with photo
+	b.createFlashCard(chatID, user, state)
+}
+
+// largestPhotoURL returns the file link of the largest size of a photo
+func (b *Bot) largestPhotoURL(photos []tgbotapi.PhotoSize) (string, error) {
+	if len(photos) == 0 {
+		return "", errors.New("message has no photo")
+	}
+
 	largestPhoto := photos[len(photos)-1]
 
 	// Get file URL
@@ -559,41 +737,32 @@ func (b *Bot) handleContextPhoto(update tgbotapi.Update, user *models.User) {
 
 	file, err := b.api.GetFile(fileConfig)
 	if err != nil {
-		b.logger.Error("Failed to get photo file",
-			"error", err,
-			"file_id", largestPhoto.FileID,
-		)
-		b.sendErrorMessage(chatID, "Failed to process the photo. Please try again.")
-		return
+		return "", err
 	}
 
-	photoURL := file.Link(b.api.Token)
-
-	// Update user state
-	state := b.userStates[user.TelegramID]
-	state.PhotoURL = photoURL
-	b.userStates[user.TelegramID] = state
-
-	// Create flash card with photo
-	b.createFlashCard(chatID, user, state)
+	return file.Link(b.api.Token), nil
 }
 
 func (b *Bot) createFlashCard(chatID int64, user *models.User, state UserState) {
 	// Get definition
-	definition, err := b.flashcardService.GetDefinition(state.SelectedDef)
-	if err != nil {
-		b.logger.Error("Failed to get definition for card creation",
-			"error", err,
-			"def_id", state.SelectedDef,
-		)
-		b.sendErrorMessage(chatID, "Failed to create flash card. Please try again.")
-		return
+	definition := state.Definition
+	if definition == nil {
+		var err error
+		definition, err = b.flashcardService.GetDefinition(state.SelectedDef)
+		if err != nil {
+			b.logger.Error("Failed to get definition for card creation",
+				"error", err,
+				"def_id", state.SelectedDef,
+			)
+			b.sendErrorMessage(chatID, "Failed to create flash card. Please try again.")
+			return
+		}
 	}
 
 	// Get examples
 	var exampleTexts []string
 	for _, exID := range state.Examples {
-		ex, err := b.flashcardService.GetExample(exID)
+		ex, err := b.selectedExample(state, exID)
 		if err != nil {
 			b.logger.Warn("Failed to get example",
 				"error", err,
@@ -612,9 +781,18 @@ func (b *Bot) createFlashCard(chatID int64, user *models.User, state UserState)
 		exampleTexts,
 		state.PhotoURL,
 	)
+	card.Phonetic = definition.Phonetic
+	card.AudioURL = definition.AudioURL
+	card.PartOfSpeech = definition.PartOfSpeech
+	if state.IncludeSynonyms {
+		card.Synonyms = definition.Synonyms
+	}
+	if state.IncludeAntonyms {
+		card.Antonyms = definition.Antonyms
+	}
 
 	// Save to database
-	err = b.flashcardService.CreateFlashCard(card)
+	err := b.flashcardService.CreateFlashCard(card)
 	if err != nil {
 		b.logger.Error("Failed to save flash card",
 			"error", err,
@@ -635,11 +813,14 @@ func (b *Bot) createFlashCard(chatID int64, user *models.User, state UserState)
 	}
 
 	// Clear user state
-	delete(b.userStates, user.TelegramID)
+	b.clearState(user.TelegramID)
 
 	// Send confirmation
-	text := fmt.Sprintf("✅ Flash card created for *%s*!\n\n*Definition:*\n%s",
-		state.CurrentWord, definition.Text)
+	text := fmt.Sprintf("✅ Flash card created for *%s*!", state.CurrentWord)
+	if card.Phonetic != "" {
+		text += " " + card.Phonetic
+	}
+	text += "\n\n*Definition:*\n" + definition.Text
 
 	if len(exampleTexts) > 0 {
 		text += "\n\n*Examples:*"
@@ -648,6 +829,8 @@ func (b *Bot) createFlashCard(chatID int64, user *models.User, state UserState)
 		}
 	}
 
+	text += relatedWordsText(*card)
+
 	if state.PhotoURL != "" {
 		text += "\n\n*Context photo added!*"
 	}
@@ -658,25 +841,48 @@ func (b *Bot) createFlashCard(chatID int64, user *models.User, state UserState)
 	msg.ParseMode = "HTML"
 
 	b.api.Send(msg)
+
+	// Uploading the recording now keeps it even if the dictionary removes it
+	b.sendPronunciation(chatID, card)
 }
 
 // Other handlers (placeholder implementations)
 
+// learnAheadLimit is how early learning cards are shown when nothing else is left to review
+const learnAheadLimit = 20 * time.Minute
+
+// Review modes, deciding what the front of a card asks for
+const (
+	reviewModeDefinition = "definition" // recall the word from its definition
+	reviewModeSynonyms   = "synonyms"   // recall the word from its synonyms
+	reviewModeTyped      = "typed"      // type the word after seeing its definition
+	reviewModeQuiz       = "quiz"       // choose the word for a definition out of several
+)
+
 // ReviewState represents the state of a review session
 type ReviewState struct {
-	Cards       []models.FlashCard
+	Cards       []models.ReviewCard
 	CurrentCard int
 	IsFlipped   bool
 	BankID      int
+	Mode        string        // empty for sessions started before modes existed, which are definition reviews
+	ShownAt     time.Time     // when the front of the current card was shown
+	Latency     time.Duration // from showing the current card to flipping or answering it, 0 if unknown
+	LastRating  *LastRating   // the latest rating of the session, until it is undone
 }
 
 func (b *Bot) handleReviewCommand(update tgbotapi.Update, user *models.User, args string) {
 	chatID := update.Message.Chat.ID
 
-	// Parse limit argument if provided
+	// Parse the mode and limit arguments if provided
 	limit := 10 // Default limit
-	if args != "" {
-		customLimit, err := strconv.Atoi(args)
+	mode := reviewModeDefinition
+	for _, arg := range strings.Fields(args) {
+		if arg == reviewModeSynonyms || arg == reviewModeTyped || arg == reviewModeQuiz {
+			mode = arg
+			continue
+		}
+		customLimit, err := strconv.Atoi(arg)
 		if err == nil && customLimit > 0 && customLimit <= 50 {
 			limit = customLimit
 		}
@@ -707,6 +913,16 @@ func (b *Bot) handleReviewCommand(update tgbotapi.Update, user *models.User, arg
 		return
 	}
 
+	// Resume an interrupted review session, e.g. after a restart
+	if state, exists := b.getState(user.TelegramID); exists && args == "" && state.State == "reviewing" && state.ReviewState != nil {
+		reviewState := state.ReviewState
+		if reviewState.BankID == activeBankID && reviewState.CurrentCard < len(reviewState.Cards) {
+			b.sendMessage(chatID, fmt.Sprintf("▶️ Resuming your review session: %d cards left.", len(reviewState.Cards)-reviewState.CurrentCard))
+			b.showReviewCard(chatID, user, reviewState.Cards[reviewState.CurrentCard], reviewState.Mode, reviewState.IsFlipped)
+			return
+		}
+	}
+
 	// Get due cards
 	dueCards, err := b.spacedRepService.GetDueCards(user.ID, activeBankID, limit)
 	if err != nil {
@@ -719,28 +935,74 @@ func (b *Bot) handleReviewCommand(update tgbotapi.Update, user *models.User, arg
 		return
 	}
 
+	// Get what is left of today's limits
+	remainingNew, remainingReviews, err := b.spacedRepService.GetDailyRemaining(user.ID, activeBankID)
+	if err != nil {
+		b.logger.Warn("Failed to get daily remaining cards",
+			"error", err,
+			"user_id", user.ID,
+			"bank_id", activeBankID,
+		)
+	}
+
+	// Only cards with synonyms can be reviewed by them
+	if mode == reviewModeSynonyms {
+		dueCards = filterReviewCards(dueCards, mode)
+		if len(dueCards) == 0 {
+			b.sendMessage(chatID, "None of your due cards have synonyms. Turn on synonyms when creating cards, or use /review to review by definition.")
+			return
+		}
+	}
+
+	// Only cards that ask for the word can be answered by typing or choosing it
+	if mode == reviewModeTyped || mode == reviewModeQuiz {
+		dueCards = filterReviewCards(dueCards, mode)
+		if len(dueCards) == 0 {
+			b.sendMessage(chatID, "None of your due cards ask for the word. Use /template to review definition → word or /cloze to fill gaps in examples, or use /review.")
+			return
+		}
+	}
+
 	if len(dueCards) == 0 {
+		// Only blame the limits if they are what holds cards back
+		limited, err := b.spacedRepService.HasLimitedCards(user.ID, activeBankID)
+		if err != nil {
+			b.logger.Warn("Failed to check for limited cards",
+				"error", err,
+				"user_id", user.ID,
+				"bank_id", activeBankID,
+			)
+		}
+		if limited {
+			b.sendMessage(chatID, "You've reached your daily limits for this bank. Come back tomorrow! 🎉\n\nYou can change the limits in /settings.")
+			return
+		}
 		b.sendMessage(chatID, "You don't have any cards due for review. Great job! 🎉")
 		return
 	}
 
+	if err == nil {
+		b.sendMessage(chatID, fmt.Sprintf("📅 Left for today: %d new cards and %d reviews.", remainingNew, remainingReviews))
+	}
+
 	// Create review state
 	reviewState := ReviewState{
 		Cards:       dueCards,
 		CurrentCard: 0,
 		IsFlipped:   false,
 		BankID:      activeBankID,
+		Mode:        mode,
 	}
 
 	// Store review state in user state
-	b.userStates[user.TelegramID] = UserState{
+	b.setState(user.TelegramID, UserState{
 		State:       "reviewing",
 		CurrentBank: activeBankID,
 		ReviewState: &reviewState,
-	}
+	})
 
 	// Show first card
-	b.showReviewCard(chatID, user, reviewState.Cards[0], false)
+	b.showReviewCard(chatID, user, reviewState.Cards[0], mode, false)
 }
 
 func (b *Bot) handleReviewCallback(update tgbotapi.Update, user *models.User, args []string) {
@@ -753,8 +1015,14 @@ func (b *Bot) handleReviewCallback(update tgbotapi.Update, user *models.User, ar
 
 	action := args[0]
 
+	// The last rating can be undone after the session is completed
+	if action == "undo" {
+		b.handleUndoRating(update, user, args[1:])
+		return
+	}
+
 	// Get user state
-	state, exists := b.userStates[user.TelegramID]
+	state, exists := b.getState(user.TelegramID)
 	if !exists || state.State != "reviewing" || state.ReviewState == nil {
 		b.sendErrorMessage(chatID, "Review session expired. Please start a new review with /review.")
 		return
@@ -763,107 +1031,276 @@ func (b *Bot) handleReviewCallback(update tgbotapi.Update, user *models.User, ar
 	reviewState := state.ReviewState
 
 	switch action {
+	case "quiz":
+		b.handleQuizAnswer(update, user, state, args[1:])
+
 	case "flip":
-		// Flip the card
+		// Buttons of earlier cards stay in the chat
+		if !isCurrentReviewCard(reviewState, args[1:]) || reviewState.IsFlipped {
+			b.sendMessage(chatID, "This card has already been flipped.")
+			return
+		}
+
+		// Flip the card, which is when the user recalled it or gave up
 		reviewState.IsFlipped = true
-		b.userStates[user.TelegramID] = state
+		reviewState.Latency = answerLatency(reviewState)
+		b.setState(user.TelegramID, state)
 
 		// Show the flipped card
-		b.showReviewCard(chatID, user, reviewState.Cards[reviewState.CurrentCard], true)
+		b.showReviewCard(chatID, user, reviewState.Cards[reviewState.CurrentCard], reviewState.Mode, true)
 
 	case "rate":
-		if len(args) < 2 {
+		// rev:rate:<card ID>:<direction>:<rating>
+		if len(args) < 4 {
 			b.logger.Error("Invalid rating in review callback", "args", args)
 			return
 		}
 
 		// Parse rating
-		rating, err := strconv.Atoi(args[1])
+		rating, err := strconv.Atoi(args[3])
 		if err != nil || rating < 0 || rating > 3 {
-			b.logger.Error("Invalid rating value", "rating", args[1])
+			b.logger.Error("Invalid rating value", "rating", args[3])
 			return
 		}
 
-		// Process the review
-		currentCard := reviewState.Cards[reviewState.CurrentCard]
-		err = b.spacedRepService.ProcessReview(user.ID, currentCard.ID, rating)
-		if err != nil {
-			b.logger.Error("Failed to process review",
-				"error", err,
-				"user_id", user.ID,
-				"card_id", currentCard.ID,
-			)
-			b.sendErrorMessage(chatID, "Failed to save your review. Please try again.")
+		// A second tap on the buttons of a rated card must not rate the next one,
+		// which the user hasn't seen flipped yet
+		if !isCurrentReviewCard(reviewState, args[1:3]) || !reviewState.IsFlipped {
+			b.sendMessage(chatID, "This card has already been rated.")
 			return
 		}
 
-		// Update statistics
-		err = b.statsService.IncrementReviewed(user.ID, reviewState.BankID)
-		if err != nil {
-			b.logger.Warn("Failed to update statistics",
-				"error", err,
-				"user_id", user.ID,
-				"bank_id", reviewState.BankID,
-			)
+		b.rateReviewCard(chatID, user, state, rating)
+	}
+}
+
+// isCurrentReviewCard checks if the card ID and direction of review callback data are those of
+// the current card of the session
+func isCurrentReviewCard(reviewState *ReviewState, args []string) bool {
+	if len(args) < 2 || reviewState.CurrentCard >= len(reviewState.Cards) {
+		return false
+	}
+
+	cardID, err := strconv.Atoi(args[0])
+	if err != nil {
+		return false
+	}
+
+	card := reviewState.Cards[reviewState.CurrentCard]
+	return card.ID == cardID && reviewDirection(card) == args[1]
+}
+
+// rateReviewCard saves the rating of the current card of a review session and moves on to the next card
+func (b *Bot) rateReviewCard(chatID int64, user *models.User, state UserState, rating int) {
+	reviewState := state.ReviewState
+
+	// Fast and slow answers change the rating if the user grades by time
+	var timingText string
+	settings, err := b.settingsService.GetUserSettings(user.ID)
+	if err != nil {
+		b.logger.Warn("Failed to get user settings",
+			"error", err,
+			"user_id", user.ID,
+		)
+	} else if settings.Settings.TimedGrading {
+		if timed := spaced_repetition.TimedQuality(rating, reviewState.Latency); timed != rating {
+			timingText = fmt.Sprintf("⏱ You answered in %.1f seconds, so the card is rated %s.\n\n", reviewState.Latency.Seconds(), ratingName(timed))
+			rating = timed
 		}
+	}
+
+	// Process the review
+	currentCard := reviewState.Cards[reviewState.CurrentCard]
+	answer, err := b.spacedRepService.ProcessReview(user.ID, currentCard.ID, reviewDirection(currentCard), rating, reviewState.Latency)
+	if err != nil {
+		b.logger.Error("Failed to process review",
+			"error", err,
+			"user_id", user.ID,
+			"card_id", currentCard.ID,
+			"direction", reviewDirection(currentCard),
+		)
+		b.sendErrorMessage(chatID, "Failed to save your review. Please try again.")
+		return
+	}
+
+	// Update statistics
+	err = b.statsService.IncrementReviewed(user.ID, reviewState.BankID)
+	if err != nil {
+		b.logger.Warn("Failed to update statistics",
+			"error", err,
+			"user_id", user.ID,
+			"bank_id", reviewState.BankID,
+		)
+	}
+
+	// Update streak
+	err = b.statsService.UpdateStreak(user.ID)
+	if err != nil {
+		b.logger.Warn("Failed to update streak",
+			"error", err,
+			"user_id", user.ID,
+		)
+	}
+
+	// Show feedback based on rating
+	var feedbackText string
+	switch rating {
+	case spaced_repetition.QualityAgain:
+		feedbackText = "You'll see this card again soon."
+	case spaced_repetition.QualityHard:
+		feedbackText = "You'll see this card again in a short while."
+	case spaced_repetition.QualityGood:
+		feedbackText = "Good job! You'll see this card again later."
+	case spaced_repetition.QualityEasy:
+		feedbackText = "Excellent! You'll see this card again much later."
+	}
+
+	// Cards in learning steps come back within the day
+	review := answer.Review
+	if review.State == models.ReviewStateLearning || review.State == models.ReviewStateRelearning {
+		feedbackText = fmt.Sprintf("You'll see this card again in %s.", formatStep(time.Until(review.DueDate)))
+	}
+
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("✅ Card reviewed: *%s*\n\n%s%s", currentCard.Word, timingText, feedbackText))
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = b.createUndoKeyboard(answer.LogID)
+	b.api.Send(msg)
 
-		// Update streak
-		err = b.statsService.UpdateStreak(user.ID)
+	// Keep what is needed to undo the rating
+	reviewState.LastRating = &LastRating{
+		Answer:    *answer,
+		CardIndex: reviewState.CurrentCard,
+		Latency:   reviewState.Latency,
+	}
+
+	// Move to the next card or finish the review
+	reviewState.CurrentCard++
+	reviewState.IsFlipped = false
+
+	// Bring back learning cards whose step has expired
+	queued := len(reviewState.Cards)
+	b.requeueLearningCards(user, reviewState)
+	reviewState.LastRating.Requeued = len(reviewState.Cards) - queued
+
+	if reviewState.CurrentCard >= len(reviewState.Cards) {
+		// Review session completed, kept until the next one so the last rating can be undone
+		state.State = "review_finished"
+		b.setState(user.TelegramID, state)
+
+		// Get review stats
+		totalCards, dueCards, err := b.spacedRepService.GetReviewStats(user.ID)
 		if err != nil {
-			b.logger.Warn("Failed to update streak",
+			b.logger.Error("Failed to get review stats",
 				"error", err,
 				"user_id", user.ID,
 			)
+			b.sendMessage(chatID, "🎉 Review session completed!")
+			return
 		}
 
-		// Show feedback based on rating
-		var feedbackText string
-		switch rating {
-		case spaced_repetition.QualityAgain:
-			feedbackText = "You'll see this card again soon."
-		case spaced_repetition.QualityHard:
-			feedbackText = "You'll see this card again in a short while."
-		case spaced_repetition.QualityGood:
-			feedbackText = "Good job! You'll see this card again later."
-		case spaced_repetition.QualityEasy:
-			feedbackText = "Excellent! You'll see this card again much later."
-		}
+		b.sendMessage(chatID, fmt.Sprintf("🎉 Review session completed!\n\nYou've reviewed %d cards.\nYou have %d cards in total, with %d cards due for review.",
+			len(reviewState.Cards), totalCards, dueCards))
+		return
+	}
 
-		b.sendMessage(chatID, fmt.Sprintf("✅ Card reviewed: *%s*\n\n%s", currentCard.Word, feedbackText))
+	// Show the next card
+	b.setState(user.TelegramID, state)
+	b.showReviewCard(chatID, user, reviewState.Cards[reviewState.CurrentCard], reviewState.Mode, false)
+}
 
-		// Move to the next card or finish the review
-		reviewState.CurrentCard++
-		reviewState.IsFlipped = false
+// requeueLearningCards inserts learning cards whose step has expired after the current position
+// of the review queue. When the queue is exhausted, cards due within learnAheadLimit are shown early.
+func (b *Bot) requeueLearningCards(user *models.User, reviewState *ReviewState) {
+	until := time.Now()
+	if reviewState.CurrentCard >= len(reviewState.Cards) {
+		until = until.Add(learnAheadLimit)
+	}
 
-		if reviewState.CurrentCard >= len(reviewState.Cards) {
-			// Review session completed
-			delete(b.userStates, user.TelegramID)
+	cards, err := b.spacedRepService.GetDueLearningCards(user.ID, reviewState.BankID, until)
+	if err != nil {
+		b.logger.Warn("Failed to get due learning cards",
+			"error", err,
+			"user_id", user.ID,
+			"bank_id", reviewState.BankID,
+		)
+		return
+	}
 
-			// Get review stats
-			totalCards, dueCards, err := b.spacedRepService.GetReviewStats(user.ID)
-			if err != nil {
-				b.logger.Error("Failed to get review stats",
-					"error", err,
-					"user_id", user.ID,
-				)
-				b.sendMessage(chatID, "🎉 Review session completed!")
-				return
-			}
+	// Skip cards that are still waiting in the queue in the same direction
+	type queued struct {
+		id        int
+		direction string
+	}
+	pending := make(map[queued]bool)
+	for _, card := range reviewState.Cards[reviewState.CurrentCard:] {
+		pending[queued{card.ID, reviewDirection(card)}] = true
+	}
 
-			b.sendMessage(chatID, fmt.Sprintf("🎉 Review session completed!\n\nYou've reviewed %d cards.\nYou have %d cards in total, with %d cards due for review.",
-				len(reviewState.Cards), totalCards, dueCards))
-			return
+	var due []models.ReviewCard
+	for _, card := range filterReviewCards(cards, reviewState.Mode) {
+		if !pending[queued{card.ID, reviewDirection(card)}] {
+			due = append(due, card)
 		}
+	}
+
+	if len(due) == 0 {
+		return
+	}
+
+	queue := append([]models.ReviewCard{}, reviewState.Cards[:reviewState.CurrentCard]...)
+	queue = append(queue, due...)
+	reviewState.Cards = append(queue, reviewState.Cards[reviewState.CurrentCard:]...)
+}
 
-		// Show the next card
-		b.userStates[user.TelegramID] = state
-		b.showReviewCard(chatID, user, reviewState.Cards[reviewState.CurrentCard], false)
+// filterReviewCards returns the cards that can be reviewed in a mode.
+// Synonyms ask for the word, so they replace the definition of reverse cards.
+// Typed and quiz answers are words, so only reverse and cloze cards can be typed or chosen.
+func filterReviewCards(cards []models.ReviewCard, mode string) []models.ReviewCard {
+	if mode != reviewModeSynonyms && mode != reviewModeTyped && mode != reviewModeQuiz {
+		return cards
+	}
+
+	var filtered []models.ReviewCard
+	for _, card := range cards {
+		switch reviewDirection(card) {
+		case models.DirectionReverse:
+			if mode == reviewModeSynonyms && len(card.Synonyms) == 0 {
+				continue
+			}
+		case models.DirectionCloze:
+			if mode == reviewModeSynonyms {
+				continue
+			}
+		default:
+			continue
+		}
+		filtered = append(filtered, card)
 	}
+	return filtered
+}
+
+const (
+	// slowestCards is the number of cards listed by their answer time in /stats
+	slowestCards = 5
+
+	// latencyPageSize is the number of cards on a page of /stats times
+	latencyPageSize = 20
+)
+
+// formatLatency formats an answer time in milliseconds, e.g. "4.2 s"
+func formatLatency(ms int) string {
+	return fmt.Sprintf("%.1f s", float64(ms)/1000)
 }
 
 func (b *Bot) handleStatsCommand(update tgbotapi.Update, user *models.User, args string) {
 	chatID := update.Message.Chat.ID
 
+	// The answer time of every card is listed on its own pages
+	if strings.EqualFold(strings.TrimSpace(args), "times") {
+		b.showLatencyList(chatID, 0, user, 1)
+		return
+	}
+
 	// Get user's statistics
 	stats, err := b.statsService.GetUserStatistics(user.ID)
 	if err != nil {
@@ -942,13 +1379,96 @@ func (b *Bot) handleStatsCommand(update tgbotapi.Update, user *models.User, args
 		}
 	}
 
-	// Send statistics
-	msg := tgbotapi.NewMessage(chatID, statsText)
-	msg.ParseMode = "HTML"
-
-	b.api.Send(msg)
-}
-
+	// Answer times in the active bank
+	latency, err := b.spacedRepService.GetLatencyStats(user.ID, activeBankID, slowestCards, 0)
+	if err != nil {
+		b.logger.Warn("Failed to get latency stats",
+			"error", err,
+			"user_id", user.ID,
+			"bank_id", activeBankID,
+		)
+	} else if latency.Answers > 0 {
+		statsText += "*Answer Times (active bank):*\n"
+		statsText += fmt.Sprintf("Average: %s over %d answers\n", formatLatency(latency.AverageMs), latency.Answers)
+		statsText += fmt.Sprintf("Slowest %d of %d cards:\n", len(latency.Cards), latency.CardCount)
+		for _, card := range latency.Cards {
+			statsText += fmt.Sprintf("• %s - %s\n", card.Word, formatLatency(card.AverageMs))
+		}
+		statsText += "Send /stats times for the average answer time of every card.\n"
+	}
+
+	// Send statistics
+	msg := tgbotapi.NewMessage(chatID, statsText)
+	msg.ParseMode = "HTML"
+
+	b.api.Send(msg)
+}
+
+// showLatencyList shows a page of the average answer time of every answered card in the user's
+// active bank, slowest first, replacing the message with the given ID when paging
+func (b *Bot) showLatencyList(chatID int64, messageID int, user *models.User, page int) {
+	settings, err := b.settingsService.GetUserSettings(user.ID)
+	if err != nil {
+		b.logger.Error("Failed to get user settings",
+			"error", err,
+			"user_id", user.ID,
+		)
+		b.sendErrorMessage(chatID, "Failed to get your settings. Please try again.")
+		return
+	}
+
+	activeBankID := settings.Settings.ActiveCardBankID
+
+	latency, err := b.spacedRepService.GetLatencyStats(user.ID, activeBankID, latencyPageSize, (page-1)*latencyPageSize)
+	if err != nil {
+		b.logger.Error("Failed to get latency stats",
+			"error", err,
+			"user_id", user.ID,
+			"bank_id", activeBankID,
+		)
+		b.sendErrorMessage(chatID, "Failed to get your answer times. Please try again.")
+		return
+	}
+
+	totalPages := (latency.CardCount + latencyPageSize - 1) / latencyPageSize
+
+	listText := "⏱ *Average answer time per card (active bank)*\n"
+	switch {
+	case latency.CardCount == 0:
+		listText += "\nNo answer times yet. They are recorded as you review cards."
+	case len(latency.Cards) == 0:
+		listText += fmt.Sprintf("\nThere are only %d pages.", totalPages)
+	default:
+		first := (page-1)*latencyPageSize + 1
+		listText += fmt.Sprintf("Average: %s over %d answers\n", formatLatency(latency.AverageMs), latency.Answers)
+		listText += fmt.Sprintf("\nShowing %d–%d of %d, slowest first:\n", first, first+len(latency.Cards)-1, latency.CardCount)
+		for i, card := range latency.Cards {
+			listText += fmt.Sprintf("\n%d. *%s* – %s (%d answers)", first+i, html.EscapeString(card.Word), formatLatency(card.AverageMs), card.Answers)
+		}
+	}
+
+	keyboard := b.createLatencyListKeyboard(page, totalPages)
+
+	if messageID != 0 {
+		edit := tgbotapi.NewEditMessageTextAndMarkup(chatID, messageID, listText, keyboard)
+		edit.ParseMode = "HTML"
+		if _, err := b.api.Send(edit); err != nil {
+			b.logger.Warn("Failed to update answer time list",
+				"error", err,
+				"user_id", user.ID,
+			)
+		}
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chatID, listText)
+	msg.ParseMode = "HTML"
+	if totalPages > 1 {
+		msg.ReplyMarkup = keyboard
+	}
+
+	b.api.Send(msg)
+}
 func (b *Bot) handleBanksCommand(update tgbotapi.Update, user *models.User) {
 	chatID := update.Message.Chat.ID
 
@@ -1021,6 +1541,7 @@ func (b *Bot) handleBanksCommand(update tgbotapi.Update, user *models.User) {
 			if bank.Description != "" {
 				banksText += fmt.Sprintf("   %s\n", bank.Description)
 			}
+			banksText += fmt.Sprintf("   Languages: %s → %s\n", bank.SourceLanguage, bank.TargetLanguage)
 			banksText += fmt.Sprintf("   Cards: %d\n\n", b.countCardsInBank(bank.ID))
 		}
 	}
@@ -1112,9 +1633,9 @@ func (b *Bot) handleBankCallback(update tgbotapi.Update, user *models.User, args
 
 	case "create":
 		// User wants to create a new bank
-		b.userStates[user.TelegramID] = UserState{
+		b.setState(user.TelegramID, UserState{
 			State: "awaiting_bank_name",
-		}
+		})
 
 		b.sendMessage(chatID, "Please send the name for your new card bank.")
 	}
@@ -1125,9 +1646,9 @@ func (b *Bot) handleCreateBankCommand(update tgbotapi.Update, user *models.User,
 
 	// If no name provided in command, prompt user and set state
 	if args == "" {
-		b.userStates[user.TelegramID] = UserState{
+		b.setState(user.TelegramID, UserState{
 			State: "awaiting_bank_name",
-		}
+		})
 		b.sendMessage(chatID, "Please send the name for your new card bank.")
 		return
 	}
@@ -1173,7 +1694,7 @@ func (b *Bot) handleBankNameInput(update tgbotapi.Update, user *models.User, tex
 	}
 
 	// Clear user state
-	delete(b.userStates, user.TelegramID)
+	b.clearState(user.TelegramID)
 
 	// Set as active bank
 	err = b.settingsService.SetActiveCardBank(user.ID, bank.ID)
@@ -1188,6 +1709,87 @@ func (b *Bot) handleBankNameInput(update tgbotapi.Update, user *models.User, tex
 	b.sendMessage(chatID, fmt.Sprintf("Card bank \"%s\" created and set as active. You can now add words to this bank.", bank.Name))
 }
 
+func (b *Bot) handleLanguageCommand(update tgbotapi.Update, user *models.User, args string) {
+	chatID := update.Message.Chat.ID
+
+	// Get user's active card bank
+	settings, err := b.settingsService.GetUserSettings(user.ID)
+	if err != nil {
+		b.logger.Error("Failed to get user settings",
+			"error", err,
+			"user_id", user.ID,
+		)
+		b.sendErrorMessage(chatID, "Failed to get your settings. Please try again.")
+		return
+	}
+
+	activeBankID := settings.Settings.ActiveCardBankID
+
+	// Check if user has access to this bank
+	hasAccess, err := b.cardbankService.UserHasAccess(user.ID, activeBankID)
+	if err != nil || !hasAccess {
+		b.logger.Error("User doesn't have access to active bank",
+			"error", err,
+			"user_id", user.ID,
+			"bank_id", activeBankID,
+		)
+		b.sendErrorMessage(chatID, "You don't have access to your active card bank. Please select another bank using /banks.")
+		return
+	}
+
+	bank, err := b.cardbankService.GetCardBank(activeBankID)
+	if err != nil {
+		b.logger.Error("Failed to get bank",
+			"error", err,
+			"bank_id", activeBankID,
+		)
+		b.sendErrorMessage(chatID, "Failed to get your card bank. Please try again.")
+		return
+	}
+
+	// Without arguments show the current languages
+	fields := strings.Fields(strings.ToLower(args))
+	if len(fields) == 0 {
+		b.sendMessage(chatID, fmt.Sprintf("Words in \"%s\" are %s, defined in %s.\n\nTo change this, send /language [word language] [definition language] with two-letter codes, e.g. /language de en for German words with English definitions or /language es ru for Spanish words with Russian translations.", bank.Name, bank.SourceLanguage, bank.TargetLanguage))
+		return
+	}
+
+	// Viewers can't change the bank
+	if !b.checkCanEdit(chatID, user, activeBankID) {
+		return
+	}
+
+	// A single language changes the words and keeps the definition language
+	sourceLanguage, targetLanguage := fields[0], bank.TargetLanguage
+	if len(fields) > 1 {
+		targetLanguage = fields[1]
+	}
+
+	if !models.IsValidLanguage(sourceLanguage) || !models.IsValidLanguage(targetLanguage) {
+		b.sendErrorMessage(chatID, "Please use language codes such as en, de or es, e.g. /language de en")
+		return
+	}
+
+	if !b.flashcardService.SupportsLanguages(sourceLanguage, targetLanguage) {
+		b.sendErrorMessage(chatID, fmt.Sprintf("No dictionary supports %s → %s. Please choose another pair of languages.", sourceLanguage, targetLanguage))
+		return
+	}
+
+	bank, err = b.cardbankService.SetLanguages(bank.ID, sourceLanguage, targetLanguage)
+	if err != nil {
+		b.logger.Error("Failed to set bank languages",
+			"error", err,
+			"bank_id", activeBankID,
+			"source_language", sourceLanguage,
+			"target_language", targetLanguage,
+		)
+		b.sendErrorMessage(chatID, "Failed to change the languages of your card bank. Please try again.")
+		return
+	}
+
+	b.sendMessage(chatID, fmt.Sprintf("Words you add to \"%s\" are now looked up as %s and defined in %s.", bank.Name, bank.SourceLanguage, bank.TargetLanguage))
+}
+
 func (b *Bot) handleShareBankCommand(update tgbotapi.Update, user *models.User, args string) {
 	chatID := update.Message.Chat.ID
 
@@ -1291,7 +1893,7 @@ func (b *Bot) handleJoinBankCommand(update tgbotapi.Update, user *models.User, a
 	}
 
 	// Add user to bank with viewer role
-	err = b.cardbankService.AddUserToBank(user.ID, bankID, "viewer")
+	err = b.cardbankService.AddUserToBank(user.ID, bankID, models.RoleViewer)
 	if err != nil {
 		b.logger.Error("Failed to add user to bank",
 			"error", err,
@@ -1316,8 +1918,11 @@ func (b *Bot) handleJoinBankCommand(update tgbotapi.Update, user *models.User, a
 }
 
 func (b *Bot) handleSettingsCommand(update tgbotapi.Update, user *models.User) {
-	chatID := update.Message.Chat.ID
+	b.showSettings(update.Message.Chat.ID, user)
+}
 
+// showSettings shows the user's settings with the settings keyboard
+func (b *Bot) showSettings(chatID int64, user *models.User) {
 	// Get user's settings
 	settings, err := b.settingsService.GetUserSettings(user.ID)
 	if err != nil {
@@ -1342,6 +1947,23 @@ func (b *Bot) handleSettingsCommand(update tgbotapi.Update, user *models.User) {
 	settingsText := "⚙️ *Your Settings*\n\n"
 	settingsText += fmt.Sprintf("*Active Card Bank:* %s\n", activeBankName)
 	settingsText += fmt.Sprintf("*Review Limit:* %d cards per session\n", settings.Settings.ReviewLimit)
+	settingsText += fmt.Sprintf("*Algorithm:* %s\n", algorithmName(settings.Settings.Algorithm.Type))
+	settingsText += fmt.Sprintf("*Learning Steps:* %s\n", formatSteps(settings.Settings.LearningSteps, spaced_repetition.DefaultLearningSteps))
+	settingsText += fmt.Sprintf("*Relearning Steps:* %s\n", formatSteps(settings.Settings.RelearningSteps, spaced_repetition.DefaultRelearningSteps))
+
+	newLimit, reviewLimit := settings.Settings.DailyLimitsForBank(0)
+	settingsText += fmt.Sprintf("*Daily Limits:* %d new cards, %d reviews\n", newLimit, reviewLimit)
+	if _, ok := settings.Settings.BankDailyLimits[settings.Settings.ActiveCardBankID]; ok {
+		newLimit, reviewLimit := settings.Settings.DailyLimitsForBank(settings.Settings.ActiveCardBankID)
+		settingsText += fmt.Sprintf("*Active Bank Limits:* %d new cards, %d reviews\n", newLimit, reviewLimit)
+	}
+	settingsText += fmt.Sprintf("*Time Zone:* %s\n", settings.Settings.Location())
+
+	timedGradingStatus := "Off"
+	if settings.Settings.TimedGrading {
+		timedGradingStatus = "On"
+	}
+	settingsText += fmt.Sprintf("*Timed Grading:* %s\n", timedGradingStatus)
 
 	notificationsStatus := "Off"
 	if settings.Settings.NotificationsOn {
@@ -1391,13 +2013,60 @@ func (b *Bot) handleSettingsCallback(update tgbotapi.Update, user *models.User,
 
 	case "limit":
 		// Set review limit
-		b.userStates[user.TelegramID] = UserState{
+		b.setState(user.TelegramID, UserState{
 			State:         "awaiting_settings",
 			SettingsField: "review_limit",
-		}
+		})
 
 		b.sendMessage(chatID, "Please enter the number of cards you want to review per session (1-50):")
 
+	case "daily":
+		// Set daily limits for all banks
+		b.setState(user.TelegramID, UserState{
+			State:         "awaiting_settings",
+			SettingsField: "daily_limits",
+		})
+
+		b.sendMessage(chatID, "Please enter the number of new cards and reviews per day, separated by a space (e.g. \"20 200\", or \"0 200\" to only review):")
+
+	case "banklimits":
+		// Set daily limits for the active bank
+		if settings.Settings.ActiveCardBankID == 0 {
+			b.sendErrorMessage(chatID, "You don't have an active card bank. Please select one using /banks.")
+			return
+		}
+
+		b.setState(user.TelegramID, UserState{
+			State:         "awaiting_settings",
+			SettingsField: "bank_daily_limits",
+			CurrentBank:   settings.Settings.ActiveCardBankID,
+		})
+
+		b.sendMessage(chatID, "Please enter the number of new cards and reviews per day for your active bank, separated by a space (e.g. \"10 100\"). Send \"default\" to use your general limits.")
+
+	case "timezone":
+		// Set time zone
+		b.setState(user.TelegramID, UserState{
+			State:         "awaiting_settings",
+			SettingsField: "timezone",
+		})
+
+		b.sendMessage(chatID, "Please enter your time zone (e.g. \"Europe/Berlin\" or \"America/New_York\"). Daily limits reset at midnight in this time zone.")
+
+	case "steps", "relearning":
+		// Set learning or relearning steps
+		field := "learning_steps"
+		if action == "relearning" {
+			field = "relearning_steps"
+		}
+
+		b.setState(user.TelegramID, UserState{
+			State:         "awaiting_settings",
+			SettingsField: field,
+		})
+
+		b.sendMessage(chatID, "Please enter the steps separated by spaces, in minutes (m) or hours (h) within a day, e.g. \"1m 10m\". Send \"none\" to skip the steps.")
+
 	case "notifications":
 		// Toggle notifications
 		settings.Settings.NotificationsOn = !settings.Settings.NotificationsOn
@@ -1420,7 +2089,31 @@ func (b *Bot) handleSettingsCallback(update tgbotapi.Update, user *models.User,
 		b.sendMessage(chatID, fmt.Sprintf("Notifications %s.", status))
 
 		// Show updated settings
-		b.handleSettingsCommand(update, user)
+		b.showSettings(chatID, user)
+
+	case "timed":
+		// Toggle grading by answer time
+		settings.Settings.TimedGrading = !settings.Settings.TimedGrading
+
+		err = b.settingsService.UpdateUserSettings(user.ID, settings.Settings)
+		if err != nil {
+			b.logger.Error("Failed to update settings",
+				"error", err,
+				"user_id", user.ID,
+			)
+			b.sendErrorMessage(chatID, "Failed to update settings. Please try again.")
+			return
+		}
+
+		if settings.Settings.TimedGrading {
+			b.sendMessage(chatID, fmt.Sprintf("Timed grading enabled. Cards you rate Good within %d seconds are rated Easy, and Good or Easy answers that take longer than %d seconds are rated Hard.",
+				int(spaced_repetition.FastAnswer.Seconds()), int(spaced_repetition.SlowAnswer.Seconds())))
+		} else {
+			b.sendMessage(chatID, "Timed grading disabled.")
+		}
+
+		// Show updated settings
+		b.showSettings(chatID, user)
 
 	case "darkmode":
 		// Toggle dark mode
@@ -1444,15 +2137,110 @@ func (b *Bot) handleSettingsCallback(update tgbotapi.Update, user *models.User,
 		b.sendMessage(chatID, fmt.Sprintf("Dark mode %s.", status))
 
 		// Show updated settings
-		b.handleSettingsCommand(update, user)
+		b.showSettings(chatID, user)
+
+	case "algo":
+		if len(args) < 2 {
+			// Show algorithm choice
+			msg := tgbotapi.NewMessage(chatID, "🧠 *Spaced Repetition Algorithm*\n\nSM-2 is the classic Anki algorithm, Custom lets you tune its intervals and ease, FSRS models the memory of every card.\n\nPlease select the algorithm you want to use:")
+			msg.ParseMode = "HTML"
+			msg.ReplyMarkup = b.createAlgorithmKeyboard(settings.Settings.Algorithm)
+			b.api.Send(msg)
+			return
+		}
+
+		algorithmType := args[1]
+		if _, err := spaced_repetition.CreateAlgorithm(models.AlgorithmConfig{Type: algorithmType}); err != nil {
+			b.logger.Error("Invalid algorithm type", "type", algorithmType)
+			return
+		}
+
+		// Parameters differ between algorithms, so start from the defaults
+		if algorithmType != settings.Settings.Algorithm.Type {
+			settings.Settings.Algorithm = models.AlgorithmConfig{Type: algorithmType}
+		}
+
+		err = b.settingsService.UpdateUserSettings(user.ID, settings.Settings)
+		if err != nil {
+			b.logger.Error("Failed to update settings",
+				"error", err,
+				"user_id", user.ID,
+			)
+			b.sendErrorMessage(chatID, "Failed to update settings. Please try again.")
+			return
+		}
+
+		b.sendMessage(chatID, fmt.Sprintf("Algorithm set to %s. It will be used for your next reviews.", algorithmName(algorithmType)))
+
+		// Offer parameter tuning for tunable algorithms
+		if len(algorithmParameters[algorithmType]) > 0 {
+			b.showAlgorithmParams(chatID, settings.Settings.Algorithm)
+			return
+		}
+
+		// Show updated settings
+		b.showSettings(chatID, user)
+
+	case "params":
+		if len(args) >= 2 && args[1] == "reset" {
+			// Reset parameters to the algorithm defaults
+			settings.Settings.Algorithm.Parameters = nil
+
+			err = b.settingsService.UpdateUserSettings(user.ID, settings.Settings)
+			if err != nil {
+				b.logger.Error("Failed to update settings",
+					"error", err,
+					"user_id", user.ID,
+				)
+				b.sendErrorMessage(chatID, "Failed to update settings. Please try again.")
+				return
+			}
+
+			b.sendMessage(chatID, "Algorithm parameters reset to defaults.")
+		}
+
+		b.showAlgorithmParams(chatID, settings.Settings.Algorithm)
+
+	case "param":
+		if len(args) < 2 {
+			b.logger.Error("Invalid parameter settings data", "args", args)
+			return
+		}
+
+		param, ok := findAlgorithmParameter(settings.Settings.Algorithm.Type, args[1])
+		if !ok {
+			b.logger.Error("Unknown algorithm parameter",
+				"type", settings.Settings.Algorithm.Type,
+				"param", args[1],
+			)
+			return
+		}
+
+		b.setState(user.TelegramID, UserState{
+			State:         "awaiting_settings",
+			SettingsField: "algo:" + param.Name,
+		})
+
+		b.sendMessage(chatID, param.Prompt)
 	}
 }
 
+// showAlgorithmParams shows the tunable parameters of the user's algorithm
+func (b *Bot) showAlgorithmParams(chatID int64, config models.AlgorithmConfig) {
+	text := fmt.Sprintf("⚙️ *%s Parameters*\n\nSelect the parameter you want to change:", algorithmName(config.Type))
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = b.createAlgorithmParamsKeyboard(config)
+
+	b.api.Send(msg)
+}
+
 func (b *Bot) handleSettingsInput(update tgbotapi.Update, user *models.User, text string) {
 	chatID := update.Message.Chat.ID
 
 	// Get user state
-	state, exists := b.userStates[user.TelegramID]
+	state, exists := b.getState(user.TelegramID)
 	if !exists || state.State != "awaiting_settings" {
 		b.sendErrorMessage(chatID, "Session expired. Please start again with /settings.")
 		return
@@ -1493,22 +2281,175 @@ func (b *Bot) handleSettingsInput(update tgbotapi.Update, user *models.User, tex
 		}
 
 		// Clear user state
-		delete(b.userStates, user.TelegramID)
+		b.clearState(user.TelegramID)
 
 		b.sendMessage(chatID, fmt.Sprintf("Review limit set to %d cards per session.", limit))
 
 		// Show updated settings
-		msg := update.Message
-		update.Message = nil
-		update.CallbackQuery = &tgbotapi.CallbackQuery{
-			Message: msg,
+		b.showSettings(chatID, user)
+
+	case "daily_limits", "bank_daily_limits":
+		var limits models.DailyLimits
+
+		// "default" removes the bank override
+		if state.SettingsField == "bank_daily_limits" && strings.EqualFold(strings.TrimSpace(text), "default") {
+			delete(settings.Settings.BankDailyLimits, state.CurrentBank)
+		} else {
+			// Parse limits
+			limits, err = parseDailyLimits(text)
+			if err != nil {
+				b.sendErrorMessage(chatID, fmt.Sprintf("Please enter two numbers, new cards (0-%d) and reviews (0-%d), e.g. \"20 200\" or \"0 200\" for reviews only.", maxDailyLimit, maxDailyLimit))
+				return
+			}
+
+			if state.SettingsField == "daily_limits" {
+				settings.Settings.DailyLimits = limits
+			} else {
+				if settings.Settings.BankDailyLimits == nil {
+					settings.Settings.BankDailyLimits = make(map[int]models.DailyLimits)
+				}
+				settings.Settings.BankDailyLimits[state.CurrentBank] = limits
+			}
 		}
-		b.handleSettingsCommand(update, user)
+
+		// Update settings
+		err = b.settingsService.UpdateUserSettings(user.ID, settings.Settings)
+		if err != nil {
+			b.logger.Error("Failed to update settings",
+				"error", err,
+				"user_id", user.ID,
+			)
+			b.sendErrorMessage(chatID, "Failed to update settings. Please try again.")
+			return
+		}
+
+		// Clear user state
+		b.clearState(user.TelegramID)
+
+		b.sendMessage(chatID, "Daily limits updated.")
+
+		// Show updated settings
+		b.showSettings(chatID, user)
+
+	case "timezone":
+		// Validate time zone
+		timezone := strings.TrimSpace(text)
+		if _, err := time.LoadLocation(timezone); err != nil || timezone == "" || strings.EqualFold(timezone, "local") {
+			b.sendErrorMessage(chatID, "Unknown time zone. Please use a name such as \"Europe/Berlin\" or \"UTC\".")
+			return
+		}
+
+		// Update settings
+		settings.Settings.Timezone = timezone
+
+		err = b.settingsService.UpdateUserSettings(user.ID, settings.Settings)
+		if err != nil {
+			b.logger.Error("Failed to update settings",
+				"error", err,
+				"user_id", user.ID,
+			)
+			b.sendErrorMessage(chatID, "Failed to update settings. Please try again.")
+			return
+		}
+
+		// Clear user state
+		b.clearState(user.TelegramID)
+
+		b.sendMessage(chatID, fmt.Sprintf("Time zone set to %s.", timezone))
+
+		// Show updated settings
+		b.showSettings(chatID, user)
+
+	case "learning_steps", "relearning_steps":
+		// Parse steps
+		steps, err := parseSteps(text)
+		if err != nil {
+			b.sendErrorMessage(chatID, "Please enter steps such as \"1m 10m\" (each up to 24h), or \"none\".")
+			return
+		}
+
+		// Update settings
+		if state.SettingsField == "learning_steps" {
+			settings.Settings.LearningSteps = steps
+		} else {
+			settings.Settings.RelearningSteps = steps
+		}
+
+		err = b.settingsService.UpdateUserSettings(user.ID, settings.Settings)
+		if err != nil {
+			b.logger.Error("Failed to update settings",
+				"error", err,
+				"user_id", user.ID,
+			)
+			b.sendErrorMessage(chatID, "Failed to update settings. Please try again.")
+			return
+		}
+
+		// Clear user state
+		b.clearState(user.TelegramID)
+
+		b.sendMessage(chatID, fmt.Sprintf("Steps set to %s.", formatSteps(steps, nil)))
+
+		// Show updated settings
+		b.showSettings(chatID, user)
+
+	default:
+		if !strings.HasPrefix(state.SettingsField, "algo:") {
+			return
+		}
+
+		// Algorithm parameter
+		name := strings.TrimPrefix(state.SettingsField, "algo:")
+		value, err := parseAlgorithmParameter(name, text)
+		if err != nil {
+			b.sendErrorMessage(chatID, "Invalid value. "+b.algorithmParameterPrompt(settings.Settings.Algorithm.Type, name))
+			return
+		}
+
+		// Apply the value to a copy of the configuration and validate it
+		config := models.AlgorithmConfig{
+			Type:       settings.Settings.Algorithm.Type,
+			Parameters: make(map[string]interface{}),
+		}
+		for k, v := range settings.Settings.Algorithm.Parameters {
+			config.Parameters[k] = v
+		}
+		config.Parameters[name] = value
+
+		if _, err := spaced_repetition.CreateAlgorithm(config); err != nil {
+			b.sendErrorMessage(chatID, "Invalid value. "+b.algorithmParameterPrompt(config.Type, name))
+			return
+		}
+
+		settings.Settings.Algorithm = config
+
+		err = b.settingsService.UpdateUserSettings(user.ID, settings.Settings)
+		if err != nil {
+			b.logger.Error("Failed to update settings",
+				"error", err,
+				"user_id", user.ID,
+			)
+			b.sendErrorMessage(chatID, "Failed to update settings. Please try again.")
+			return
+		}
+
+		// Clear user state
+		b.clearState(user.TelegramID)
+
+		b.sendMessage(chatID, "Algorithm parameter updated.")
+
+		// Show updated parameters
+		b.showAlgorithmParams(chatID, config)
 	}
 }
 
-func (b *Bot) handlePaginationCallback(update tgbotapi.Update, user *models.User, args []string) {
-	// Placeholder implementation
+// algorithmParameterPrompt returns the input prompt for an algorithm parameter
+func (b *Bot) algorithmParameterPrompt(algorithmType, name string) string {
+	param, ok := findAlgorithmParameter(algorithmType, name)
+	if !ok {
+		return "Please try again with /settings."
+	}
+	return param.Prompt
 }
 
 func (b *Bot) handleAdminCommand(update tgbotapi.Update, user *models.User, args string) {
@@ -1522,12 +2463,16 @@ func (b *Bot) handleAdminInput(update tgbotapi.Update, user *models.User, text s
 }
 
 // showReviewCard shows a flash card for review
-func (b *Bot) showReviewCard(chatID int64, user *models.User, card models.FlashCard, isFlipped bool) {
+func (b *Bot) showReviewCard(chatID int64, user *models.User, card models.ReviewCard, mode string, isFlipped bool) {
 	var text string
 
 	if isFlipped {
 		// Show the word (answer)
-		text = fmt.Sprintf("📝 *%s*\n\n*Definition:*\n%s", card.Word, card.Definition)
+		text = fmt.Sprintf("📝 *%s*", card.Word)
+		if card.Phonetic != "" {
+			text += " " + card.Phonetic
+		}
+		text += "\n\n*Definition:*\n" + card.Definition
 
 		if len(card.Examples) > 0 {
 			text += "\n\n*Examples:*"
@@ -1536,7 +2481,29 @@ func (b *Bot) showReviewCard(chatID int64, user *models.User, card models.FlashC
 			}
 		}
 
-		text += "\n\nHow well did you remember this word?"
+		text += relatedWordsText(card.FlashCard)
+
+		if reviewDirection(card) == models.DirectionForward {
+			text += "\n\nHow well did you remember the meaning?"
+		} else {
+			text += "\n\nHow well did you remember this word?"
+		}
+	} else if reviewDirection(card) == models.DirectionCloze {
+		// Show an example without the word (question)
+		text = clozeText(card.FlashCard)
+		if mode == reviewModeTyped {
+			text += "\n\n✍️ Type the missing word, or flip the card if you don't know it."
+		}
+	} else if reviewDirection(card) == models.DirectionForward {
+		// Show the word (question)
+		text = fmt.Sprintf("📝 *%s*", card.Word)
+		if card.Phonetic != "" {
+			text += " " + card.Phonetic
+		}
+		text += "\n\nWhat does it mean?"
+	} else if mode == reviewModeSynonyms {
+		// Show the synonyms (question)
+		text = fmt.Sprintf("🔗 *Synonyms:*\n%s\n\nWhich word means the same?", strings.Join(card.Synonyms, ", "))
 	} else {
 		// Show the definition and examples (question)
 		text = fmt.Sprintf("*Definition:*\n%s", card.Definition)
@@ -1544,14 +2511,50 @@ func (b *Bot) showReviewCard(chatID int64, user *models.User, card models.FlashC
 		if len(card.Examples) > 0 {
 			text += "\n\n*Examples:*"
 			for i, ex := range card.Examples {
+				// The examples mustn't give away the word to type or choose
+				if mode == reviewModeTyped || mode == reviewModeQuiz {
+					ex = maskWord(ex, card.Word)
+				}
 				text += fmt.Sprintf("\n%d. %s", i+1, ex)
 			}
 		}
+
+		if mode == reviewModeTyped {
+			text += "\n\n✍️ Type the word, or flip the card if you don't know it."
+		}
 	}
 
 	msg := tgbotapi.NewMessage(chatID, text)
 	msg.ParseMode = "HTML"
-	msg.ReplyMarkup = b.createReviewKeyboard(isFlipped)
+
+	// The time to answer starts when the question is shown
+	if !isFlipped {
+		b.markCardShown(user)
+	}
+
+	// Quiz questions are answered by choosing the word out of the bank's other words
+	if !isFlipped && mode == reviewModeQuiz && reviewDirection(card) != models.DirectionForward {
+		if options := b.quizOptions(card); len(options) > 1 {
+			msg.Text += "\n\n❓ Which word is it?"
+			msg.ReplyMarkup = b.createQuizKeyboard(card.ID, options)
+			b.api.Send(msg)
+			return
+		}
+	}
+
+	canEdit := false
+	if isFlipped {
+		var err error
+		canEdit, err = b.cardbankService.UserCanEdit(user.ID, card.CardBankID)
+		if err != nil {
+			b.logger.Warn("Failed to check bank permissions",
+				"error", err,
+				"user_id", user.ID,
+				"bank_id", card.CardBankID,
+			)
+		}
+	}
+	msg.ReplyMarkup = b.createReviewKeyboard(card.ID, reviewDirection(card), isFlipped, canEdit)
 
 	b.api.Send(msg)
 
@@ -1561,4 +2564,62 @@ func (b *Bot) showReviewCard(chatID int64, user *models.User, card models.FlashC
 		photo.Caption = "Context image for: " + card.Word
 		b.api.Send(photo)
 	}
+
+	if isFlipped {
+		b.sendPronunciation(chatID, &card.FlashCard)
+	}
+}
+
+// markCardShown records when the front of the current card of a review session was shown
+func (b *Bot) markCardShown(user *models.User) {
+	state, exists := b.getState(user.TelegramID)
+	if !exists || state.ReviewState == nil {
+		return
+	}
+
+	state.ReviewState.ShownAt = time.Now()
+	state.ReviewState.Latency = 0
+	b.setState(user.TelegramID, state)
+}
+
+// answerLatency returns how long the current card of a review session has been shown, 0 if unknown
+func answerLatency(reviewState *ReviewState) time.Duration {
+	if reviewState.ShownAt.IsZero() {
+		return 0
+	}
+	return time.Since(reviewState.ShownAt)
+}
+
+// ratingName names a rating as its button does
+func ratingName(rating int) string {
+	switch rating {
+	case spaced_repetition.QualityAgain:
+		return "Again"
+	case spaced_repetition.QualityHard:
+		return "Hard"
+	case spaced_repetition.QualityEasy:
+		return "Easy"
+	default:
+		return "Good"
+	}
+}
+
+// reviewDirection returns the direction a card is reviewed in
+func reviewDirection(card models.ReviewCard) string {
+	if card.Direction == "" {
+		return models.DirectionReverse
+	}
+	return card.Direction
+}
+
+// relatedWordsText formats the synonyms and antonyms of a card
+func relatedWordsText(card models.FlashCard) string {
+	var text string
+	if len(card.Synonyms) > 0 {
+		text += "\n\n*Synonyms:* " + strings.Join(card.Synonyms, ", ")
+	}
+	if len(card.Antonyms) > 0 {
+		text += "\n\n*Antonyms:* " + strings.Join(card.Antonyms, ", ")
+	}
+	return text
 }
@@ -0,0 +1,366 @@
+package telegram
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/supercakecrumb/flash-cards-language-tg-bot/internal/domain/models"
+	"github.com/supercakecrumb/flash-cards-language-tg-bot/internal/domain/services"
+	"github.com/supercakecrumb/flash-cards-language-tg-bot/internal/infrastructure/exporter"
+	"github.com/supercakecrumb/flash-cards-language-tg-bot/internal/infrastructure/importer"
+)
+
+const (
+	// maxImportFileSize is the largest file the Bot API lets bots download
+	maxImportFileSize = 20 << 20
+
+	// importPreviewSize is how many cards and errors the preview lists
+	importPreviewSize = 5
+)
+
+// ImportState represents an uploaded file waiting to be imported
+type ImportState struct {
+	FileName string
+	Rows     [][]string // raw table rows, only for CSV and TSV files
+	Mapping  importer.ColumnMapping
+	Records  []importer.Record
+}
+
+func (b *Bot) handleImportCommand(update tgbotapi.Update, user *models.User) {
+	chatID := update.Message.Chat.ID
+
+	// Get user's active card bank
+	settings, err := b.settingsService.GetUserSettings(user.ID)
+	if err != nil {
+		b.logger.Error("Failed to get user settings",
+			"error", err,
+			"user_id", user.ID,
+		)
+		b.sendErrorMessage(chatID, "Failed to get your settings. Please try again.")
+		return
+	}
+
+	activeBankID := settings.Settings.ActiveCardBankID
+
+	// Check if user has access to this bank
+	hasAccess, err := b.cardbankService.UserHasAccess(user.ID, activeBankID)
+	if err != nil || !hasAccess {
+		b.logger.Error("User doesn't have access to active bank",
+			"error", err,
+			"user_id", user.ID,
+			"bank_id", activeBankID,
+		)
+		b.sendErrorMessage(chatID, "You don't have access to your active card bank. Please select another bank using /banks.")
+		return
+	}
+
+	// Viewers can't add cards
+	if !b.checkCanEdit(chatID, user, activeBankID) {
+		return
+	}
+
+	bankName := "your active bank"
+	if bank, err := b.cardbankService.GetCardBank(activeBankID); err == nil {
+		bankName = fmt.Sprintf("\"%s\"", html.EscapeString(bank.Name))
+	}
+
+	b.setState(user.TelegramID, UserState{
+		State:       "awaiting_import_file",
+		CurrentBank: activeBankID,
+	})
+
+	b.sendMessage(chatID, fmt.Sprintf("📥 Send me a file to import into %s:\n\n"+
+		"• CSV or TSV with a word and a definition column, optionally followed by example columns\n"+
+		"• An Anki package (.apkg)\n"+
+		"• A JSON file made with /export\n\n"+
+		"You'll see a preview before anything is imported.", bankName))
+}
+
+// Document handler
+func (b *Bot) handleDocument(update tgbotapi.Update) {
+	chatID := update.Message.Chat.ID
+	doc := update.Message.Document
+
+	// Ensure user exists in our system
+	user, err := b.ensureUser(update.Message.From)
+	if err != nil {
+		b.logger.Error("Failed to ensure user exists in document handler",
+			"error", err,
+			"user_id", update.Message.From.ID,
+		)
+		b.sendErrorMessage(chatID, "Internal error. Please try again later.")
+		return
+	}
+
+	// Only process documents if we're expecting an import file
+	state, exists := b.getState(user.TelegramID)
+	if !exists || state.State != "awaiting_import_file" {
+		b.sendMessage(chatID, "To import cards from a file, use /import first.")
+		return
+	}
+
+	format, err := importer.DetectFormat(doc.FileName)
+	if err != nil {
+		b.sendErrorMessage(chatID, "Unsupported file type. Please send a .csv, .tsv, .apkg or .json file.")
+		return
+	}
+
+	if doc.FileSize > maxImportFileSize {
+		b.sendErrorMessage(chatID, "This file is too large. Files up to 20 MB can be imported.")
+		return
+	}
+
+	data, err := b.downloadFile(doc.FileID)
+	if err != nil {
+		b.logger.Error("Failed to download import file",
+			"error", err,
+			"user_id", user.ID,
+			"file_name", doc.FileName,
+		)
+		b.sendErrorMessage(chatID, "Failed to download the file. Please try again.")
+		return
+	}
+
+	importState := &ImportState{
+		FileName: doc.FileName,
+	}
+
+	switch format {
+	case importer.FormatAPKG:
+		importState.Records, err = importer.ParseAPKG(data)
+	case importer.FormatJSON:
+		importState.Records, err = importer.ParseJSON(data)
+	default:
+		importState.Rows, err = importer.ParseTable(data, format)
+		if err == nil {
+			importState.Mapping = importer.DetectColumnMapping(importState.Rows)
+			importState.Records = importer.MapRecords(importState.Rows, importState.Mapping)
+		}
+	}
+	if err != nil {
+		b.logger.Warn("Failed to parse import file",
+			"error", err,
+			"user_id", user.ID,
+			"file_name", doc.FileName,
+		)
+		b.sendErrorMessage(chatID, importErrorMessage(err))
+		return
+	}
+
+	for i := range importState.Records {
+		importState.Records[i].ImageURL = b.resolveImageReference(importState.Records[i].ImageURL)
+	}
+
+	state.State = "import_preview"
+	state.Import = importState
+	b.setState(user.TelegramID, state)
+
+	b.showImportPreview(chatID, user, state)
+}
+
+// resolveImageReference turns an exported reference to a Telegram file back into a file link
+func (b *Bot) resolveImageReference(ref string) string {
+	if path, ok := strings.CutPrefix(ref, exporter.TelegramFilePrefix); ok {
+		return fmt.Sprintf(tgbotapi.FileEndpoint, b.api.Token, path)
+	}
+	return ref
+}
+
+// downloadFile downloads a file sent to the bot
+func (b *Bot) downloadFile(fileID string) ([]byte, error) {
+	url, err := b.api.GetFileDirectURL(fileID)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &http.Client{Timeout: 60 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	return io.ReadAll(io.LimitReader(resp.Body, maxImportFileSize))
+}
+
+// importErrorMessage returns a user-facing message for a parse error
+func importErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, importer.ErrEmptyFile):
+		return "The file doesn't contain any cards."
+	case errors.Is(err, importer.ErrUnsupportedDeck):
+		return "This file was exported by a newer version of the bot and can't be imported."
+	case errors.Is(err, importer.ErrUnsupportedPackage):
+		return "This Anki package can't be read. Please export it again with \"Support older Anki versions\" enabled."
+	default:
+		return "Failed to read the file. Please check its format and try again."
+	}
+}
+
+// showImportPreview shows a dry run of the import with buttons to confirm or adjust it
+func (b *Bot) showImportPreview(chatID int64, user *models.User, state UserState) {
+	result, err := b.importService.PreviewImport(state.CurrentBank, state.Import.Records)
+	if err != nil {
+		b.logger.Error("Failed to preview import",
+			"error", err,
+			"user_id", user.ID,
+			"bank_id", state.CurrentBank,
+		)
+		b.sendErrorMessage(chatID, "Failed to prepare the import. Please try again.")
+		return
+	}
+
+	text := fmt.Sprintf("📋 *Import Preview: %s*\n\n", html.EscapeString(state.Import.FileName))
+	if state.Import.Rows != nil {
+		text += fmt.Sprintf("*Columns:* %s\n\n", state.Import.Mapping)
+	}
+
+	text += fmt.Sprintf("*New cards:* %d\n", len(result.Cards))
+	text += fmt.Sprintf("*Duplicates (skipped):* %d\n", len(result.Skipped))
+	text += fmt.Sprintf("*Invalid rows:* %d\n", len(result.Failed))
+
+	if len(result.Cards) > 0 {
+		text += "\n*First cards:*\n"
+		for i, card := range result.Cards {
+			if i >= importPreviewSize {
+				text += fmt.Sprintf("…and %d more\n", len(result.Cards)-importPreviewSize)
+				break
+			}
+			text += fmt.Sprintf("• %s — %s\n", html.EscapeString(card.Word), html.EscapeString(truncate(card.Definition, 80)))
+		}
+	}
+
+	text += formatImportErrors(result.Failed)
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = b.createImportPreviewKeyboard(len(result.Cards), state.Import.Rows != nil)
+
+	b.api.Send(msg)
+}
+
+func (b *Bot) handleImportCallback(update tgbotapi.Update, user *models.User, args []string) {
+	chatID := update.CallbackQuery.Message.Chat.ID
+
+	state, exists := b.getState(user.TelegramID)
+	if !exists || state.Import == nil {
+		b.sendMessage(chatID, "This import has expired. Use /import to start again.")
+		return
+	}
+
+	switch args[0] {
+	case "confirm":
+		result, err := b.importService.ImportCards(user.ID, state.CurrentBank, state.Import.Records)
+		if err != nil {
+			b.logger.Error("Failed to import cards",
+				"error", err,
+				"user_id", user.ID,
+				"bank_id", state.CurrentBank,
+			)
+			if result != nil && result.Imported > 0 {
+				b.sendErrorMessage(chatID, fmt.Sprintf("The import stopped after %d cards. Send the file again to import the rest, cards that already exist will be skipped.", result.Imported))
+			} else {
+				b.sendErrorMessage(chatID, "Failed to import cards. Please try again.")
+			}
+			b.clearState(user.TelegramID)
+			return
+		}
+
+		// Clear user state
+		b.clearState(user.TelegramID)
+
+		text := "✅ *Import finished*\n\n"
+		text += fmt.Sprintf("*Imported:* %d\n", result.Imported)
+		text += fmt.Sprintf("*Skipped (duplicates):* %d\n", len(result.Skipped))
+		text += fmt.Sprintf("*Failed:* %d\n", len(result.Failed))
+		text += formatImportErrors(result.Failed)
+		text += "\nUse /review to start learning the new cards."
+
+		b.sendMessage(chatID, text)
+
+	case "columns":
+		if state.Import.Rows == nil {
+			return
+		}
+
+		state.State = "awaiting_import_columns"
+		b.setState(user.TelegramID, state)
+
+		text := "Please send the column mapping with column numbers starting at 1, for example:\n\n"
+		text += "word=1 definition=2 examples=3,4 header=yes\n\n"
+		text += fmt.Sprintf("*Current:* %s\n", state.Import.Mapping)
+		if len(state.Import.Rows) > 0 {
+			text += "*First row:*\n"
+			for i, value := range state.Import.Rows[0] {
+				text += fmt.Sprintf("%d. %s\n", i+1, html.EscapeString(truncate(value, 40)))
+			}
+		}
+
+		b.sendMessage(chatID, text)
+
+	case "cancel":
+		b.clearState(user.TelegramID)
+		b.sendMessage(chatID, "Import cancelled.")
+	}
+}
+
+func (b *Bot) handleImportColumnsInput(update tgbotapi.Update, user *models.User, text string) {
+	chatID := update.Message.Chat.ID
+
+	state, _ := b.getState(user.TelegramID)
+	if state.Import == nil {
+		b.clearState(user.TelegramID)
+		b.sendMessage(chatID, "This import has expired. Use /import to start again.")
+		return
+	}
+
+	mapping, err := importer.ParseColumnMapping(text)
+	if err != nil {
+		b.sendErrorMessage(chatID, "Invalid column mapping. Please use a format like: word=1 definition=2 examples=3 header=yes")
+		return
+	}
+
+	state.State = "import_preview"
+	state.Import.Mapping = mapping
+	state.Import.Records = importer.MapRecords(state.Import.Rows, mapping)
+	b.setState(user.TelegramID, state)
+
+	b.showImportPreview(chatID, user, state)
+}
+
+// formatImportErrors lists the first invalid rows of an import
+func formatImportErrors(failed []services.ImportRowError) string {
+	if len(failed) == 0 {
+		return ""
+	}
+
+	text := "\n*Invalid rows:*\n"
+	for i, rowErr := range failed {
+		if i >= importPreviewSize {
+			text += fmt.Sprintf("…and %d more\n", len(failed)-importPreviewSize)
+			break
+		}
+		text += fmt.Sprintf("• Row %d: %s\n", rowErr.Row, html.EscapeString(rowErr.Reason))
+	}
+
+	return text
+}
+
+// truncate shortens a text to at most limit runes
+func truncate(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit-1]) + "…"
+}
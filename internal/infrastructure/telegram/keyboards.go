@@ -2,10 +2,12 @@ package telegram
 
 import (
 	"fmt"
+	"strings"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/supercakecrumb/flash-cards-language-tg-bot/internal/domain/models"
 	"github.com/supercakecrumb/flash-cards-language-tg-bot/internal/domain/services"
+	"github.com/supercakecrumb/flash-cards-language-tg-bot/pkg/spaced_repetition"
 )
 
 const (
@@ -24,9 +26,12 @@ func (b *Bot) createDefinitionsKeyboard(word string, definitions []services.Defi
 
 		// Truncate definition if too long
 		defText := def.Text
+		if def.PartOfSpeech != "" {
+			defText = fmt.Sprintf("%s (%s)", defText, def.PartOfSpeech)
+		}
 
 		button := tgbotapi.NewInlineKeyboardButtonData(
-			fmt.Sprintf("%d. %s (%s)", i+1, defText, def.PartOfSpeech),
+			fmt.Sprintf("%d. %s", i+1, defText),
 			fmt.Sprintf("def:%s", def.ID),
 		)
 
@@ -36,9 +41,11 @@ func (b *Bot) createDefinitionsKeyboard(word string, definitions []services.Defi
 	return tgbotapi.NewInlineKeyboardMarkup(rows...)
 }
 
-// createExamplesKeyboard creates an inline keyboard with examples
-func (b *Bot) createExamplesKeyboard(examples []services.Example, selectedExamples []string) tgbotapi.InlineKeyboardMarkup {
+// createExamplesKeyboard creates an inline keyboard with examples, and toggles for the synonyms
+// and antonyms of the selected definition
+func (b *Bot) createExamplesKeyboard(examples []services.Example, state UserState) tgbotapi.InlineKeyboardMarkup {
 	var rows [][]tgbotapi.InlineKeyboardButton
+	selectedExamples := state.Examples
 
 	for i, ex := range examples {
 		if i >= 5 {
@@ -74,6 +81,24 @@ func (b *Bot) createExamplesKeyboard(examples []services.Example, selectedExampl
 		rows = append(rows, []tgbotapi.InlineKeyboardButton{button})
 	}
 
+	// Add toggles for related words
+	if def := state.Definition; def != nil && (len(def.Synonyms) > 0 || len(def.Antonyms) > 0) {
+		var toggleRow []tgbotapi.InlineKeyboardButton
+		if len(def.Synonyms) > 0 {
+			toggleRow = append(toggleRow, tgbotapi.NewInlineKeyboardButtonData(
+				toggleLabel(fmt.Sprintf("Synonyms (%d)", len(def.Synonyms)), state.IncludeSynonyms),
+				"ex:synonyms",
+			))
+		}
+		if len(def.Antonyms) > 0 {
+			toggleRow = append(toggleRow, tgbotapi.NewInlineKeyboardButtonData(
+				toggleLabel(fmt.Sprintf("Antonyms (%d)", len(def.Antonyms)), state.IncludeAntonyms),
+				"ex:antonyms",
+			))
+		}
+		rows = append(rows, toggleRow)
+	}
+
 	// Add buttons to continue
 	rows = append(rows, []tgbotapi.InlineKeyboardButton{
 		tgbotapi.NewInlineKeyboardButtonData("Add context photo", "ex:photo"),
@@ -83,24 +108,134 @@ func (b *Bot) createExamplesKeyboard(examples []services.Example, selectedExampl
 	return tgbotapi.NewInlineKeyboardMarkup(rows...)
 }
 
-// createReviewKeyboard creates an inline keyboard for card review
-func (b *Bot) createReviewKeyboard(isFlipped bool) tgbotapi.InlineKeyboardMarkup {
+// toggleLabel marks the label of a toggle button that is on
+func toggleLabel(label string, on bool) string {
+	if on {
+		return "✅ " + label
+	}
+	return "⬜ " + label
+}
+
+// createReviewKeyboard creates an inline keyboard for card review, with buttons to
+// edit or delete the flipped card for users who may change it. The flip and rating
+// buttons carry the card and direction they belong to.
+func (b *Bot) createReviewKeyboard(cardID int, direction string, isFlipped, canEdit bool) tgbotapi.InlineKeyboardMarkup {
 	if !isFlipped {
 		// Show flip button if card is not flipped
 		return tgbotapi.NewInlineKeyboardMarkup(
 			tgbotapi.NewInlineKeyboardRow(
-				tgbotapi.NewInlineKeyboardButtonData("Flip card", "rev:flip"),
+				tgbotapi.NewInlineKeyboardButtonData("Flip card", fmt.Sprintf("rev:flip:%d:%s", cardID, direction)),
 			),
 		)
 	}
 
 	// Show rating buttons if card is flipped
+	rows := [][]tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Again", fmt.Sprintf("rev:rate:%d:%s:0", cardID, direction)),
+			tgbotapi.NewInlineKeyboardButtonData("Hard", fmt.Sprintf("rev:rate:%d:%s:1", cardID, direction)),
+			tgbotapi.NewInlineKeyboardButtonData("Good", fmt.Sprintf("rev:rate:%d:%s:2", cardID, direction)),
+			tgbotapi.NewInlineKeyboardButtonData("Easy", fmt.Sprintf("rev:rate:%d:%s:3", cardID, direction)),
+		),
+	}
+
+	if canEdit {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✏️ Edit", fmt.Sprintf("card:edit:%d", cardID)),
+			tgbotapi.NewInlineKeyboardButtonData("🗑 Delete", fmt.Sprintf("card:delete:%d", cardID)),
+		))
+	}
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// createUndoKeyboard creates an inline keyboard to undo the rating of a review
+func (b *Bot) createUndoKeyboard(logID int) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("↩️ Undo", fmt.Sprintf("rev:undo:%d", logID)),
+		),
+	)
+}
+
+// createQuizKeyboard creates an inline keyboard with the words to choose from for a quiz question,
+// two per row
+func (b *Bot) createQuizKeyboard(cardID int, options []models.FlashCard) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	var row []tgbotapi.InlineKeyboardButton
+
+	for _, option := range options {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(option.Word, fmt.Sprintf("rev:quiz:%d:%d", cardID, option.ID)))
+		if len(row) == 2 {
+			rows = append(rows, row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("🤷 I don't know", fmt.Sprintf("rev:quiz:%d:0", cardID)),
+	))
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// createCardEditorKeyboard creates an inline keyboard to choose the field of a card to change
+func (b *Bot) createCardEditorKeyboard(cardID int) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Word", fmt.Sprintf("card:field:%d:word", cardID)),
+			tgbotapi.NewInlineKeyboardButtonData("Definition", fmt.Sprintf("card:field:%d:definition", cardID)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Examples", fmt.Sprintf("card:field:%d:examples", cardID)),
+			tgbotapi.NewInlineKeyboardButtonData("Image", fmt.Sprintf("card:field:%d:image", cardID)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🔁 Template", fmt.Sprintf("card:template:%d", cardID)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🗑 Delete", fmt.Sprintf("card:delete:%d", cardID)),
+			tgbotapi.NewInlineKeyboardButtonData("Done", fmt.Sprintf("card:done:%d", cardID)),
+		),
+	)
+}
+
+// createCardTemplateKeyboard creates an inline keyboard to choose the template of a card,
+// marking the current one
+func (b *Bot) createCardTemplateKeyboard(cardID int, current string) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+
+	options := append([]string{cardTemplateDefault}, cardTemplates...)
+	for _, template := range options {
+		label := "Bank's template"
+		if template != cardTemplateDefault {
+			label = templateLabel(template)
+		}
+		if template == current || (template == cardTemplateDefault && current == "") {
+			label = "✅ " + label
+		}
+
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(label, fmt.Sprintf("card:set_template:%d:%s", cardID, template)),
+		))
+	}
+
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("« Back", fmt.Sprintf("card:edit:%d", cardID)),
+	))
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// createDeleteConfirmationKeyboard creates an inline keyboard to confirm deleting a card
+func (b *Bot) createDeleteConfirmationKeyboard(cardID int) tgbotapi.InlineKeyboardMarkup {
 	return tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("Again", "rev:rate:0"),
-			tgbotapi.NewInlineKeyboardButtonData("Hard", "rev:rate:1"),
-			tgbotapi.NewInlineKeyboardButtonData("Good", "rev:rate:2"),
-			tgbotapi.NewInlineKeyboardButtonData("Easy", "rev:rate:3"),
+			tgbotapi.NewInlineKeyboardButtonData("🗑 Delete", fmt.Sprintf("card:confirm_delete:%d", cardID)),
+			tgbotapi.NewInlineKeyboardButtonData("Cancel", fmt.Sprintf("card:done:%d", cardID)),
 		),
 	)
 }
@@ -147,6 +282,87 @@ func (b *Bot) createBanksKeyboard(banks []models.CardBank, currentPage, totalPag
 	return tgbotapi.NewInlineKeyboardMarkup(rows...)
 }
 
+// createCardListKeyboard creates an inline keyboard to open the cards of a page of /cards,
+// change the filter and go to other pages
+func (b *Bot) createCardListKeyboard(cards []models.FlashCard, filter, text string, currentPage, totalPages int) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+
+	// Two cards per row
+	var row []tgbotapi.InlineKeyboardButton
+	for _, card := range cards {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(card.Word, fmt.Sprintf("card:view:%d", card.ID)))
+		if len(row) == 2 {
+			rows = append(rows, row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+
+	if filter == "" {
+		filter = cardFilterAll
+	}
+
+	// Changing the filter starts again on the first page
+	var filterRow []tgbotapi.InlineKeyboardButton
+	for _, f := range cardFilters {
+		label := strings.ToUpper(f[:1]) + f[1:]
+		if f == filter {
+			label = "✅ " + label
+		}
+		filterRow = append(filterRow, tgbotapi.NewInlineKeyboardButtonData(label, fmt.Sprintf("page:cards:1:%s:%s", f, text)))
+	}
+	rows = append(rows, filterRow)
+
+	// Add pagination buttons if needed
+	if totalPages > 1 {
+		var paginationRow []tgbotapi.InlineKeyboardButton
+
+		if currentPage > 1 {
+			paginationRow = append(paginationRow, tgbotapi.NewInlineKeyboardButtonData(
+				"◀️ Previous",
+				fmt.Sprintf("page:cards:%d:%s:%s", currentPage-1, filter, text),
+			))
+		}
+
+		if currentPage < totalPages {
+			paginationRow = append(paginationRow, tgbotapi.NewInlineKeyboardButtonData(
+				"Next ▶️",
+				fmt.Sprintf("page:cards:%d:%s:%s", currentPage+1, filter, text),
+			))
+		}
+
+		rows = append(rows, paginationRow)
+	}
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// createLatencyListKeyboard creates an inline keyboard to go to other pages of /stats times
+func (b *Bot) createLatencyListKeyboard(currentPage, totalPages int) tgbotapi.InlineKeyboardMarkup {
+	var paginationRow []tgbotapi.InlineKeyboardButton
+
+	if currentPage > 1 {
+		paginationRow = append(paginationRow, tgbotapi.NewInlineKeyboardButtonData(
+			"◀️ Previous",
+			fmt.Sprintf("page:times:%d", currentPage-1),
+		))
+	}
+
+	if currentPage < totalPages {
+		paginationRow = append(paginationRow, tgbotapi.NewInlineKeyboardButtonData(
+			"Next ▶️",
+			fmt.Sprintf("page:times:%d", currentPage+1),
+		))
+	}
+
+	if len(paginationRow) == 0 {
+		return tgbotapi.NewInlineKeyboardMarkup()
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(paginationRow)
+}
+
 // createSettingsKeyboard creates an inline keyboard for settings
 func (b *Bot) createSettingsKeyboard(settings *models.Settings) tgbotapi.InlineKeyboardMarkup {
 	notificationsText := "Notifications: OFF"
@@ -159,6 +375,11 @@ func (b *Bot) createSettingsKeyboard(settings *models.Settings) tgbotapi.InlineK
 		darkModeText = "Dark Mode: ON"
 	}
 
+	timedGradingText := "Timed Grading: OFF"
+	if settings.Settings.TimedGrading {
+		timedGradingText = "Timed Grading: ON"
+	}
+
 	return tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData("Change Active Bank", "set:bank"),
@@ -169,6 +390,37 @@ func (b *Bot) createSettingsKeyboard(settings *models.Settings) tgbotapi.InlineK
 				"set:limit",
 			),
 		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				fmt.Sprintf("Algorithm: %s", algorithmName(settings.Settings.Algorithm.Type)),
+				"set:algo",
+			),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				fmt.Sprintf("Learning Steps: %s", formatSteps(settings.Settings.LearningSteps, spaced_repetition.DefaultLearningSteps)),
+				"set:steps",
+			),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				fmt.Sprintf("Relearning Steps: %s", formatSteps(settings.Settings.RelearningSteps, spaced_repetition.DefaultRelearningSteps)),
+				"set:relearning",
+			),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Daily Limits", "set:daily"),
+			tgbotapi.NewInlineKeyboardButtonData("Active Bank Limits", "set:banklimits"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				fmt.Sprintf("Time Zone: %s", settings.Settings.Location()),
+				"set:timezone",
+			),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(timedGradingText, "set:timed"),
+		),
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData(notificationsText, "set:notifications"),
 		),
@@ -177,3 +429,86 @@ func (b *Bot) createSettingsKeyboard(settings *models.Settings) tgbotapi.InlineK
 		),
 	)
 }
+
+// createAlgorithmKeyboard creates an inline keyboard for choosing a spaced repetition algorithm
+func (b *Bot) createAlgorithmKeyboard(config models.AlgorithmConfig) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+
+	for _, algorithmType := range algorithmTypes {
+		text := algorithmName(algorithmType)
+		if algorithmType == config.Type || (config.Type == "" && algorithmType == spaced_repetition.AlgorithmTypeSM2) {
+			text = "✅ " + text
+		}
+
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(text, fmt.Sprintf("set:algo:%s", algorithmType)),
+		))
+	}
+
+	// Only tunable algorithms get a parameters button
+	if len(algorithmParameters[config.Type]) > 0 {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Tune parameters", "set:params"),
+		))
+	}
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// createAlgorithmParamsKeyboard creates an inline keyboard with the tunable parameters of an algorithm
+func (b *Bot) createAlgorithmParamsKeyboard(config models.AlgorithmConfig) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+
+	for _, param := range algorithmParameters[config.Type] {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				fmt.Sprintf("%s: %s", param.Label, algorithmParameterValue(config, param.Name)),
+				fmt.Sprintf("set:param:%s", param.Name),
+			),
+		))
+	}
+
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("Reset to defaults", "set:params:reset"),
+	))
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// createImportPreviewKeyboard creates an inline keyboard for confirming an import
+func (b *Bot) createImportPreviewKeyboard(newCards int, canMapColumns bool) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+
+	if newCards > 0 {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("Import %d cards", newCards), "imp:confirm"),
+		))
+	}
+
+	// Column mapping only applies to CSV and TSV files
+	if canMapColumns {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Change columns", "imp:columns"),
+		))
+	}
+
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("Cancel", "imp:cancel"),
+	))
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// createExportKeyboard creates an inline keyboard for choosing the export format
+func (b *Bot) createExportKeyboard() tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("CSV", "exp:csv"),
+			tgbotapi.NewInlineKeyboardButtonData("Anki (.apkg)", "exp:apkg"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("JSON", "exp:json"),
+			tgbotapi.NewInlineKeyboardButtonData("JSON with my progress", "exp:json:reviews"),
+		),
+	)
+}
@@ -0,0 +1,255 @@
+package telegram
+
+import (
+	"fmt"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/supercakecrumb/flash-cards-language-tg-bot/internal/domain/models"
+)
+
+// manualSeparators split a manually written card into its front and back, in order of preference.
+// A plain hyphen needs spaces around it so hyphenated words stay whole.
+var manualSeparators = []string{"—", "–", " - "}
+
+// manualExampleSeparator splits the examples off the back of a manually written card
+const manualExampleSeparator = "|"
+
+// manualCard is a card written out by the user
+type manualCard struct {
+	Front    string
+	Back     string
+	Examples []string
+}
+
+// parseManualCard parses a card written as "front — back", optionally followed by examples
+// separated by "|", e.g. "break the ice — start a conversation | She told a joke to break the ice"
+func parseManualCard(line string) (manualCard, bool) {
+	for _, separator := range manualSeparators {
+		front, rest, found := strings.Cut(line, separator)
+		if !found {
+			continue
+		}
+
+		parts := strings.Split(rest, manualExampleSeparator)
+		card := manualCard{
+			Front: strings.TrimSpace(front),
+			Back:  strings.TrimSpace(parts[0]),
+		}
+		for _, example := range parts[1:] {
+			if example = strings.TrimSpace(example); example != "" {
+				card.Examples = append(card.Examples, example)
+			}
+		}
+
+		if card.Front == "" || card.Back == "" {
+			return manualCard{}, false
+		}
+		return card, true
+	}
+
+	return manualCard{}, false
+}
+
+// parseManualCards parses a message with one manually written card per line.
+// It returns the cards and the lines that aren't cards.
+func parseManualCards(text string) ([]manualCard, []string) {
+	var cards []manualCard
+	var invalid []string
+
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		card, ok := parseManualCard(line)
+		if !ok {
+			invalid = append(invalid, line)
+			continue
+		}
+		cards = append(cards, card)
+	}
+
+	return cards, invalid
+}
+
+func (b *Bot) handleManualCommand(update tgbotapi.Update, user *models.User, args string) {
+	chatID := update.Message.Chat.ID
+
+	bankID, ok := b.editableActiveBank(chatID, user)
+	if !ok {
+		return
+	}
+
+	// Cards written out in the command are created right away
+	if cards, invalid := parseManualCards(args); len(cards) > 0 {
+		b.createManualCards(chatID, user, bankID, cards, invalid)
+		return
+	}
+
+	front := strings.TrimSpace(args)
+	if front == "" {
+		b.setState(user.TelegramID, UserState{
+			State:       "awaiting_manual_front",
+			CurrentBank: bankID,
+		})
+		b.sendMessage(chatID, "✍️ Please send the front of the card: a word, phrase or idiom.\n\n"+
+			"You can also send a whole card as \"word — definition\", or several cards with one per line.")
+		return
+	}
+
+	b.setState(user.TelegramID, UserState{
+		State:       "awaiting_manual_back",
+		CurrentBank: bankID,
+		CurrentWord: front,
+	})
+	b.sendMessage(chatID, fmt.Sprintf("Please send the back of the card for \"%s\": its definition or translation.", front))
+}
+
+func (b *Bot) handleManualInput(update tgbotapi.Update, user *models.User, text string) {
+	chatID := update.Message.Chat.ID
+
+	state, _ := b.getState(user.TelegramID)
+	text = strings.TrimSpace(text)
+
+	switch state.State {
+	case "awaiting_manual_front":
+		// Whole cards can be sent instead of the front
+		if cards, invalid := parseManualCards(text); len(cards) > 0 {
+			b.clearState(user.TelegramID)
+			b.createManualCards(chatID, user, state.CurrentBank, cards, invalid)
+			return
+		}
+
+		if text == "" {
+			b.sendErrorMessage(chatID, "The front of the card can't be empty. Please send a word or phrase.")
+			return
+		}
+
+		state.State = "awaiting_manual_back"
+		state.CurrentWord = text
+		b.setState(user.TelegramID, state)
+
+		b.sendMessage(chatID, fmt.Sprintf("Please send the back of the card for \"%s\": its definition or translation.", text))
+
+	case "awaiting_manual_back":
+		if text == "" {
+			b.sendErrorMessage(chatID, "The back of the card can't be empty. Please send its definition or translation.")
+			return
+		}
+
+		state.State = "awaiting_manual_examples"
+		state.ManualBack = text
+		b.setState(user.TelegramID, state)
+
+		b.sendMessage(chatID, fmt.Sprintf("Please send examples of \"%s\", one per line, or \"%s\" to create the card without examples.", state.CurrentWord, clearValue))
+
+	case "awaiting_manual_examples":
+		card := manualCard{
+			Front: state.CurrentWord,
+			Back:  state.ManualBack,
+		}
+		if text != clearValue {
+			for _, line := range strings.Split(text, "\n") {
+				if line = strings.TrimSpace(line); line != "" {
+					card.Examples = append(card.Examples, line)
+				}
+			}
+		}
+
+		b.clearState(user.TelegramID)
+		b.createManualCards(chatID, user, state.CurrentBank, []manualCard{card}, nil)
+	}
+}
+
+// editableActiveBank returns the user's active bank if they may add cards to it
+func (b *Bot) editableActiveBank(chatID int64, user *models.User) (int, bool) {
+	settings, err := b.settingsService.GetUserSettings(user.ID)
+	if err != nil {
+		b.logger.Error("Failed to get user settings",
+			"error", err,
+			"user_id", user.ID,
+		)
+		b.sendErrorMessage(chatID, "Failed to get your settings. Please try again.")
+		return 0, false
+	}
+
+	activeBankID := settings.Settings.ActiveCardBankID
+	if !b.checkCanEdit(chatID, user, activeBankID) {
+		return 0, false
+	}
+
+	return activeBankID, true
+}
+
+// createManualCards creates cards written out by the user, skipping words the bank already has,
+// and reports what was created
+func (b *Bot) createManualCards(chatID int64, user *models.User, bankID int, cards []manualCard, invalid []string) {
+	var created []*models.FlashCard
+	var duplicates []string
+
+	for _, c := range cards {
+		// Words are unique within a bank
+		if _, err := b.flashcardService.GetFlashCardByWord(c.Front, bankID); err == nil {
+			duplicates = append(duplicates, c.Front)
+			continue
+		}
+
+		card := models.NewFlashCard(bankID, c.Front, c.Back, c.Examples, "")
+		if err := b.flashcardService.CreateFlashCard(card); err != nil {
+			b.logger.Error("Failed to save flash card",
+				"error", err,
+				"word", c.Front,
+			)
+			b.sendErrorMessage(chatID, fmt.Sprintf("Failed to save the card for \"%s\". Please try again.", c.Front))
+			break
+		}
+		created = append(created, card)
+
+		// Update statistics
+		if err := b.statsService.IncrementLearned(user.ID, bankID); err != nil {
+			b.logger.Warn("Failed to update statistics",
+				"error", err,
+				"user_id", user.ID,
+				"bank_id", bankID,
+			)
+		}
+	}
+
+	var text string
+	switch {
+	case len(cards) == 1 && len(created) == 1:
+		card := created[0]
+		text = fmt.Sprintf("✅ Flash card created for *%s*!\n\n*Definition:*\n%s", card.Word, card.Definition)
+		if len(card.Examples) > 0 {
+			text += "\n\n*Examples:*"
+			for i, ex := range card.Examples {
+				text += fmt.Sprintf("\n%d. %s", i+1, ex)
+			}
+		}
+	case len(created) > 0:
+		text = fmt.Sprintf("✅ Created %d flash cards:", len(created))
+		for _, card := range created {
+			text += fmt.Sprintf("\n• %s", card.Word)
+		}
+	default:
+		text = "No flash cards were created."
+	}
+
+	if len(duplicates) > 0 {
+		text += fmt.Sprintf("\n\nAlready in this bank, skipped: %s", strings.Join(duplicates, ", "))
+	}
+	if len(invalid) > 0 {
+		text += fmt.Sprintf("\n\nThese lines aren't written as \"word — definition\" and were skipped:\n%s", strings.Join(invalid, "\n"))
+	}
+
+	if len(created) > 0 {
+		text += "\n\nUse /review to practice your flash cards."
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "HTML"
+
+	b.api.Send(msg)
+}
@@ -0,0 +1,148 @@
+package telegram
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/supercakecrumb/flash-cards-language-tg-bot/internal/domain/models"
+	"github.com/supercakecrumb/flash-cards-language-tg-bot/pkg/spaced_repetition"
+)
+
+// quizDistractors is the number of wrong words offered with the right one
+const quizDistractors = 3
+
+// quizOptions returns the card with words of the same bank it can be confused with, in random order.
+// A bank with a single card has no options to choose from.
+func (b *Bot) quizOptions(card models.ReviewCard) []models.FlashCard {
+	distractors, err := b.flashcardService.GetDistractors(&card.FlashCard, quizDistractors)
+	if err != nil {
+		b.logger.Warn("Failed to get quiz distractors",
+			"error", err,
+			"card_id", card.ID,
+		)
+		return nil
+	}
+
+	options := append(distractors, card.FlashCard)
+	rand.Shuffle(len(options), func(i, j int) {
+		options[i], options[j] = options[j], options[i]
+	})
+
+	return options
+}
+
+// handleQuizAnswer grades the word chosen for the current card of a quiz and rates the card
+// by whether it was right and how long it took. args are the card ID and the chosen card ID,
+// which is 0 if the user didn't know.
+func (b *Bot) handleQuizAnswer(update tgbotapi.Update, user *models.User, state UserState, args []string) {
+	chatID := update.CallbackQuery.Message.Chat.ID
+
+	if len(args) < 2 {
+		b.logger.Error("Invalid quiz callback data", "args", args)
+		return
+	}
+
+	cardID, err1 := strconv.Atoi(args[0])
+	chosenID, err2 := strconv.Atoi(args[1])
+	if err1 != nil || err2 != nil {
+		b.logger.Error("Invalid card ID in quiz callback", "args", args)
+		return
+	}
+
+	// Buttons of earlier questions stay in the chat
+	reviewState := state.ReviewState
+	if reviewState.CurrentCard >= len(reviewState.Cards) || reviewState.Cards[reviewState.CurrentCard].ID != cardID || reviewState.IsFlipped {
+		b.sendMessage(chatID, "This question has already been answered.")
+		return
+	}
+
+	card := reviewState.Cards[reviewState.CurrentCard]
+	correct := chosenID == card.ID
+
+	elapsed := answerLatency(reviewState)
+
+	b.markQuizAnswer(update.CallbackQuery.Message, card.ID, chosenID)
+
+	var feedback string
+	switch {
+	case correct && elapsed > 0:
+		feedback = fmt.Sprintf("✅ Correct! You answered in %.1f seconds.", elapsed.Seconds())
+	case correct:
+		feedback = "✅ Correct!"
+	case chosenID == 0:
+		feedback = "The answer is:"
+	default:
+		feedback = "❌ Not quite, the answer is:"
+	}
+
+	feedback += fmt.Sprintf("\n\n📝 *%s*", card.Word)
+	if card.Phonetic != "" {
+		feedback += " " + card.Phonetic
+	}
+
+	msg := tgbotapi.NewMessage(chatID, feedback)
+	msg.ParseMode = "HTML"
+	b.api.Send(msg)
+
+	b.sendPronunciation(chatID, &card.FlashCard)
+
+	// Answered questions can't be answered again
+	reviewState.IsFlipped = true
+	reviewState.Latency = elapsed
+	b.rateReviewCard(chatID, user, state, quizQuality(correct, elapsed))
+}
+
+// quizQuality maps a quiz answer to a rating: wrong answers are forgotten, right ones are
+// easier the faster they came. An unknown response time counts as a good answer.
+func quizQuality(correct bool, elapsed time.Duration) int {
+	if !correct {
+		return spaced_repetition.QualityAgain
+	}
+	return spaced_repetition.TimedQuality(spaced_repetition.QualityGood, elapsed)
+}
+
+// markQuizAnswer marks the right word and a wrong choice on the buttons of a quiz question
+// and drops the buttons that aren't words
+func (b *Bot) markQuizAnswer(message *tgbotapi.Message, cardID, chosenID int) {
+	if message.ReplyMarkup == nil {
+		return
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, row := range message.ReplyMarkup.InlineKeyboard {
+		var marked []tgbotapi.InlineKeyboardButton
+		for _, button := range row {
+			if button.CallbackData == nil {
+				continue
+			}
+
+			parts := strings.Split(*button.CallbackData, ":")
+			if len(parts) != 4 || parts[3] == "0" {
+				continue
+			}
+
+			switch parts[3] {
+			case strconv.Itoa(cardID):
+				button.Text = "✅ " + button.Text
+			case strconv.Itoa(chosenID):
+				button.Text = "❌ " + button.Text
+			}
+			marked = append(marked, button)
+		}
+		if len(marked) > 0 {
+			rows = append(rows, marked)
+		}
+	}
+
+	edit := tgbotapi.NewEditMessageReplyMarkup(message.Chat.ID, message.MessageID, tgbotapi.NewInlineKeyboardMarkup(rows...))
+	if _, err := b.api.Send(edit); err != nil {
+		b.logger.Warn("Failed to mark quiz answer",
+			"error", err,
+			"card_id", cardID,
+		)
+	}
+}
@@ -0,0 +1,236 @@
+package telegram
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/supercakecrumb/flash-cards-language-tg-bot/internal/domain/models"
+	"github.com/supercakecrumb/flash-cards-language-tg-bot/pkg/spaced_repetition"
+)
+
+// algorithmTypes lists the algorithms users can choose in /settings
+var algorithmTypes = []string{
+	spaced_repetition.AlgorithmTypeSM2,
+	spaced_repetition.AlgorithmTypeCustom,
+	spaced_repetition.AlgorithmTypeFSRS,
+}
+
+// algorithmParameter describes a tunable algorithm parameter
+type algorithmParameter struct {
+	Name   string
+	Label  string
+	Prompt string
+}
+
+// algorithmParameters lists the tunable parameters of each algorithm type
+var algorithmParameters = map[string][]algorithmParameter{
+	spaced_repetition.AlgorithmTypeCustom: {
+		{Name: "max_interval", Label: "Max interval", Prompt: "Please enter the maximum interval in days (1-36500):"},
+		{Name: "initial_intervals", Label: "Initial intervals", Prompt: "Please enter the intervals in days for the first repetitions, separated by commas (e.g. 1, 3, 7):"},
+		{Name: "min_ease_factor", Label: "Min ease", Prompt: "Please enter the minimum ease factor (at least 1.0, e.g. 1.3):"},
+		{Name: "max_ease_factor", Label: "Max ease", Prompt: "Please enter the maximum ease factor (at least 1.0, or 0 for no limit):"},
+		{Name: "default_ease_factor", Label: "Starting ease", Prompt: "Please enter the ease factor for new cards (at least 1.0, e.g. 2.5):"},
+	},
+	spaced_repetition.AlgorithmTypeFSRS: {
+		{Name: "request_retention", Label: "Target retention", Prompt: "Please enter the desired probability of recall between 0 and 1 (e.g. 0.9):"},
+		{Name: "max_interval", Label: "Max interval", Prompt: "Please enter the maximum interval in days (1-36500):"},
+	},
+}
+
+// maxIntervalLimit is the largest interval users can configure
+const maxIntervalLimit = 36500
+
+// algorithmName returns a human readable algorithm name
+func algorithmName(algorithmType string) string {
+	switch algorithmType {
+	case "", spaced_repetition.AlgorithmTypeSM2:
+		return "SM-2"
+	case spaced_repetition.AlgorithmTypeCustom:
+		return "Custom"
+	case spaced_repetition.AlgorithmTypeFSRS:
+		return "FSRS"
+	default:
+		return algorithmType
+	}
+}
+
+// findAlgorithmParameter looks up a tunable parameter of an algorithm type
+func findAlgorithmParameter(algorithmType, name string) (algorithmParameter, bool) {
+	for _, param := range algorithmParameters[algorithmType] {
+		if param.Name == name {
+			return param, true
+		}
+	}
+	return algorithmParameter{}, false
+}
+
+// algorithmParameterValue formats the effective value of a parameter, including defaults
+func algorithmParameterValue(config models.AlgorithmConfig, name string) string {
+	algorithm, err := spaced_repetition.CreateAlgorithm(config)
+	if err != nil {
+		return "?"
+	}
+
+	switch a := algorithm.(type) {
+	case *spaced_repetition.CustomAlgorithm:
+		switch name {
+		case "max_interval":
+			return fmt.Sprintf("%d days", a.MaxInterval)
+		case "initial_intervals":
+			intervals := make([]string, len(a.InitialIntervals))
+			for i, interval := range a.InitialIntervals {
+				intervals[i] = strconv.Itoa(interval)
+			}
+			return strings.Join(intervals, ", ")
+		case "min_ease_factor":
+			return fmt.Sprintf("%.2f", a.MinEaseFactor)
+		case "max_ease_factor":
+			if a.MaxEaseFactor == 0 {
+				return "none"
+			}
+			return fmt.Sprintf("%.2f", a.MaxEaseFactor)
+		case "default_ease_factor":
+			return fmt.Sprintf("%.2f", a.DefaultEaseFactor)
+		}
+	case *spaced_repetition.FSRSAlgorithm:
+		switch name {
+		case "request_retention":
+			return fmt.Sprintf("%.2f", a.RequestRetention)
+		case "max_interval":
+			return fmt.Sprintf("%d days", a.MaxInterval)
+		}
+	}
+
+	return "?"
+}
+
+// parseAlgorithmParameter parses user input for an algorithm parameter
+func parseAlgorithmParameter(name, text string) (interface{}, error) {
+	text = strings.TrimSpace(text)
+
+	switch name {
+	case "max_interval":
+		value, err := strconv.Atoi(text)
+		if err != nil || value < 1 || value > maxIntervalLimit {
+			return nil, models.ErrInvalidInput
+		}
+		return value, nil
+
+	case "initial_intervals":
+		var intervals []int
+		for _, part := range strings.Split(text, ",") {
+			value, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil || value > maxIntervalLimit {
+				return nil, models.ErrInvalidInput
+			}
+			intervals = append(intervals, value)
+		}
+		return intervals, nil
+
+	default:
+		value, err := strconv.ParseFloat(strings.Replace(text, ",", ".", 1), 64)
+		if err != nil {
+			return nil, models.ErrInvalidInput
+		}
+		return value, nil
+	}
+}
+
+// maxStepMinutes is the longest learning step, steps must fit within a day
+const maxStepMinutes = 24 * 60
+
+// formatStep formats a learning step delay, e.g. "10 minutes"
+func formatStep(d time.Duration) string {
+	minutes := int(d.Round(time.Minute).Minutes())
+	switch {
+	case minutes <= 1:
+		return "1 minute"
+	case minutes < 60:
+		return fmt.Sprintf("%d minutes", minutes)
+	case minutes < 120:
+		return "1 hour"
+	default:
+		return fmt.Sprintf("%d hours", minutes/60)
+	}
+}
+
+// formatSteps formats learning steps in minutes, e.g. "1m 10m", nil means the default steps
+func formatSteps(minutes []int, defaults []time.Duration) string {
+	if minutes == nil {
+		minutes = make([]int, len(defaults))
+		for i, d := range defaults {
+			minutes[i] = int(d.Minutes())
+		}
+	}
+
+	if len(minutes) == 0 {
+		return "none"
+	}
+
+	steps := make([]string, len(minutes))
+	for i, m := range minutes {
+		if m%60 == 0 {
+			steps[i] = fmt.Sprintf("%dh", m/60)
+		} else {
+			steps[i] = fmt.Sprintf("%dm", m)
+		}
+	}
+	return strings.Join(steps, " ")
+}
+
+// parseSteps parses learning steps such as "1m 10m 1h" into minutes, "none" disables the steps
+func parseSteps(text string) ([]int, error) {
+	text = strings.ToLower(strings.TrimSpace(text))
+	if text == "none" || text == "0" {
+		return []int{}, nil
+	}
+
+	var steps []int
+	for _, part := range strings.FieldsFunc(text, func(r rune) bool { return r == ' ' || r == ',' }) {
+		multiplier := 1
+		switch {
+		case strings.HasSuffix(part, "h"):
+			multiplier = 60
+			part = strings.TrimSuffix(part, "h")
+		case strings.HasSuffix(part, "m"):
+			part = strings.TrimSuffix(part, "m")
+		}
+
+		value, err := strconv.Atoi(part)
+		if err != nil || value < 1 || value*multiplier > maxStepMinutes {
+			return nil, models.ErrInvalidInput
+		}
+		steps = append(steps, value*multiplier)
+	}
+
+	if len(steps) == 0 {
+		return nil, models.ErrInvalidInput
+	}
+
+	return steps, nil
+}
+
+// maxDailyLimit is the largest daily new card or review limit
+const maxDailyLimit = 9999
+
+// parseDailyLimits parses daily limits such as "20 200". 0 is a valid limit.
+func parseDailyLimits(text string) (models.DailyLimits, error) {
+	parts := strings.FieldsFunc(text, func(r rune) bool { return r == ' ' || r == ',' || r == '/' })
+	if len(parts) != 2 {
+		return models.DailyLimits{}, models.ErrInvalidInput
+	}
+
+	newCards, err := strconv.Atoi(parts[0])
+	if err != nil || newCards < 0 || newCards > maxDailyLimit {
+		return models.DailyLimits{}, models.ErrInvalidInput
+	}
+
+	reviews, err := strconv.Atoi(parts[1])
+	if err != nil || reviews < 0 || reviews > maxDailyLimit {
+		return models.DailyLimits{}, models.ErrInvalidInput
+	}
+
+	return models.DailyLimits{NewCards: &newCards, Reviews: &reviews}, nil
+}
@@ -0,0 +1,206 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/supercakecrumb/flash-cards-language-tg-bot/internal/domain/models"
+	"github.com/supercakecrumb/flash-cards-language-tg-bot/internal/repository"
+)
+
+// stateCleanupInterval is how often expired conversation states are removed
+const stateCleanupInterval = 10 * time.Minute
+
+// StateStore persists the conversation state of users between updates.
+// Implementations must be safe for concurrent use.
+type StateStore interface {
+	Get(telegramID int64) (UserState, bool, error)
+	Set(telegramID int64, state UserState) error
+	Delete(telegramID int64) error
+	DeleteExpired() (int64, error)
+}
+
+// memoryStateStore keeps conversation states in memory, they are lost on restart
+type memoryStateStore struct {
+	mu     sync.Mutex
+	ttl    time.Duration
+	states map[int64]memoryState
+}
+
+// memoryState is a conversation state with its expiry
+type memoryState struct {
+	state     UserState
+	expiresAt time.Time
+}
+
+// NewMemoryStateStore creates a state store that keeps states in memory
+func NewMemoryStateStore(ttl time.Duration) StateStore {
+	return &memoryStateStore{
+		ttl:    ttl,
+		states: make(map[int64]memoryState),
+	}
+}
+
+// Get retrieves the state of a user
+func (s *memoryStateStore) Get(telegramID int64) (UserState, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.states[telegramID]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return UserState{}, false, nil
+	}
+
+	return entry.state, true, nil
+}
+
+// Set stores the state of a user and extends its expiry
+func (s *memoryStateStore) Set(telegramID int64, state UserState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.states[telegramID] = memoryState{
+		state:     state,
+		expiresAt: time.Now().Add(s.ttl),
+	}
+
+	return nil
+}
+
+// Delete removes the state of a user
+func (s *memoryStateStore) Delete(telegramID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.states, telegramID)
+	return nil
+}
+
+// DeleteExpired removes all expired states
+func (s *memoryStateStore) DeleteExpired() (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	var deleted int64
+	for telegramID, entry := range s.states {
+		if now.After(entry.expiresAt) {
+			delete(s.states, telegramID)
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
+
+// postgresStateStore keeps conversation states in the database as JSON,
+// so they survive restarts and are shared between bot instances
+type postgresStateStore struct {
+	repo repository.ConversationStateRepository
+	ttl  time.Duration
+}
+
+// NewPostgresStateStore creates a state store backed by the conversation state repository
+func NewPostgresStateStore(repo repository.ConversationStateRepository, ttl time.Duration) StateStore {
+	return &postgresStateStore{
+		repo: repo,
+		ttl:  ttl,
+	}
+}
+
+// Get retrieves the state of a user
+func (s *postgresStateStore) Get(telegramID int64) (UserState, bool, error) {
+	stored, err := s.repo.Get(telegramID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return UserState{}, false, nil
+		}
+		return UserState{}, false, err
+	}
+
+	var state UserState
+	if err := json.Unmarshal([]byte(stored.State), &state); err != nil {
+		return UserState{}, false, err
+	}
+
+	return state, true, nil
+}
+
+// Set stores the state of a user and extends its expiry
+func (s *postgresStateStore) Set(telegramID int64, state UserState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.Save(models.NewConversationState(telegramID, string(data), s.ttl))
+}
+
+// Delete removes the state of a user
+func (s *postgresStateStore) Delete(telegramID int64) error {
+	return s.repo.Delete(telegramID)
+}
+
+// DeleteExpired removes all expired states
+func (s *postgresStateStore) DeleteExpired() (int64, error) {
+	return s.repo.DeleteExpired(time.Now())
+}
+
+// getState retrieves the conversation state of a user.
+// Store errors are logged and treated as a missing state.
+func (b *Bot) getState(telegramID int64) (UserState, bool) {
+	state, exists, err := b.states.Get(telegramID)
+	if err != nil {
+		b.logger.Error("Failed to get user state",
+			"error", err,
+			"telegram_id", telegramID,
+		)
+		return UserState{}, false
+	}
+	return state, exists
+}
+
+// setState stores the conversation state of a user
+func (b *Bot) setState(telegramID int64, state UserState) {
+	if err := b.states.Set(telegramID, state); err != nil {
+		b.logger.Error("Failed to save user state",
+			"error", err,
+			"telegram_id", telegramID,
+			"state", state.State,
+		)
+	}
+}
+
+// clearState removes the conversation state of a user
+func (b *Bot) clearState(telegramID int64) {
+	if err := b.states.Delete(telegramID); err != nil {
+		b.logger.Error("Failed to clear user state",
+			"error", err,
+			"telegram_id", telegramID,
+		)
+	}
+}
+
+// cleanupStates periodically removes expired conversation states until ctx is done
+func (b *Bot) cleanupStates(ctx context.Context) {
+	ticker := time.NewTicker(stateCleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			deleted, err := b.states.DeleteExpired()
+			if err != nil {
+				b.logger.Error("Failed to delete expired user states", "error", err)
+				continue
+			}
+			if deleted > 0 {
+				b.logger.Debug("Deleted expired user states", "count", deleted)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package telegram
+
+import (
+	"fmt"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/supercakecrumb/flash-cards-language-tg-bot/internal/domain/models"
+)
+
+// cardTemplateDefault stands for the bank's template in callback data
+const cardTemplateDefault = "default"
+
+// cardTemplates are the templates offered for banks and cards, in button order
+var cardTemplates = []string{
+	models.TemplateReverse,
+	models.TemplateForward,
+	models.TemplateBoth,
+}
+
+func (b *Bot) handleTemplateCommand(update tgbotapi.Update, user *models.User, args string) {
+	chatID := update.Message.Chat.ID
+
+	// Get user's active card bank
+	settings, err := b.settingsService.GetUserSettings(user.ID)
+	if err != nil {
+		b.logger.Error("Failed to get user settings",
+			"error", err,
+			"user_id", user.ID,
+		)
+		b.sendErrorMessage(chatID, "Failed to get your settings. Please try again.")
+		return
+	}
+
+	activeBankID := settings.Settings.ActiveCardBankID
+
+	// Check if user has access to this bank
+	hasAccess, err := b.cardbankService.UserHasAccess(user.ID, activeBankID)
+	if err != nil || !hasAccess {
+		b.logger.Error("User doesn't have access to active bank",
+			"error", err,
+			"user_id", user.ID,
+			"bank_id", activeBankID,
+		)
+		b.sendErrorMessage(chatID, "You don't have access to your active card bank. Please select another bank using /banks.")
+		return
+	}
+
+	bank, err := b.cardbankService.GetCardBank(activeBankID)
+	if err != nil {
+		b.logger.Error("Failed to get bank",
+			"error", err,
+			"bank_id", activeBankID,
+		)
+		b.sendErrorMessage(chatID, "Failed to get your card bank. Please try again.")
+		return
+	}
+
+	// Without arguments show the current template
+	template := strings.ToLower(strings.TrimSpace(args))
+	if template == "" {
+		b.sendMessage(chatID, fmt.Sprintf("Cards in \"%s\" are reviewed %s.\n\n"+
+			"To change this, send /template reverse (definition → word), /template forward (word → definition) or /template both. "+
+			"Both directions of a card are scheduled independently. To change a single card, use the Template button of /edit.",
+			bank.Name, templateLabel(bank.CardTemplate)))
+		return
+	}
+
+	// Viewers can't change the bank
+	if !b.checkCanEdit(chatID, user, activeBankID) {
+		return
+	}
+
+	if !models.IsValidTemplate(template) {
+		b.sendErrorMessage(chatID, "Please choose reverse, forward or both, e.g. /template both")
+		return
+	}
+
+	bank, err = b.cardbankService.SetCardTemplate(bank.ID, template)
+	if err != nil {
+		b.logger.Error("Failed to set bank template",
+			"error", err,
+			"bank_id", activeBankID,
+			"template", template,
+		)
+		b.sendErrorMessage(chatID, "Failed to change the template of your card bank. Please try again.")
+		return
+	}
+
+	b.sendMessage(chatID, fmt.Sprintf("Cards in \"%s\" are now reviewed %s. Progress in directions you turn off is kept for when you turn them on again.", bank.Name, templateLabel(bank.CardTemplate)))
+}
+
+// showCardTemplates shows the templates a card can use
+func (b *Bot) showCardTemplates(chatID int64, card *models.FlashCard) {
+	text := fmt.Sprintf("🔁 *Template of \"%s\"*\n\nCurrently reviewed %s. Choose how to review this card:", card.Word, templateLabel(b.cardTemplate(card)))
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = b.createCardTemplateKeyboard(card.ID, card.Template)
+
+	b.api.Send(msg)
+}
+
+// setCardTemplate changes the template of a card and shows the editor again
+func (b *Bot) setCardTemplate(chatID int64, user *models.User, card *models.FlashCard, template string) {
+	if template == cardTemplateDefault {
+		template = ""
+	}
+	if template != "" && !models.IsValidTemplate(template) {
+		b.logger.Error("Unknown card template", "template", template)
+		return
+	}
+
+	card.Template = template
+
+	state, _ := b.getState(user.TelegramID)
+	b.saveCardEdit(chatID, user, state, card)
+}
+
+// cardTemplate returns the template a card is reviewed with, its own or its bank's
+func (b *Bot) cardTemplate(card *models.FlashCard) string {
+	if card.Template != "" {
+		return card.Template
+	}
+
+	bank, err := b.cardbankService.GetCardBank(card.CardBankID)
+	if err != nil {
+		b.logger.Warn("Failed to get bank of card",
+			"error", err,
+			"card_id", card.ID,
+			"bank_id", card.CardBankID,
+		)
+		return models.DefaultTemplate
+	}
+
+	return bank.CardTemplate
+}
+
+// templateLabel describes the directions of a template
+func templateLabel(template string) string {
+	switch template {
+	case models.TemplateForward:
+		return "word → definition"
+	case models.TemplateBoth:
+		return "in both directions"
+	default:
+		return "definition → word"
+	}
+}
+
+// directionLabel names a review direction
+func directionLabel(direction string) string {
+	switch direction {
+	case models.DirectionForward:
+		return "Word → definition"
+	case models.DirectionCloze:
+		return "Cloze"
+	default:
+		return "Definition → word"
+	}
+}
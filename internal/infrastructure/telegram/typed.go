@@ -0,0 +1,98 @@
+package telegram
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/supercakecrumb/flash-cards-language-tg-bot/internal/domain/models"
+	"github.com/supercakecrumb/flash-cards-language-tg-bot/pkg/answer"
+	"github.com/supercakecrumb/flash-cards-language-tg-bot/pkg/cloze"
+	"github.com/supercakecrumb/flash-cards-language-tg-bot/pkg/spaced_repetition"
+)
+
+// handleTypedAnswer grades the word typed for the current card of a typed review session
+// and rates the card by how close the answer was
+func (b *Bot) handleTypedAnswer(update tgbotapi.Update, user *models.User, text string) {
+	chatID := update.Message.Chat.ID
+
+	state, _ := b.getState(user.TelegramID)
+	reviewState := state.ReviewState
+	if reviewState.CurrentCard >= len(reviewState.Cards) {
+		b.sendErrorMessage(chatID, "Review session expired. Please start a new review with /review.")
+		return
+	}
+
+	card := reviewState.Cards[reviewState.CurrentCard]
+	expected := clozeAnswer(card)
+	result := answer.Check(text, expected)
+
+	var feedback string
+	switch result.Verdict {
+	case answer.Exact:
+		feedback = "✅ Correct!"
+	case answer.Accents:
+		feedback = "✅ Correct, but mind the accents:\n" + answerDiffText(result.Edits)
+	case answer.Typo:
+		feedback = "🟡 Almost! Your mistakes:\n" + answerDiffText(result.Edits)
+	default:
+		feedback = "❌ Not quite. Your mistakes:\n" + answerDiffText(result.Edits)
+	}
+
+	feedback += fmt.Sprintf("\n\n📝 *%s*", html.EscapeString(expected))
+	if expected != card.Word {
+		// Cloze cards ask for the form in the example
+		feedback += fmt.Sprintf(" (%s)", html.EscapeString(card.Word))
+	}
+	if card.Phonetic != "" {
+		feedback += " " + card.Phonetic
+	}
+
+	msg := tgbotapi.NewMessage(chatID, feedback)
+	msg.ParseMode = "HTML"
+	b.api.Send(msg)
+
+	b.sendPronunciation(chatID, &card.FlashCard)
+
+	reviewState.Latency = answerLatency(reviewState)
+	b.rateReviewCard(chatID, user, state, typedQuality(result.Verdict))
+}
+
+// typedQuality maps the grade of a typed answer to a rating
+func typedQuality(verdict answer.Verdict) int {
+	switch verdict {
+	case answer.Exact:
+		return spaced_repetition.QualityGood
+	case answer.Accents, answer.Typo:
+		return spaced_repetition.QualityHard
+	default:
+		return spaced_repetition.QualityAgain
+	}
+}
+
+// answerDiffText shows the mistakes of a typed answer: typed characters that are wrong
+// are struck through and missing ones underlined
+func answerDiffText(edits []answer.Edit) string {
+	var text strings.Builder
+	for _, edit := range edits {
+		escaped := html.EscapeString(edit.Text)
+		switch edit.Op {
+		case answer.Delete:
+			text.WriteString("<s>" + escaped + "</s>")
+		case answer.Insert:
+			text.WriteString("<u>" + escaped + "</u>")
+		default:
+			text.WriteString(escaped)
+		}
+	}
+	return text.String()
+}
+
+// maskWord hides a word and its inflected forms in a text, ignoring case
+func maskWord(text, word string) string {
+	if c, ok := cloze.Make(text, word); ok {
+		return c.Text
+	}
+	return text
+}
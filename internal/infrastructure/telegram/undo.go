@@ -0,0 +1,106 @@
+package telegram
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/supercakecrumb/flash-cards-language-tg-bot/internal/domain/models"
+	"github.com/supercakecrumb/flash-cards-language-tg-bot/internal/domain/services"
+)
+
+// LastRating is the latest rating of a review session, kept to undo it
+type LastRating struct {
+	Answer    models.ReviewAnswer
+	CardIndex int           // position of the rated card in the session's cards
+	Requeued  int           // learning cards brought back after the rating
+	Latency   time.Duration // of the rated answer
+}
+
+// handleUndoRating takes back the latest rating of a review session and returns to its card,
+// flipped so it can be rated again. args are the review log ID of the rating.
+func (b *Bot) handleUndoRating(update tgbotapi.Update, user *models.User, args []string) {
+	chatID := update.CallbackQuery.Message.Chat.ID
+
+	if len(args) < 1 {
+		b.logger.Error("Invalid undo callback data", "args", args)
+		return
+	}
+
+	logID, err := strconv.Atoi(args[0])
+	if err != nil {
+		b.logger.Error("Invalid review log ID in undo callback", "log_id", args[0])
+		return
+	}
+
+	// Only the latest rating of the current or just completed session can be undone
+	state, exists := b.getState(user.TelegramID)
+	if !exists || (state.State != "reviewing" && state.State != "review_finished") || state.ReviewState == nil ||
+		state.ReviewState.LastRating == nil || state.ReviewState.LastRating.Answer.LogID != logID {
+		b.sendMessage(chatID, "Only the latest rating of your review session can be undone.")
+		return
+	}
+
+	reviewState := state.ReviewState
+	last := reviewState.LastRating
+	if last.CardIndex >= len(reviewState.Cards) {
+		b.logger.Error("Undone card is not in the review session",
+			"user_id", user.ID,
+			"card_index", last.CardIndex,
+		)
+		return
+	}
+
+	err = b.spacedRepService.UndoReview(user.ID, &last.Answer)
+	if errors.Is(err, services.ErrNotFound) {
+		b.sendMessage(chatID, "This card has been answered again since, so the rating can't be undone.")
+		return
+	}
+	if err != nil {
+		b.logger.Error("Failed to undo review",
+			"error", err,
+			"user_id", user.ID,
+			"log_id", logID,
+		)
+		b.sendErrorMessage(chatID, "Failed to undo your rating. Please try again.")
+		return
+	}
+
+	// The streak is kept on purpose: the card is still reviewed today, it only gets rated again
+	err = b.statsService.DecrementReviewed(user.ID, reviewState.BankID)
+	if err != nil {
+		b.logger.Warn("Failed to update statistics",
+			"error", err,
+			"user_id", user.ID,
+			"bank_id", reviewState.BankID,
+		)
+	}
+
+	// Learning cards brought back by the rating come back again when they are due
+	start := last.CardIndex + 1
+	end := min(start+last.Requeued, len(reviewState.Cards))
+	reviewState.Cards = append(reviewState.Cards[:start], reviewState.Cards[end:]...)
+
+	reviewState.CurrentCard = last.CardIndex
+	reviewState.IsFlipped = true
+	reviewState.Latency = last.Latency
+	reviewState.LastRating = nil
+	state.State = "reviewing"
+	b.setState(user.TelegramID, state)
+
+	// The rating is gone, so is its undo button
+	removeUndo := tgbotapi.NewEditMessageReplyMarkup(chatID, update.CallbackQuery.Message.MessageID,
+		tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{}})
+	if _, err := b.api.Send(removeUndo); err != nil {
+		b.logger.Warn("Failed to remove undo button",
+			"error", err,
+			"log_id", logID,
+		)
+	}
+
+	card := reviewState.Cards[reviewState.CurrentCard]
+	b.sendMessage(chatID, fmt.Sprintf("↩️ Rating of *%s* undone. Rate it again:", card.Word))
+	b.showReviewCard(chatID, user, card, reviewState.Mode, true)
+}
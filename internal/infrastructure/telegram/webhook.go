@@ -0,0 +1,139 @@
+package telegram
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Webhook server limits
+const (
+	webhookMaxBodySize     = 1 << 20
+	webhookShutdownTimeout = 10 * time.Second
+)
+
+// secretTokenHeader carries the secret token Telegram was given when the webhook was set
+const secretTokenHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// WebhookConfig configures receiving updates through a webhook instead of long polling.
+// Several bot instances can serve the same URL behind a load balancer.
+type WebhookConfig struct {
+	URL         string // public HTTPS URL Telegram sends updates to
+	ListenAddr  string // address the HTTP server listens on
+	SecretToken string // expected in the secret token header of every update
+	CertFile    string // TLS certificate, leave empty when a reverse proxy terminates TLS
+	KeyFile     string // TLS private key
+}
+
+// serveWebhook registers the webhook and handles the updates Telegram posts until ctx is done
+func (b *Bot) serveWebhook(ctx context.Context) error {
+	webhookURL, err := url.Parse(b.webhook.URL)
+	if err != nil {
+		return err
+	}
+
+	path := webhookURL.Path
+	if path == "" {
+		path = "/"
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(path, b.handleWebhook)
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	server := &http.Server{
+		Addr:              b.webhook.ListenAddr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		var err error
+		if b.webhook.CertFile != "" {
+			err = server.ListenAndServeTLS(b.webhook.CertFile, b.webhook.KeyFile)
+		} else {
+			err = server.ListenAndServe()
+		}
+		if !errors.Is(err, http.ErrServerClosed) {
+			errCh <- err
+		}
+	}()
+
+	// Every instance registers the same URL, so setting it again is harmless
+	params := tgbotapi.Params{
+		"url":          b.webhook.URL,
+		"secret_token": b.webhook.SecretToken,
+	}
+	if _, err := b.api.MakeRequest("setWebhook", params); err != nil {
+		server.Close()
+		return err
+	}
+
+	b.logger.Info("Listening for webhook updates",
+		"addr", b.webhook.ListenAddr,
+		"path", path,
+		"tls", b.webhook.CertFile != "",
+	)
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	b.logger.Info("Stopping Telegram bot")
+
+	// The webhook stays registered so other instances keep receiving updates.
+	// Requests in flight are allowed to finish queueing their update.
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), webhookShutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		b.logger.Warn("Webhook server did not stop gracefully", "error", err)
+	}
+
+	return nil
+}
+
+// handleWebhook checks the secret token of an update posted by Telegram and queues it
+func (b *Bot) handleWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	token := r.Header.Get(secretTokenHeader)
+	if subtle.ConstantTimeCompare([]byte(token), []byte(b.webhook.SecretToken)) != 1 {
+		b.logger.Warn("Rejected webhook request with invalid secret token", "remote_addr", r.RemoteAddr)
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+
+	var update tgbotapi.Update
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, webhookMaxBodySize)).Decode(&update); err != nil {
+		b.logger.Warn("Failed to decode webhook update",
+			"error", err,
+			"remote_addr", r.RemoteAddr,
+		)
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+
+	// Telegram delivers the update again if it isn't acknowledged
+	if err := b.dispatcher.dispatch(r.Context(), update); err != nil {
+		b.logger.Warn("Dropped webhook update", "error", err, "update_id", update.UpdateID)
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
@@ -45,8 +45,8 @@ func NewCardBankRepository(db *sqlx.DB) CardBankRepository {
 // Create creates a new card bank
 func (r *cardBankRepository) Create(bank *models.CardBank) error {
 	query := `
-		INSERT INTO card_banks (name, description, owner_id, is_public, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO card_banks (name, description, owner_id, is_public, source_language, target_language, card_template, cloze_cards, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 		RETURNING id
 	`
 
@@ -56,6 +56,10 @@ func (r *cardBankRepository) Create(bank *models.CardBank) error {
 		bank.Description,
 		bank.OwnerID,
 		bank.IsPublic,
+		bank.SourceLanguage,
+		bank.TargetLanguage,
+		bank.CardTemplate,
+		bank.ClozeCards,
 		bank.CreatedAt,
 		bank.UpdatedAt,
 	).Scan(&bank.ID)
@@ -66,7 +70,7 @@ func (r *cardBankRepository) Create(bank *models.CardBank) error {
 // GetByID retrieves a card bank by ID
 func (r *cardBankRepository) GetByID(bankID int) (*models.CardBank, error) {
 	query := `
-		SELECT id, name, description, owner_id, is_public, created_at, updated_at
+		SELECT id, name, description, owner_id, is_public, source_language, target_language, card_template, cloze_cards, created_at, updated_at
 		FROM card_banks
 		WHERE id = $1
 	`
@@ -86,7 +90,8 @@ func (r *cardBankRepository) GetByID(bankID int) (*models.CardBank, error) {
 // GetBanksForUser retrieves all card banks a user has access to
 func (r *cardBankRepository) GetBanksForUser(userID int) ([]models.CardBank, error) {
 	query := `
-		SELECT cb.id, cb.name, cb.description, cb.owner_id, cb.is_public, cb.created_at, cb.updated_at
+		SELECT cb.id, cb.name, cb.description, cb.owner_id, cb.is_public, cb.source_language, cb.target_language,
+			cb.card_template, cb.cloze_cards, cb.created_at, cb.updated_at
 		FROM card_banks cb
 		JOIN bank_memberships bm ON cb.id = bm.card_bank_id
 		WHERE bm.user_id = $1
@@ -106,8 +111,8 @@ func (r *cardBankRepository) GetBanksForUser(userID int) ([]models.CardBank, err
 func (r *cardBankRepository) Update(bank *models.CardBank) error {
 	query := `
 		UPDATE card_banks
-		SET name = $1, description = $2, is_public = $3, updated_at = $4
-		WHERE id = $5
+		SET name = $1, description = $2, is_public = $3, source_language = $4, target_language = $5, card_template = $6, cloze_cards = $7, updated_at = $8
+		WHERE id = $9
 	`
 
 	bank.UpdatedAt = time.Now()
@@ -117,6 +122,10 @@ func (r *cardBankRepository) Update(bank *models.CardBank) error {
 		bank.Name,
 		bank.Description,
 		bank.IsPublic,
+		bank.SourceLanguage,
+		bank.TargetLanguage,
+		bank.CardTemplate,
+		bank.ClozeCards,
 		bank.UpdatedAt,
 		bank.ID,
 	)
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/supercakecrumb/flash-cards-language-tg-bot/internal/domain/models"
+)
+
+// ConversationStateRepository defines the interface for conversation state data access
+type ConversationStateRepository interface {
+	Get(telegramID int64) (*models.ConversationState, error)
+	Save(state *models.ConversationState) error
+	Delete(telegramID int64) error
+	DeleteExpired(now time.Time) (int64, error)
+}
+
+// conversationStateRepository implements the ConversationStateRepository interface
+type conversationStateRepository struct {
+	db *sqlx.DB
+}
+
+// NewConversationStateRepository creates a new conversation state repository
+func NewConversationStateRepository(db *sqlx.DB) ConversationStateRepository {
+	return &conversationStateRepository{
+		db: db,
+	}
+}
+
+// Get retrieves the conversation state of a user, expired states are treated as missing
+func (r *conversationStateRepository) Get(telegramID int64) (*models.ConversationState, error) {
+	query := `
+		SELECT telegram_id, state, expires_at, updated_at
+		FROM conversation_states
+		WHERE telegram_id = $1 AND expires_at > $2
+	`
+
+	var state models.ConversationState
+	err := r.db.Get(&state, query, telegramID, time.Now())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &state, nil
+}
+
+// Save creates or replaces the conversation state of a user
+func (r *conversationStateRepository) Save(state *models.ConversationState) error {
+	query := `
+		INSERT INTO conversation_states (telegram_id, state, expires_at, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (telegram_id) DO UPDATE
+		SET state = EXCLUDED.state, expires_at = EXCLUDED.expires_at, updated_at = EXCLUDED.updated_at
+	`
+
+	_, err := r.db.Exec(
+		query,
+		state.TelegramID,
+		state.State,
+		state.ExpiresAt,
+		state.UpdatedAt,
+	)
+
+	return err
+}
+
+// Delete deletes the conversation state of a user
+func (r *conversationStateRepository) Delete(telegramID int64) error {
+	query := `DELETE FROM conversation_states WHERE telegram_id = $1`
+	_, err := r.db.Exec(query, telegramID)
+	return err
+}
+
+// DeleteExpired deletes all conversation states that expired before now
+func (r *conversationStateRepository) DeleteExpired(now time.Time) (int64, error) {
+	query := `DELETE FROM conversation_states WHERE expires_at <= $1`
+
+	result, err := r.db.Exec(query, now)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
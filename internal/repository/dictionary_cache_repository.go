@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/supercakecrumb/flash-cards-language-tg-bot/internal/domain/models"
+)
+
+// DictionaryCacheRepository defines the interface for dictionary cache data access
+type DictionaryCacheRepository interface {
+	Get(key string) (*models.DictionaryCacheEntry, error)
+	Save(entry *models.DictionaryCacheEntry) error
+	DeleteExpired(now time.Time) (int64, error)
+}
+
+// dictionaryCacheRepository implements the DictionaryCacheRepository interface
+type dictionaryCacheRepository struct {
+	db *sqlx.DB
+}
+
+// NewDictionaryCacheRepository creates a new dictionary cache repository
+func NewDictionaryCacheRepository(db *sqlx.DB) DictionaryCacheRepository {
+	return &dictionaryCacheRepository{
+		db: db,
+	}
+}
+
+// Get retrieves a cached lookup, expired entries are treated as missing
+func (r *dictionaryCacheRepository) Get(key string) (*models.DictionaryCacheEntry, error) {
+	query := `
+		SELECT cache_key, definitions, expires_at, created_at
+		FROM dictionary_cache
+		WHERE cache_key = $1 AND expires_at > $2
+	`
+
+	var entry models.DictionaryCacheEntry
+	err := r.db.Get(&entry, query, key, time.Now())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+// Save creates or replaces a cached lookup
+func (r *dictionaryCacheRepository) Save(entry *models.DictionaryCacheEntry) error {
+	query := `
+		INSERT INTO dictionary_cache (cache_key, definitions, expires_at, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (cache_key) DO UPDATE
+		SET definitions = EXCLUDED.definitions, expires_at = EXCLUDED.expires_at, created_at = EXCLUDED.created_at
+	`
+
+	_, err := r.db.Exec(
+		query,
+		entry.Key,
+		entry.Definitions,
+		entry.ExpiresAt,
+		entry.CreatedAt,
+	)
+
+	return err
+}
+
+// DeleteExpired deletes all cached lookups that expired before now
+func (r *dictionaryCacheRepository) DeleteExpired(now time.Time) (int64, error) {
+	query := `DELETE FROM dictionary_cache WHERE expires_at <= $1`
+
+	result, err := r.db.Exec(query, now)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
@@ -2,7 +2,10 @@ package repository
 
 import (
 	"database/sql"
+	"fmt"
+	"strings"
 	"time"
+	"unicode"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/supercakecrumb/flash-cards-language-tg-bot/internal/domain/models"
@@ -14,7 +17,9 @@ type FlashCardRepository interface {
 	GetByID(cardID int) (*models.FlashCard, error)
 	GetByWord(word string, bankID int) (*models.FlashCard, error)
 	GetCardsForBank(bankID int) ([]models.FlashCard, error)
-	GetNewCards(userID, bankID, limit int) ([]models.FlashCard, error)
+	GetNewCards(userID, bankID, limit int) ([]models.ReviewCard, error)
+	GetDistractorCandidates(card *models.FlashCard, limit int) ([]models.FlashCard, error)
+	Search(search models.CardSearch) ([]models.FlashCard, int, error)
 	Update(card *models.FlashCard) error
 	Delete(cardID int) error
 }
@@ -34,8 +39,8 @@ func NewFlashCardRepository(db *sqlx.DB) FlashCardRepository {
 // Create creates a new flash card
 func (r *flashCardRepository) Create(card *models.FlashCard) error {
 	query := `
-		INSERT INTO flash_cards (card_bank_id, word, definition, examples, image_url, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO flash_cards (card_bank_id, word, definition, examples, image_url, phonetic, audio_url, audio_file_id, synonyms, antonyms, template, part_of_speech, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 		RETURNING id
 	`
 
@@ -46,6 +51,13 @@ func (r *flashCardRepository) Create(card *models.FlashCard) error {
 		card.Definition,
 		card.Examples,
 		card.ImageURL,
+		card.Phonetic,
+		card.AudioURL,
+		card.AudioFileID,
+		card.Synonyms,
+		card.Antonyms,
+		card.Template,
+		card.PartOfSpeech,
 		card.CreatedAt,
 		card.UpdatedAt,
 	).Scan(&card.ID)
@@ -56,7 +68,7 @@ func (r *flashCardRepository) Create(card *models.FlashCard) error {
 // GetByID retrieves a flash card by ID
 func (r *flashCardRepository) GetByID(cardID int) (*models.FlashCard, error) {
 	query := `
-		SELECT id, card_bank_id, word, definition, examples, image_url, created_at, updated_at
+		SELECT id, card_bank_id, word, definition, examples, image_url, phonetic, audio_url, audio_file_id, synonyms, antonyms, template, part_of_speech, created_at, updated_at
 		FROM flash_cards
 		WHERE id = $1
 	`
@@ -76,7 +88,7 @@ func (r *flashCardRepository) GetByID(cardID int) (*models.FlashCard, error) {
 // GetByWord retrieves a flash card by word and bank ID
 func (r *flashCardRepository) GetByWord(word string, bankID int) (*models.FlashCard, error) {
 	query := `
-		SELECT id, card_bank_id, word, definition, examples, image_url, created_at, updated_at
+		SELECT id, card_bank_id, word, definition, examples, image_url, phonetic, audio_url, audio_file_id, synonyms, antonyms, template, part_of_speech, created_at, updated_at
 		FROM flash_cards
 		WHERE word = $1 AND card_bank_id = $2
 	`
@@ -96,7 +108,7 @@ func (r *flashCardRepository) GetByWord(word string, bankID int) (*models.FlashC
 // GetCardsForBank retrieves all flash cards in a bank
 func (r *flashCardRepository) GetCardsForBank(bankID int) ([]models.FlashCard, error) {
 	query := `
-		SELECT id, card_bank_id, word, definition, examples, image_url, created_at, updated_at
+		SELECT id, card_bank_id, word, definition, examples, image_url, phonetic, audio_url, audio_file_id, synonyms, antonyms, template, part_of_speech, created_at, updated_at
 		FROM flash_cards
 		WHERE card_bank_id = $1
 		ORDER BY created_at DESC
@@ -111,18 +123,30 @@ func (r *flashCardRepository) GetCardsForBank(bankID int) ([]models.FlashCard, e
 	return cards, nil
 }
 
-// GetNewCards retrieves cards that the user hasn't reviewed yet
-func (r *flashCardRepository) GetNewCards(userID, bankID, limit int) ([]models.FlashCard, error) {
+// GetNewCards retrieves the directions of cards that the user hasn't reviewed yet,
+// out of the directions each card's template generates. Cards with examples also have
+// a cloze direction if their bank uses cloze cards.
+func (r *flashCardRepository) GetNewCards(userID, bankID, limit int) ([]models.ReviewCard, error) {
 	query := `
-		SELECT fc.id, fc.card_bank_id, fc.word, fc.definition, fc.examples, fc.image_url, fc.created_at, fc.updated_at
+		SELECT fc.id, fc.card_bank_id, fc.word, fc.definition, fc.examples, fc.image_url, fc.phonetic, fc.audio_url, fc.audio_file_id, fc.synonyms, fc.antonyms, fc.template, fc.part_of_speech, fc.created_at, fc.updated_at,
+			d.direction
 		FROM flash_cards fc
-		LEFT JOIN reviews r ON fc.id = r.flash_card_id AND r.user_id = $1
+		JOIN card_banks cb ON fc.card_bank_id = cb.id
+		CROSS JOIN LATERAL unnest(CASE COALESCE(NULLIF(fc.template, ''), cb.card_template)
+			WHEN 'forward' THEN ARRAY['forward']
+			WHEN 'both' THEN ARRAY['forward', 'reverse']
+			ELSE ARRAY['reverse']
+		END || CASE WHEN cb.cloze_cards AND jsonb_array_length(fc.examples) > 0
+			THEN ARRAY['cloze']
+			ELSE ARRAY[]::TEXT[]
+		END) WITH ORDINALITY AS d(direction, position)
+		LEFT JOIN reviews r ON fc.id = r.flash_card_id AND r.user_id = $1 AND r.direction = d.direction
 		WHERE fc.card_bank_id = $2 AND r.id IS NULL
-		ORDER BY fc.created_at DESC
+		ORDER BY fc.created_at DESC, d.position ASC
 		LIMIT $3
 	`
 
-	var cards []models.FlashCard
+	var cards []models.ReviewCard
 	err := r.db.Select(&cards, query, userID, bankID, limit)
 	if err != nil {
 		return nil, err
@@ -131,12 +155,105 @@ func (r *flashCardRepository) GetNewCards(userID, bankID, limit int) ([]models.F
 	return cards, nil
 }
 
+// GetDistractorCandidates retrieves random cards of the same bank with other words than a card,
+// those with the same part of speech first
+func (r *flashCardRepository) GetDistractorCandidates(card *models.FlashCard, limit int) ([]models.FlashCard, error) {
+	query := `
+		SELECT id, card_bank_id, word, definition, examples, image_url, phonetic, audio_url, audio_file_id, synonyms, antonyms, template, part_of_speech, created_at, updated_at
+		FROM flash_cards
+		WHERE card_bank_id = $1 AND id <> $2 AND LOWER(word) <> LOWER($3)
+		ORDER BY (part_of_speech <> '' AND part_of_speech = $4) DESC, RANDOM()
+		LIMIT $5
+	`
+
+	var cards []models.FlashCard
+	err := r.db.Select(&cards, query, card.CardBankID, card.ID, card.Word, card.PartOfSpeech, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return cards, nil
+}
+
+// Search retrieves a page of the cards in a bank that match a search, with the number of matching cards.
+// Cards are ordered by relevance when searching for text and by word otherwise.
+func (r *flashCardRepository) Search(search models.CardSearch) ([]models.FlashCard, int, error) {
+	conditions := []string{"fc.card_bank_id = $1"}
+	args := []interface{}{search.BankID, search.UserID}
+	order := "fc.word ASC"
+
+	if query := searchQuery(search.Text); query != "" {
+		args = append(args, query)
+		conditions = append(conditions, fmt.Sprintf("fc.search_vector @@ to_tsquery('simple', $%d)", len(args)))
+		order = fmt.Sprintf("ts_rank(fc.search_vector, to_tsquery('simple', $%d)) DESC, fc.word ASC", len(args))
+	}
+
+	// A card has a review for every direction, filters match cards with any review that matches
+	reviewExists := func(condition string) string {
+		return "EXISTS (SELECT 1 FROM reviews r WHERE r.flash_card_id = fc.id AND r.user_id = $2 AND " + condition + ")"
+	}
+
+	switch search.Filter {
+	case models.CardFilterNew:
+		args = append(args, models.ReviewStateNew)
+		conditions = append(conditions, "NOT "+reviewExists(fmt.Sprintf("r.state <> $%d", len(args))))
+	case models.CardFilterLearning:
+		args = append(args, models.ReviewStateLearning, models.ReviewStateRelearning)
+		conditions = append(conditions, reviewExists(fmt.Sprintf("r.state IN ($%d, $%d)", len(args)-1, len(args))))
+	case models.CardFilterDue:
+		args = append(args, models.ReviewStateNew, time.Now())
+		conditions = append(conditions, reviewExists(fmt.Sprintf("r.state <> $%d AND r.due_date <= $%d", len(args)-1, len(args))))
+	case models.CardFilterMature:
+		args = append(args, models.ReviewStateReview, models.MatureInterval)
+		conditions = append(conditions, reviewExists(fmt.Sprintf("r.state = $%d AND r.interval >= $%d", len(args)-1, len(args))))
+	}
+
+	from := `
+		FROM flash_cards fc
+		WHERE ` + strings.Join(conditions, " AND ")
+
+	var total int
+	err := r.db.Get(&total, "SELECT COUNT(*)"+from, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT fc.id, fc.card_bank_id, fc.word, fc.definition, fc.examples, fc.image_url, fc.phonetic, fc.audio_url, fc.audio_file_id, fc.synonyms, fc.antonyms, fc.template, fc.part_of_speech, fc.created_at, fc.updated_at` + from +
+		fmt.Sprintf(`
+		ORDER BY %s
+		LIMIT $%d OFFSET $%d
+	`, order, len(args)+1, len(args)+2)
+
+	var cards []models.FlashCard
+	err = r.db.Select(&cards, query, append(args, search.Limit, search.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return cards, total, nil
+}
+
+// searchQuery turns search text into a tsquery matching cards that contain all of its words,
+// the last one also as a prefix. Anything but letters and digits is dropped.
+func searchQuery(text string) string {
+	terms := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(terms) == 0 {
+		return ""
+	}
+
+	terms[len(terms)-1] += ":*"
+	return strings.Join(terms, " & ")
+}
+
 // Update updates an existing flash card
 func (r *flashCardRepository) Update(card *models.FlashCard) error {
 	query := `
 		UPDATE flash_cards
-		SET word = $1, definition = $2, examples = $3, image_url = $4, updated_at = $5
-		WHERE id = $6
+		SET word = $1, definition = $2, examples = $3, image_url = $4, phonetic = $5, audio_url = $6, audio_file_id = $7, synonyms = $8, antonyms = $9, template = $10, part_of_speech = $11, updated_at = $12
+		WHERE id = $13
 	`
 
 	card.UpdatedAt = time.Now()
@@ -147,6 +264,13 @@ func (r *flashCardRepository) Update(card *models.FlashCard) error {
 		card.Definition,
 		card.Examples,
 		card.ImageURL,
+		card.Phonetic,
+		card.AudioURL,
+		card.AudioFileID,
+		card.Synonyms,
+		card.Antonyms,
+		card.Template,
+		card.PartOfSpeech,
 		card.UpdatedAt,
 		card.ID,
 	)
@@ -0,0 +1,257 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/supercakecrumb/flash-cards-language-tg-bot/internal/domain/models"
+)
+
+// ReviewLogRepository defines the interface for review history data access
+type ReviewLogRepository interface {
+	Create(log *models.ReviewLog) error
+	CreateWithReview(review *models.Review, log *models.ReviewLog) error
+	GetByUser(userID, limit int) ([]models.ReviewLog, error)
+	GetByUserAndCard(userID, cardID int) ([]models.ReviewLog, error)
+	GetByUserSince(userID int, since time.Time) ([]models.ReviewLog, error)
+	CountByUserSince(userID int, since time.Time) (int, error)
+	CountDailyActivity(userID, bankID int, since time.Time) (int, int, error) // new cards, reviews
+	GetLatencyStats(userID, bankID, limit, offset int) (*models.LatencyStats, error)
+	DeleteLatest(logID int, previous *models.Review) error
+}
+
+// reviewLogRepository implements the ReviewLogRepository interface
+type reviewLogRepository struct {
+	db *sqlx.DB
+}
+
+// NewReviewLogRepository creates a new review log repository
+func NewReviewLogRepository(db *sqlx.DB) ReviewLogRepository {
+	return &reviewLogRepository{
+		db: db,
+	}
+}
+
+// Create appends a new entry to the review log
+func (r *reviewLogRepository) Create(log *models.ReviewLog) error {
+	return insertReviewLog(r.db, log)
+}
+
+// CreateWithReview saves an answered review and appends the answer to the review log
+// in one transaction, so the review never advances without its history
+func (r *reviewLogRepository) CreateWithReview(review *models.Review, log *models.ReviewLog) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := updateReview(tx, review); err != nil {
+		return err
+	}
+	if err := insertReviewLog(tx, log); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// insertReviewLog appends an entry to the review log with the given database or transaction
+func insertReviewLog(db sqlx.Queryer, log *models.ReviewLog) error {
+	query := `
+		INSERT INTO review_log (user_id, flash_card_id, direction, rating, elapsed_days, previous_interval, new_interval, previous_ease_factor, new_ease_factor, previous_state, new_state, latency_ms, answered_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING id
+	`
+
+	err := db.QueryRowx(
+		query,
+		log.UserID,
+		log.FlashCardID,
+		log.Direction,
+		log.Rating,
+		log.ElapsedDays,
+		log.PreviousInterval,
+		log.NewInterval,
+		log.PreviousEaseFactor,
+		log.NewEaseFactor,
+		log.PreviousState,
+		log.NewState,
+		log.LatencyMs,
+		log.AnsweredAt,
+	).Scan(&log.ID)
+
+	return err
+}
+
+// GetByUser retrieves the most recent review log entries for a user
+func (r *reviewLogRepository) GetByUser(userID, limit int) ([]models.ReviewLog, error) {
+	query := `
+		SELECT id, user_id, flash_card_id, direction, rating, elapsed_days, previous_interval, new_interval, previous_ease_factor, new_ease_factor, previous_state, new_state, latency_ms, answered_at
+		FROM review_log
+		WHERE user_id = $1
+		ORDER BY answered_at DESC
+		LIMIT $2
+	`
+
+	var logs []models.ReviewLog
+	err := r.db.Select(&logs, query, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return logs, nil
+}
+
+// GetByUserAndCard retrieves the full review history of a card for a user
+func (r *reviewLogRepository) GetByUserAndCard(userID, cardID int) ([]models.ReviewLog, error) {
+	query := `
+		SELECT id, user_id, flash_card_id, direction, rating, elapsed_days, previous_interval, new_interval, previous_ease_factor, new_ease_factor, previous_state, new_state, latency_ms, answered_at
+		FROM review_log
+		WHERE user_id = $1 AND flash_card_id = $2
+		ORDER BY answered_at ASC
+	`
+
+	var logs []models.ReviewLog
+	err := r.db.Select(&logs, query, userID, cardID)
+	if err != nil {
+		return nil, err
+	}
+
+	return logs, nil
+}
+
+// GetByUserSince retrieves review log entries for a user answered since the given time
+func (r *reviewLogRepository) GetByUserSince(userID int, since time.Time) ([]models.ReviewLog, error) {
+	query := `
+		SELECT id, user_id, flash_card_id, direction, rating, elapsed_days, previous_interval, new_interval, previous_ease_factor, new_ease_factor, previous_state, new_state, latency_ms, answered_at
+		FROM review_log
+		WHERE user_id = $1 AND answered_at >= $2
+		ORDER BY answered_at ASC
+	`
+
+	var logs []models.ReviewLog
+	err := r.db.Select(&logs, query, userID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	return logs, nil
+}
+
+// CountByUserSince counts review log entries for a user answered since the given time
+func (r *reviewLogRepository) CountByUserSince(userID int, since time.Time) (int, error) {
+	query := `SELECT COUNT(*) FROM review_log WHERE user_id = $1 AND answered_at >= $2`
+
+	var count int
+	err := r.db.Get(&count, query, userID, since)
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// CountDailyActivity counts new cards introduced and review cards answered in a bank since the given time
+func (r *reviewLogRepository) CountDailyActivity(userID, bankID int, since time.Time) (int, int, error) {
+	query := `
+		SELECT
+			COUNT(*) FILTER (WHERE l.previous_state = 'new') AS new_cards,
+			COUNT(*) FILTER (WHERE l.previous_state = 'review') AS reviews
+		FROM review_log l
+		JOIN flash_cards fc ON l.flash_card_id = fc.id
+		WHERE l.user_id = $1 AND fc.card_bank_id = $2 AND l.answered_at >= $3
+	`
+
+	var counts struct {
+		NewCards int `db:"new_cards"`
+		Reviews  int `db:"reviews"`
+	}
+	err := r.db.Get(&counts, query, userID, bankID, since)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return counts.NewCards, counts.Reviews, nil
+}
+
+// GetLatencyStats averages the answer times of a user in a bank overall and per card,
+// keeping a page of the cards ordered from slowest to fastest
+func (r *reviewLogRepository) GetLatencyStats(userID, bankID, limit, offset int) (*models.LatencyStats, error) {
+	query := `
+		SELECT COUNT(*) AS answers, COALESCE(ROUND(AVG(LEAST(l.latency_ms, $3))), 0)::INTEGER AS average_ms,
+			COUNT(DISTINCT l.flash_card_id) AS card_count
+		FROM review_log l
+		JOIN flash_cards fc ON l.flash_card_id = fc.id
+		WHERE l.user_id = $1 AND fc.card_bank_id = $2 AND l.latency_ms > 0
+	`
+
+	var stats models.LatencyStats
+	var overall struct {
+		Answers   int `db:"answers"`
+		AverageMs int `db:"average_ms"`
+		CardCount int `db:"card_count"`
+	}
+	err := r.db.Get(&overall, query, userID, bankID, models.LatencyCapMs)
+	if err != nil {
+		return nil, err
+	}
+	stats.Answers = overall.Answers
+	stats.AverageMs = overall.AverageMs
+	stats.CardCount = overall.CardCount
+
+	query = `
+		SELECT l.flash_card_id, fc.word, COUNT(*) AS answers, ROUND(AVG(LEAST(l.latency_ms, $3)))::INTEGER AS average_ms
+		FROM review_log l
+		JOIN flash_cards fc ON l.flash_card_id = fc.id
+		WHERE l.user_id = $1 AND fc.card_bank_id = $2 AND l.latency_ms > 0
+		GROUP BY l.flash_card_id, fc.word
+		ORDER BY average_ms DESC, l.flash_card_id
+		LIMIT $4 OFFSET $5
+	`
+
+	err = r.db.Select(&stats.Cards, query, userID, bankID, models.LatencyCapMs, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return &stats, nil
+}
+
+// DeleteLatest deletes a review log entry if it is the latest answer of its user, card and direction,
+// and restores the review to its state before the answer in the same transaction.
+// It returns ErrNotFound if the entry doesn't exist or was answered again since.
+func (r *reviewLogRepository) DeleteLatest(logID int, previous *models.Review) error {
+	query := `
+		DELETE FROM review_log l
+		WHERE l.id = $1 AND NOT EXISTS (
+			SELECT 1 FROM review_log later
+			WHERE later.user_id = l.user_id AND later.flash_card_id = l.flash_card_id AND later.direction = l.direction AND later.id > l.id
+		)
+	`
+
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(query, logID)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrNotFound
+	}
+
+	if err := updateReview(tx, previous); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
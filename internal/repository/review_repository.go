@@ -12,8 +12,12 @@ import (
 type ReviewRepository interface {
 	Create(review *models.Review) error
 	GetByID(reviewID int) (*models.Review, error)
-	GetByUserAndCard(userID, cardID int) (*models.Review, error)
+	GetByUserAndCard(userID, cardID int) ([]models.Review, error)
+	GetByUserCardAndDirection(userID, cardID int, direction string) (*models.Review, error)
+	GetByUserAndBank(userID, bankID int) ([]models.Review, error)
 	GetDueReviews(userID, bankID int, dueDate time.Time, limit int) ([]models.Review, error)
+	GetDueLearningReviews(userID, bankID int, dueDate time.Time) ([]models.Review, error)
+	GetDueReviewsInState(userID, bankID int, state string, dueDate time.Time, limit int) ([]models.Review, error)
 	Update(review *models.Review) error
 	Delete(reviewID int) error
 	CountTotalReviews(userID int) (int, error)
@@ -21,6 +25,12 @@ type ReviewRepository interface {
 	GetLastReviewDate(userID int) (string, error)
 }
 
+// enabledDirection limits reviews joined with their card (fc) and bank (cb) to the directions
+// the card's template generates and cloze cards of banks that use them, so directions turned off
+// keep their progress but aren't shown
+const enabledDirection = `CASE WHEN r.direction = 'cloze' THEN cb.cloze_cards
+		ELSE COALESCE(NULLIF(fc.template, ''), cb.card_template) IN (r.direction, 'both') END`
+
 // reviewRepository implements the ReviewRepository interface
 type reviewRepository struct {
 	db *sqlx.DB
@@ -36,8 +46,8 @@ func NewReviewRepository(db *sqlx.DB) ReviewRepository {
 // Create creates a new review
 func (r *reviewRepository) Create(review *models.Review) error {
 	query := `
-		INSERT INTO reviews (user_id, flash_card_id, ease_factor, due_date, interval, repetitions, last_reviewed, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO reviews (user_id, flash_card_id, direction, ease_factor, due_date, interval, repetitions, stability, difficulty, state, step, last_reviewed, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 		RETURNING id
 	`
 
@@ -45,10 +55,15 @@ func (r *reviewRepository) Create(review *models.Review) error {
 		query,
 		review.UserID,
 		review.FlashCardID,
+		review.Direction,
 		review.EaseFactor,
 		review.DueDate,
 		review.Interval,
 		review.Repetitions,
+		review.Stability,
+		review.Difficulty,
+		review.State,
+		review.Step,
 		review.LastReviewed,
 		review.CreatedAt,
 		review.UpdatedAt,
@@ -60,7 +75,7 @@ func (r *reviewRepository) Create(review *models.Review) error {
 // GetByID retrieves a review by ID
 func (r *reviewRepository) GetByID(reviewID int) (*models.Review, error) {
 	query := `
-		SELECT id, user_id, flash_card_id, ease_factor, due_date, interval, repetitions, last_reviewed, created_at, updated_at
+		SELECT id, user_id, flash_card_id, direction, ease_factor, due_date, interval, repetitions, stability, difficulty, state, step, last_reviewed, created_at, updated_at
 		FROM reviews
 		WHERE id = $1
 	`
@@ -77,16 +92,34 @@ func (r *reviewRepository) GetByID(reviewID int) (*models.Review, error) {
 	return &review, nil
 }
 
-// GetByUserAndCard retrieves a review by user ID and card ID
-func (r *reviewRepository) GetByUserAndCard(userID, cardID int) (*models.Review, error) {
+// GetByUserAndCard retrieves the reviews of a card in every direction for a user
+func (r *reviewRepository) GetByUserAndCard(userID, cardID int) ([]models.Review, error) {
 	query := `
-		SELECT id, user_id, flash_card_id, ease_factor, due_date, interval, repetitions, last_reviewed, created_at, updated_at
+		SELECT id, user_id, flash_card_id, direction, ease_factor, due_date, interval, repetitions, stability, difficulty, state, step, last_reviewed, created_at, updated_at
 		FROM reviews
 		WHERE user_id = $1 AND flash_card_id = $2
+		ORDER BY direction ASC
+	`
+
+	var reviews []models.Review
+	err := r.db.Select(&reviews, query, userID, cardID)
+	if err != nil {
+		return nil, err
+	}
+
+	return reviews, nil
+}
+
+// GetByUserCardAndDirection retrieves a review by user ID, card ID and direction
+func (r *reviewRepository) GetByUserCardAndDirection(userID, cardID int, direction string) (*models.Review, error) {
+	query := `
+		SELECT id, user_id, flash_card_id, direction, ease_factor, due_date, interval, repetitions, stability, difficulty, state, step, last_reviewed, created_at, updated_at
+		FROM reviews
+		WHERE user_id = $1 AND flash_card_id = $2 AND direction = $3
 	`
 
 	var review models.Review
-	err := r.db.Get(&review, query, userID, cardID)
+	err := r.db.Get(&review, query, userID, cardID, direction)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, ErrNotFound
@@ -97,13 +130,32 @@ func (r *reviewRepository) GetByUserAndCard(userID, cardID int) (*models.Review,
 	return &review, nil
 }
 
+// GetByUserAndBank retrieves all reviews of a user for the cards in a bank
+func (r *reviewRepository) GetByUserAndBank(userID, bankID int) ([]models.Review, error) {
+	query := `
+		SELECT r.id, r.user_id, r.flash_card_id, r.direction, r.ease_factor, r.due_date, r.interval, r.repetitions, r.stability, r.difficulty, r.state, r.step, r.last_reviewed, r.created_at, r.updated_at
+		FROM reviews r
+		JOIN flash_cards fc ON r.flash_card_id = fc.id
+		WHERE r.user_id = $1 AND fc.card_bank_id = $2
+	`
+
+	var reviews []models.Review
+	err := r.db.Select(&reviews, query, userID, bankID)
+	if err != nil {
+		return nil, err
+	}
+
+	return reviews, nil
+}
+
 // GetDueReviews retrieves reviews that are due for a user
 func (r *reviewRepository) GetDueReviews(userID, bankID int, dueDate time.Time, limit int) ([]models.Review, error) {
 	query := `
-		SELECT r.id, r.user_id, r.flash_card_id, r.ease_factor, r.due_date, r.interval, r.repetitions, r.last_reviewed, r.created_at, r.updated_at
+		SELECT r.id, r.user_id, r.flash_card_id, r.direction, r.ease_factor, r.due_date, r.interval, r.repetitions, r.stability, r.difficulty, r.state, r.step, r.last_reviewed, r.created_at, r.updated_at
 		FROM reviews r
 		JOIN flash_cards fc ON r.flash_card_id = fc.id
-		WHERE r.user_id = $1 AND fc.card_bank_id = $2 AND r.due_date <= $3
+		JOIN card_banks cb ON fc.card_bank_id = cb.id
+		WHERE r.user_id = $1 AND fc.card_bank_id = $2 AND r.due_date <= $3 AND ` + enabledDirection + `
 		ORDER BY r.due_date ASC
 		LIMIT $4
 	`
@@ -117,22 +169,72 @@ func (r *reviewRepository) GetDueReviews(userID, bankID int, dueDate time.Time,
 	return reviews, nil
 }
 
+// GetDueLearningReviews retrieves learning and relearning reviews that are due for a user
+func (r *reviewRepository) GetDueLearningReviews(userID, bankID int, dueDate time.Time) ([]models.Review, error) {
+	query := `
+		SELECT r.id, r.user_id, r.flash_card_id, r.direction, r.ease_factor, r.due_date, r.interval, r.repetitions, r.stability, r.difficulty, r.state, r.step, r.last_reviewed, r.created_at, r.updated_at
+		FROM reviews r
+		JOIN flash_cards fc ON r.flash_card_id = fc.id
+		JOIN card_banks cb ON fc.card_bank_id = cb.id
+		WHERE r.user_id = $1 AND fc.card_bank_id = $2 AND r.state IN ('learning', 'relearning') AND r.due_date <= $3 AND ` + enabledDirection + `
+		ORDER BY r.due_date ASC
+	`
+
+	var reviews []models.Review
+	err := r.db.Select(&reviews, query, userID, bankID, dueDate)
+	if err != nil {
+		return nil, err
+	}
+
+	return reviews, nil
+}
+
+// GetDueReviewsInState retrieves due reviews in the given state for a user
+func (r *reviewRepository) GetDueReviewsInState(userID, bankID int, state string, dueDate time.Time, limit int) ([]models.Review, error) {
+	query := `
+		SELECT r.id, r.user_id, r.flash_card_id, r.direction, r.ease_factor, r.due_date, r.interval, r.repetitions, r.stability, r.difficulty, r.state, r.step, r.last_reviewed, r.created_at, r.updated_at
+		FROM reviews r
+		JOIN flash_cards fc ON r.flash_card_id = fc.id
+		JOIN card_banks cb ON fc.card_bank_id = cb.id
+		WHERE r.user_id = $1 AND fc.card_bank_id = $2 AND r.state = $3 AND r.due_date <= $4 AND ` + enabledDirection + `
+		ORDER BY r.due_date ASC
+		LIMIT $5
+	`
+
+	var reviews []models.Review
+	err := r.db.Select(&reviews, query, userID, bankID, state, dueDate, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return reviews, nil
+}
+
 // Update updates an existing review
 func (r *reviewRepository) Update(review *models.Review) error {
+	return updateReview(r.db, review)
+}
+
+// updateReview saves a review with the given database or transaction
+func updateReview(db sqlx.Execer, review *models.Review) error {
 	query := `
 		UPDATE reviews
-		SET ease_factor = $1, due_date = $2, interval = $3, repetitions = $4, last_reviewed = $5, updated_at = $6
-		WHERE id = $7
+		SET ease_factor = $1, due_date = $2, interval = $3, repetitions = $4, stability = $5, difficulty = $6, state = $7, step = $8, last_reviewed = $9, updated_at = $10
+		WHERE id = $11
 	`
 
 	review.UpdatedAt = time.Now()
 
-	_, err := r.db.Exec(
+	_, err := db.Exec(
 		query,
 		review.EaseFactor,
 		review.DueDate,
 		review.Interval,
 		review.Repetitions,
+		review.Stability,
+		review.Difficulty,
+		review.State,
+		review.Step,
 		review.LastReviewed,
 		review.UpdatedAt,
 		review.ID,
@@ -148,9 +250,15 @@ func (r *reviewRepository) Delete(reviewID int) error {
 	return err
 }
 
-// CountTotalReviews counts the total number of reviews for a user
+// CountTotalReviews counts the reviews of a user, one per card and direction that is reviewed
 func (r *reviewRepository) CountTotalReviews(userID int) (int, error) {
-	query := `SELECT COUNT(*) FROM reviews WHERE user_id = $1`
+	query := `
+		SELECT COUNT(DISTINCT (r.flash_card_id, r.direction))
+		FROM reviews r
+		JOIN flash_cards fc ON r.flash_card_id = fc.id
+		JOIN card_banks cb ON fc.card_bank_id = cb.id
+		WHERE r.user_id = $1 AND ` + enabledDirection + `
+	`
 
 	var count int
 	err := r.db.Get(&count, query, userID)
@@ -161,9 +269,16 @@ func (r *reviewRepository) CountTotalReviews(userID int) (int, error) {
 	return count, nil
 }
 
-// CountDueReviews counts the number of due reviews for a user
+// CountDueReviews counts the number of due reviews for a user, one per card and direction
+// as they are queued for review
 func (r *reviewRepository) CountDueReviews(userID int, dueDate time.Time) (int, error) {
-	query := `SELECT COUNT(*) FROM reviews WHERE user_id = $1 AND due_date <= $2`
+	query := `
+		SELECT COUNT(DISTINCT (r.flash_card_id, r.direction))
+		FROM reviews r
+		JOIN flash_cards fc ON r.flash_card_id = fc.id
+		JOIN card_banks cb ON fc.card_bank_id = cb.id
+		WHERE r.user_id = $1 AND r.due_date <= $2 AND ` + enabledDirection + `
+	`
 
 	var count int
 	err := r.db.Get(&count, query, userID, dueDate)
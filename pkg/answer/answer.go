@@ -0,0 +1,224 @@
+// Package answer grades typed answers against the expected word, forgiving case, accents and small typos.
+package answer
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Verdict is how close a typed answer is to the expected one
+type Verdict int
+
+// Verdicts, from worst to best
+const (
+	Wrong   Verdict = iota // more mistakes than the tolerance
+	Typo                   // within the tolerance of the expected answer
+	Accents                // only accents or other diacritics are wrong
+	Exact                  // the same apart from case, punctuation and spacing
+)
+
+// Op is the kind of an edit between a typed and an expected answer
+type Op int
+
+// Edit operations
+const (
+	Equal  Op = iota // typed as expected
+	Delete           // typed but not expected
+	Insert           // expected but not typed
+)
+
+// Edit is a run of text that is equal, typed by mistake or missing in a typed answer
+type Edit struct {
+	Op   Op
+	Text string
+}
+
+// Result is the grade of a typed answer
+type Result struct {
+	Verdict  Verdict
+	Distance int    // edits between the answer and the expected answer, ignoring accents
+	Edits    []Edit // turn the typed answer into the expected one, accents included
+}
+
+// Check grades a typed answer against the expected answer
+func Check(typed, expected string) Result {
+	a, e := clean(typed), clean(expected)
+	foldedA, foldedE := fold(a), fold(e)
+
+	result := Result{
+		Distance: Distance(foldedA, foldedE),
+		Edits:    Diff(a, e),
+	}
+
+	switch {
+	case a == e:
+		result.Verdict = Exact
+	case foldedA == foldedE:
+		result.Verdict = Accents
+	case a != "" && result.Distance <= Tolerance(foldedE):
+		result.Verdict = Typo
+	default:
+		result.Verdict = Wrong
+	}
+
+	return result
+}
+
+// Tolerance returns the number of mistakes forgiven in an answer: none in short words,
+// then one for every four letters
+func Tolerance(expected string) int {
+	return len([]rune(expected)) / 4
+}
+
+// clean lowercases text, drops apostrophes, turns other punctuation into spaces and collapses spacing
+func clean(text string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case r == '\'' || r == '’' || r == '`':
+			continue
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r):
+			b.WriteRune(r)
+		default:
+			b.WriteRune(' ')
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// diacritics maps lowercase letters with diacritics to their base letters
+var diacritics = map[rune]string{
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'ā': "a", 'ă': "a", 'ą': "a",
+	'æ': "ae",
+	'ç': "c", 'ć': "c", 'ĉ': "c", 'ċ': "c", 'č': "c",
+	'ď': "d", 'đ': "d", 'ð': "d",
+	'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ē': "e", 'ĕ': "e", 'ė': "e", 'ę': "e", 'ě': "e",
+	'ĝ': "g", 'ğ': "g", 'ġ': "g", 'ģ': "g",
+	'ĥ': "h", 'ħ': "h",
+	'ì': "i", 'í': "i", 'î': "i", 'ï': "i", 'ĩ': "i", 'ī': "i", 'ĭ': "i", 'į': "i", 'ı': "i",
+	'ĵ': "j",
+	'ķ': "k",
+	'ĺ': "l", 'ļ': "l", 'ľ': "l", 'ŀ': "l", 'ł': "l",
+	'ñ': "n", 'ń': "n", 'ņ': "n", 'ň': "n",
+	'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o", 'ō': "o", 'ŏ': "o", 'ő': "o",
+	'œ': "oe",
+	'ŕ': "r", 'ŗ': "r", 'ř': "r",
+	'ś': "s", 'ŝ': "s", 'ş': "s", 'š': "s", 'ș': "s",
+	'ß': "ss",
+	'ţ': "t", 'ť': "t", 'ŧ': "t", 'ț': "t",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ũ': "u", 'ū': "u", 'ŭ': "u", 'ů': "u", 'ű': "u", 'ų': "u",
+	'ŵ': "w",
+	'ý': "y", 'ÿ': "y", 'ŷ': "y",
+	'ź': "z", 'ż': "z", 'ž': "z",
+	'ё': "е", 'й': "и",
+}
+
+// fold replaces letters with diacritics by their base letters and drops combining marks
+func fold(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		if unicode.IsMark(r) {
+			continue
+		}
+		if base, ok := diacritics[r]; ok {
+			b.WriteString(base)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Distance returns the edit distance between two strings, counted in characters.
+// Swapping two adjacent characters is a single edit, as it is a common typo.
+func Distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	// Rows of the distance matrix, two before the current one are needed for swaps
+	beforePrevious := make([]int, len(rb)+1)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				current[j] = min(current[j], beforePrevious[j-2]+1)
+			}
+		}
+		beforePrevious, previous, current = previous, current, beforePrevious
+	}
+
+	return previous[len(rb)]
+}
+
+// Diff returns the edits that turn a typed answer into the expected one, merging runs of the same kind.
+// A wrong character is a deletion followed by an insertion.
+func Diff(typed, expected string) []Edit {
+	ra, rb := []rune(typed), []rune(expected)
+
+	// distances[i][j] is the distance between the first i typed and the first j expected characters
+	distances := make([][]int, len(ra)+1)
+	for i := range distances {
+		distances[i] = make([]int, len(rb)+1)
+		distances[i][0] = i
+	}
+	for j := range distances[0] {
+		distances[0][j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			distances[i][j] = min(distances[i-1][j]+1, distances[i][j-1]+1, distances[i-1][j-1]+cost)
+		}
+	}
+
+	// Walk back from the end, collecting edits in reverse
+	var reversed []Edit
+	add := func(op Op, r rune) {
+		reversed = append(reversed, Edit{Op: op, Text: string(r)})
+	}
+
+	i, j := len(ra), len(rb)
+	for i > 0 || j > 0 {
+		switch {
+		case i > 0 && j > 0 && ra[i-1] == rb[j-1] && distances[i][j] == distances[i-1][j-1]:
+			add(Equal, ra[i-1])
+			i, j = i-1, j-1
+		case i > 0 && j > 0 && distances[i][j] == distances[i-1][j-1]+1:
+			// Reversed, so the insertion ends up after the deletion
+			add(Insert, rb[j-1])
+			add(Delete, ra[i-1])
+			i, j = i-1, j-1
+		case i > 0 && distances[i][j] == distances[i-1][j]+1:
+			add(Delete, ra[i-1])
+			i--
+		default:
+			add(Insert, rb[j-1])
+			j--
+		}
+	}
+
+	var edits []Edit
+	for k := len(reversed) - 1; k >= 0; k-- {
+		edit := reversed[k]
+		if n := len(edits); n > 0 && edits[n-1].Op == edit.Op {
+			edits[n-1].Text += edit.Text
+			continue
+		}
+		edits = append(edits, edit)
+	}
+
+	return edits
+}
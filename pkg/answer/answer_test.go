@@ -0,0 +1,68 @@
+package answer
+
+import "testing"
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		typed    string
+		expected string
+		verdict  Verdict
+	}{
+		{"same", "house", "house", Exact},
+		{"case, punctuation and spacing", "  The House! ", "the house", Exact},
+		{"apostrophes", "dont", "don't", Exact},
+		{"missing accent", "cafe", "café", Accents},
+		{"wrong accent", "élève", "elève", Accents},
+		{"missing letter", "hous", "house", Typo},
+		{"swapped letters", "hosue", "house", Typo},
+		{"accent and typo", "kafe", "café", Typo},
+		{"typo in a short word", "cat", "car", Wrong},
+		{"too many typos", "hoses", "house", Wrong},
+		{"another word", "dog", "house", Wrong},
+		{"empty", "", "a", Wrong},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Check(tt.typed, tt.expected).Verdict; got != tt.verdict {
+				t.Errorf("Check(%q, %q) = %d, want %d", tt.typed, tt.expected, got, tt.verdict)
+			}
+		})
+	}
+}
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"house", "house", 0},
+		{"house", "hosue", 1},
+		{"kitten", "sitting", 3},
+		{"", "abc", 3},
+		{"straße", "strasse", 2},
+	}
+
+	for _, tt := range tests {
+		if got := Distance(tt.a, tt.b); got != tt.want {
+			t.Errorf("Distance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestDiff(t *testing.T) {
+	edits := Diff("hause", "house")
+	want := []Edit{{Equal, "h"}, {Delete, "a"}, {Insert, "o"}, {Equal, "use"}}
+
+	if len(edits) != len(want) {
+		t.Fatalf("Diff = %v, want %v", edits, want)
+	}
+	for i := range want {
+		if edits[i] != want[i] {
+			t.Errorf("Diff = %v, want %v", edits, want)
+			break
+		}
+	}
+}
@@ -0,0 +1,261 @@
+// Package cloze makes fill-in-the-blank exercises by blanking a word and its inflected forms in a sentence.
+package cloze
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Gap replaces the word in a cloze
+const Gap = "_____"
+
+// Cloze is a sentence with a word blanked out
+type Cloze struct {
+	Text   string // the sentence with every form of the word replaced by Gap
+	Answer string // the first form blanked out, as written in the sentence
+}
+
+// Make blanks out every form of a word in a sentence. It fails if the sentence doesn't contain the word.
+func Make(sentence, word string) (Cloze, bool) {
+	pattern := formsPattern(word)
+	if pattern == nil {
+		return Cloze{}, false
+	}
+
+	var text strings.Builder
+	var answer string
+	last := 0
+	for _, match := range pattern.FindAllStringIndex(sentence, -1) {
+		start, end := match[0], match[1]
+		if !isBoundary(sentence, start, end) {
+			continue
+		}
+
+		if answer == "" {
+			answer = sentence[start:end]
+		}
+		text.WriteString(sentence[last:start])
+		text.WriteString(Gap)
+		last = end
+	}
+
+	if answer == "" {
+		return Cloze{}, false
+	}
+
+	text.WriteString(sentence[last:])
+	return Cloze{Text: text.String(), Answer: answer}, true
+}
+
+// Pick makes a cloze out of the first example that contains the word
+func Pick(examples []string, word string) (Cloze, bool) {
+	for _, example := range examples {
+		if c, ok := Make(example, word); ok {
+			return c, true
+		}
+	}
+	return Cloze{}, false
+}
+
+// formsPattern matches the forms of a word, longest first, ignoring case and spacing between the words of a phrase
+func formsPattern(word string) *regexp.Regexp {
+	forms := Forms(word)
+	if len(forms) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(forms, func(i, j int) bool {
+		return len([]rune(forms[i])) > len([]rune(forms[j]))
+	})
+
+	alternatives := make([]string, len(forms))
+	for i, form := range forms {
+		words := strings.Fields(form)
+		for k := range words {
+			words[k] = regexp.QuoteMeta(words[k])
+		}
+		alternatives[i] = strings.Join(words, `\s+`)
+	}
+
+	pattern, err := regexp.Compile(`(?i)(?:` + strings.Join(alternatives, "|") + `)`)
+	if err != nil {
+		return nil
+	}
+	return pattern
+}
+
+// isBoundary checks that a match isn't part of a longer word
+func isBoundary(text string, start, end int) bool {
+	if before, _ := utf8.DecodeLastRuneInString(text[:start]); start > 0 && isWordRune(before) {
+		return false
+	}
+	if after, _ := utf8.DecodeRuneInString(text[end:]); end < len(text) && isWordRune(after) {
+		return false
+	}
+	return true
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r)
+}
+
+// Forms returns a word or phrase with its inflected forms, lowercased. The first and last word
+// of a phrase are inflected, as in "give up" and "ice cream".
+func Forms(word string) []string {
+	words := strings.Fields(strings.ToLower(word))
+	if len(words) == 0 {
+		return nil
+	}
+
+	seen := map[string]bool{}
+	var forms []string
+	add := func(form string) {
+		if !seen[form] {
+			seen[form] = true
+			forms = append(forms, form)
+		}
+	}
+
+	if len(words) == 1 {
+		for _, form := range inflect(words[0]) {
+			add(form)
+		}
+		return forms
+	}
+
+	first, middle, last := words[0], words[1:len(words)-1], words[len(words)-1]
+	for _, form := range inflect(first) {
+		add(strings.Join(append(append([]string{form}, middle...), last), " "))
+	}
+	for _, form := range inflect(last) {
+		add(strings.Join(append(append([]string{first}, middle...), form), " "))
+	}
+	return forms
+}
+
+// irregular maps common irregular English words to their forms
+var irregular = map[string][]string{
+	"be":         {"am", "is", "are", "was", "were", "been", "being"},
+	"have":       {"has", "had", "having"},
+	"do":         {"does", "did", "done", "doing"},
+	"go":         {"goes", "went", "gone", "going"},
+	"get":        {"got", "gotten"},
+	"make":       {"made"},
+	"take":       {"took", "taken"},
+	"come":       {"came"},
+	"see":        {"saw", "seen"},
+	"know":       {"knew", "known"},
+	"give":       {"gave", "given"},
+	"find":       {"found"},
+	"think":      {"thought"},
+	"tell":       {"told"},
+	"become":     {"became"},
+	"leave":      {"left"},
+	"feel":       {"felt"},
+	"bring":      {"brought"},
+	"begin":      {"began", "begun"},
+	"keep":       {"kept"},
+	"hold":       {"held"},
+	"write":      {"wrote", "written"},
+	"stand":      {"stood"},
+	"run":        {"ran"},
+	"speak":      {"spoke", "spoken"},
+	"buy":        {"bought"},
+	"catch":      {"caught"},
+	"teach":      {"taught"},
+	"fall":       {"fell", "fallen"},
+	"eat":        {"ate", "eaten"},
+	"drink":      {"drank", "drunk"},
+	"swim":       {"swam", "swum"},
+	"sing":       {"sang", "sung"},
+	"break":      {"broke", "broken"},
+	"choose":     {"chose", "chosen"},
+	"forget":     {"forgot", "forgotten"},
+	"lose":       {"lost"},
+	"meet":       {"met"},
+	"pay":        {"paid"},
+	"say":        {"said"},
+	"sell":       {"sold"},
+	"send":       {"sent"},
+	"sit":        {"sat"},
+	"sleep":      {"slept"},
+	"spend":      {"spent"},
+	"understand": {"understood"},
+	"win":        {"won"},
+	"wear":       {"wore", "worn"},
+	"drive":      {"drove", "driven"},
+	"ride":       {"rode", "ridden"},
+	"rise":       {"rose", "risen"},
+	"fly":        {"flew", "flown"},
+	"grow":       {"grew", "grown"},
+	"throw":      {"threw", "thrown"},
+	"draw":       {"drew", "drawn"},
+	"seek":       {"sought"},
+	"fight":      {"fought"},
+	"lead":       {"led"},
+	"mean":       {"meant"},
+	"hide":       {"hid", "hidden"},
+	"shake":      {"shook", "shaken"},
+	"steal":      {"stole", "stolen"},
+	"tear":       {"tore", "torn"},
+	"freeze":     {"froze", "frozen"},
+	"child":      {"children"},
+	"man":        {"men"},
+	"woman":      {"women"},
+	"person":     {"people"},
+	"mouse":      {"mice"},
+	"foot":       {"feet"},
+	"tooth":      {"teeth"},
+	"good":       {"better", "best"},
+	"bad":        {"worse", "worst"},
+}
+
+// inflect returns a single word with its regular and irregular English inflections.
+// Words shorter than three letters are only matched as they are, as most of their
+// suffixed forms would be other words.
+func inflect(word string) []string {
+	forms := []string{word}
+	forms = append(forms, irregular[word]...)
+
+	runes := []rune(word)
+	n := len(runes)
+	if n < 3 {
+		return forms
+	}
+
+	forms = append(forms, word+"s", word+"es", word+"ed", word+"ing", word+"er", word+"est", word+"ly")
+
+	last := runes[n-1]
+	stem := string(runes[:n-1])
+	switch {
+	case last == 'e':
+		forms = append(forms, word+"d", word+"r", word+"st", stem+"ing")
+		if runes[n-2] == 'i' {
+			forms = append(forms, string(runes[:n-2])+"ying")
+		}
+		if runes[n-2] == 'f' {
+			forms = append(forms, string(runes[:n-2])+"ves")
+		}
+	case last == 'y' && !isVowel(runes[n-2]):
+		forms = append(forms, stem+"ies", stem+"ied", stem+"ier", stem+"iest", stem+"ily")
+	case last == 'f':
+		forms = append(forms, stem+"ves")
+	case isConsonant(last) && last != 'w' && last != 'x' && last != 'y' && isVowel(runes[n-2]) && isConsonant(runes[n-3]):
+		// Short vowels double the final consonant, as in "running" and "stopped"
+		doubled := word + string(last)
+		forms = append(forms, doubled+"ed", doubled+"ing", doubled+"er", doubled+"est")
+	}
+
+	return forms
+}
+
+func isVowel(r rune) bool {
+	return strings.ContainsRune("aeiou", r)
+}
+
+func isConsonant(r rune) bool {
+	return r >= 'a' && r <= 'z' && !isVowel(r)
+}
@@ -0,0 +1,49 @@
+package cloze
+
+import "testing"
+
+func TestMake(t *testing.T) {
+	tests := []struct {
+		name     string
+		sentence string
+		word     string
+		text     string
+		answer   string
+		ok       bool
+	}{
+		{"word as is", "I run every day", "run", "I _____ every day", "run", true},
+		{"doubled consonant", "She is running late", "run", "She is _____ late", "running", true},
+		{"irregular verb", "He ran home", "run", "He _____ home", "ran", true},
+		{"irregular plural", "The children played", "child", "The _____ played", "children", true},
+		{"y to ies", "He studies hard", "study", "He _____ hard", "studies", true},
+		{"silent e", "We were making tea", "make", "We were _____ tea", "making", true},
+		{"phrase", "She gave up smoking", "give up", "She _____ smoking", "gave up", true},
+		{"capitalized", "Running is fun", "run", "_____ is fun", "Running", true},
+		{"every occurrence", "Run, run!", "run", "_____, _____!", "Run", true},
+		{"part of a longer word", "The rerun aired", "run", "", "", false},
+		{"missing", "Nothing here", "run", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, ok := Make(tt.sentence, tt.word)
+			if ok != tt.ok {
+				t.Fatalf("Make(%q, %q) ok = %v, want %v", tt.sentence, tt.word, ok, tt.ok)
+			}
+			if c.Text != tt.text || c.Answer != tt.answer {
+				t.Errorf("Make(%q, %q) = %q, %q, want %q, %q", tt.sentence, tt.word, c.Text, c.Answer, tt.text, tt.answer)
+			}
+		})
+	}
+}
+
+func TestPick(t *testing.T) {
+	c, ok := Pick([]string{"No match here", "I was running"}, "run")
+	if !ok || c.Text != "I was _____" || c.Answer != "running" {
+		t.Errorf("Pick = %q, %q, %v, want the second example", c.Text, c.Answer, ok)
+	}
+
+	if _, ok := Pick(nil, "run"); ok {
+		t.Error("Pick found a cloze without examples")
+	}
+}
@@ -20,3 +20,10 @@ type Algorithm interface {
 	// Returns: next review date, new interval (days), new ease factor
 	CalculateNextReview(review *models.Review, difficulty int) (time.Time, int, float64)
 }
+
+// MemoryModel is implemented by algorithms that track per-card memory state
+type MemoryModel interface {
+	// NextMemoryState calculates the card's memory state after a review with the given rating
+	// Returns: new stability (days), new difficulty
+	NextMemoryState(review *models.Review, quality int) (float64, float64)
+}
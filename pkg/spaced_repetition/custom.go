@@ -12,6 +12,9 @@ type CustomAlgorithm struct {
 	// Minimum ease factor
 	MinEaseFactor float64
 
+	// Maximum ease factor (0 means no upper bound)
+	MaxEaseFactor float64
+
 	// Default ease factor for new cards
 	DefaultEaseFactor float64
 
@@ -64,6 +67,9 @@ func (a *CustomAlgorithm) CalculateNextReview(review *models.Review, quality int
 	if easeFactor < a.MinEaseFactor {
 		easeFactor = a.MinEaseFactor
 	}
+	if a.MaxEaseFactor > 0 && easeFactor > a.MaxEaseFactor {
+		easeFactor = a.MaxEaseFactor
+	}
 
 	// Calculate new interval
 	var interval int
@@ -109,22 +115,27 @@ func (a *CustomAlgorithm) CalculateNextReview(review *models.Review, quality int
 func (a *CustomAlgorithm) SetParameter(name string, value interface{}) error {
 	switch name {
 	case "min_ease_factor":
-		if val, ok := value.(float64); ok {
+		if val, ok := floatParameter(value); ok && val >= 1.0 {
 			a.MinEaseFactor = val
 			return nil
 		}
+	case "max_ease_factor":
+		if val, ok := floatParameter(value); ok && (val == 0 || val >= 1.0) {
+			a.MaxEaseFactor = val
+			return nil
+		}
 	case "default_ease_factor":
-		if val, ok := value.(float64); ok {
+		if val, ok := floatParameter(value); ok && val >= 1.0 {
 			a.DefaultEaseFactor = val
 			return nil
 		}
 	case "max_interval":
-		if val, ok := value.(int); ok {
+		if val, ok := intParameter(value); ok && val >= 1 {
 			a.MaxInterval = val
 			return nil
 		}
 	case "initial_intervals":
-		if val, ok := value.([]int); ok {
+		if val, ok := intSliceParameter(value); ok && len(val) > 0 && validIntervals(val) {
 			a.InitialIntervals = val
 			return nil
 		}
@@ -132,3 +143,13 @@ func (a *CustomAlgorithm) SetParameter(name string, value interface{}) error {
 
 	return models.ErrInvalidParameter
 }
+
+// validIntervals checks that intervals are positive and non-decreasing
+func validIntervals(intervals []int) bool {
+	for i, interval := range intervals {
+		if interval < 1 || (i > 0 && interval < intervals[i-1]) {
+			return false
+		}
+	}
+	return true
+}
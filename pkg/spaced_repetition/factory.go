@@ -1,6 +1,8 @@
 package spaced_repetition
 
 import (
+	"math"
+
 	"github.com/supercakecrumb/flash-cards-language-tg-bot/internal/domain/models"
 )
 
@@ -8,13 +10,11 @@ import (
 const (
 	AlgorithmTypeSM2    = "sm2"
 	AlgorithmTypeCustom = "custom"
+	AlgorithmTypeFSRS   = "fsrs"
 )
 
 // AlgorithmConfig represents configuration for a spaced repetition algorithm
-type AlgorithmConfig struct {
-	Type       string                 `json:"type"`
-	Parameters map[string]interface{} `json:"parameters"`
-}
+type AlgorithmConfig = models.AlgorithmConfig
 
 // CreateAlgorithm creates a spaced repetition algorithm based on configuration
 func CreateAlgorithm(config AlgorithmConfig) (Algorithm, error) {
@@ -32,9 +32,91 @@ func CreateAlgorithm(config AlgorithmConfig) (Algorithm, error) {
 			}
 		}
 
+		// Ease bounds are set independently, so check them together
+		if algorithm.MaxEaseFactor != 0 && algorithm.MaxEaseFactor < algorithm.MinEaseFactor {
+			return nil, models.ErrInvalidParameter
+		}
+
+		return algorithm, nil
+
+	case AlgorithmTypeFSRS:
+		algorithm := NewFSRSAlgorithm()
+
+		// Apply custom parameters
+		for name, value := range config.Parameters {
+			if err := algorithm.SetParameter(name, value); err != nil {
+				return nil, err
+			}
+		}
+
 		return algorithm, nil
 
 	default:
 		return nil, models.ErrInvalidParameter
 	}
 }
+
+// Parameter values may arrive as native Go types or decoded from JSON
+// (numbers as float64, lists as []interface{}), so the helpers below accept both.
+
+// floatParameter converts a parameter value to float64
+func floatParameter(value interface{}) (float64, bool) {
+	switch val := value.(type) {
+	case float64:
+		return val, true
+	case int:
+		return float64(val), true
+	}
+	return 0, false
+}
+
+// intParameter converts a parameter value to int
+func intParameter(value interface{}) (int, bool) {
+	switch val := value.(type) {
+	case int:
+		return val, true
+	case float64:
+		if val == math.Trunc(val) {
+			return int(val), true
+		}
+	}
+	return 0, false
+}
+
+// intSliceParameter converts a parameter value to []int
+func intSliceParameter(value interface{}) ([]int, bool) {
+	switch val := value.(type) {
+	case []int:
+		return val, true
+	case []interface{}:
+		result := make([]int, 0, len(val))
+		for _, item := range val {
+			i, ok := intParameter(item)
+			if !ok {
+				return nil, false
+			}
+			result = append(result, i)
+		}
+		return result, true
+	}
+	return nil, false
+}
+
+// floatSliceParameter converts a parameter value to []float64
+func floatSliceParameter(value interface{}) ([]float64, bool) {
+	switch val := value.(type) {
+	case []float64:
+		return val, true
+	case []interface{}:
+		result := make([]float64, 0, len(val))
+		for _, item := range val {
+			f, ok := floatParameter(item)
+			if !ok {
+				return nil, false
+			}
+			result = append(result, f)
+		}
+		return result, true
+	}
+	return nil, false
+}
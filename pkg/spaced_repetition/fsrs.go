@@ -0,0 +1,163 @@
+package spaced_repetition
+
+import (
+	"math"
+	"time"
+
+	"github.com/supercakecrumb/flash-cards-language-tg-bot/internal/domain/models"
+)
+
+// FSRS forgetting curve constants
+const (
+	fsrsDecay  = -0.5
+	fsrsFactor = 19.0 / 81.0
+)
+
+// FSRSAlgorithm implements the Free Spaced Repetition Scheduler (FSRS-4.5)
+type FSRSAlgorithm struct {
+	// Probability of recall the schedule aims for when the card comes due
+	RequestRetention float64
+
+	// Maximum interval (in days)
+	MaxInterval int
+
+	// Model weights (w0..w16)
+	Weights []float64
+}
+
+// NewFSRSAlgorithm creates a new FSRS algorithm with default parameters
+func NewFSRSAlgorithm() *FSRSAlgorithm {
+	return &FSRSAlgorithm{
+		RequestRetention: 0.9,
+		MaxInterval:      36500,
+		Weights: []float64{
+			0.4872, 1.4003, 3.7145, 13.8206, 5.1618, 1.2298, 0.8975, 0.031, 1.6474,
+			0.1367, 1.0461, 2.1072, 0.0793, 0.3246, 1.587, 0.2272, 2.8755,
+		},
+	}
+}
+
+// CalculateNextReview implements the Algorithm interface
+func (a *FSRSAlgorithm) CalculateNextReview(review *models.Review, quality int) (time.Time, int, float64) {
+	stability, _ := a.NextMemoryState(review, quality)
+
+	// Calculate the interval at which recall probability drops to the requested retention
+	interval := int(math.Round(stability / fsrsFactor * (math.Pow(a.RequestRetention, 1/fsrsDecay) - 1)))
+
+	// Ensure minimum interval of 1 day
+	if interval < 1 {
+		interval = 1
+	}
+
+	// Apply maximum interval
+	if interval > a.MaxInterval {
+		interval = a.MaxInterval
+	}
+
+	// Calculate next review date
+	nextReview := time.Now().AddDate(0, 0, interval)
+
+	// FSRS does not use the ease factor, keep it unchanged
+	return nextReview, interval, review.EaseFactor
+}
+
+// NextMemoryState implements the MemoryModel interface
+func (a *FSRSAlgorithm) NextMemoryState(review *models.Review, quality int) (float64, float64) {
+	// FSRS grades run from 1 (Again) to 4 (Easy)
+	grade := float64(quality + 1)
+
+	// First review of the card
+	if review.Stability == 0 {
+		return a.initialStability(grade), a.initialDifficulty(grade)
+	}
+
+	// Calculate retrievability at the moment of the review
+	var elapsedDays float64
+	if !review.LastReviewed.IsZero() {
+		elapsedDays = math.Max(0, time.Since(review.LastReviewed).Hours()/24)
+	}
+	retrievability := math.Pow(1+fsrsFactor*elapsedDays/review.Stability, fsrsDecay)
+
+	difficulty := review.Difficulty
+	if difficulty == 0 {
+		difficulty = a.initialDifficulty(grade)
+	}
+
+	var stability float64
+	if quality == QualityAgain {
+		stability = a.forgetStability(difficulty, review.Stability, retrievability)
+	} else {
+		stability = a.recallStability(difficulty, review.Stability, retrievability, quality)
+	}
+
+	return stability, a.nextDifficulty(difficulty, grade)
+}
+
+// initialStability returns the stability of a card after its first review
+func (a *FSRSAlgorithm) initialStability(grade float64) float64 {
+	return math.Max(a.Weights[int(grade)-1], 0.1)
+}
+
+// initialDifficulty returns the difficulty of a card after its first review
+func (a *FSRSAlgorithm) initialDifficulty(grade float64) float64 {
+	return clampDifficulty(a.Weights[4] - (grade-3)*a.Weights[5])
+}
+
+// nextDifficulty returns the difficulty after a review, reverting towards the mean
+func (a *FSRSAlgorithm) nextDifficulty(difficulty, grade float64) float64 {
+	next := difficulty - a.Weights[6]*(grade-3)
+	return clampDifficulty(a.Weights[7]*a.initialDifficulty(4) + (1-a.Weights[7])*next)
+}
+
+// recallStability returns the stability after a successful recall
+func (a *FSRSAlgorithm) recallStability(difficulty, stability, retrievability float64, quality int) float64 {
+	modifier := 1.0
+	switch quality {
+	case QualityHard:
+		modifier = a.Weights[15]
+	case QualityEasy:
+		modifier = a.Weights[16]
+	}
+
+	return stability * (1 + math.Exp(a.Weights[8])*
+		(11-difficulty)*
+		math.Pow(stability, -a.Weights[9])*
+		(math.Exp((1-retrievability)*a.Weights[10])-1)*
+		modifier)
+}
+
+// forgetStability returns the stability after a lapse
+func (a *FSRSAlgorithm) forgetStability(difficulty, stability, retrievability float64) float64 {
+	return a.Weights[11] *
+		math.Pow(difficulty, -a.Weights[12]) *
+		(math.Pow(stability+1, a.Weights[13]) - 1) *
+		math.Exp((1-retrievability)*a.Weights[14])
+}
+
+// clampDifficulty keeps difficulty within the FSRS range of 1 to 10
+func clampDifficulty(difficulty float64) float64 {
+	return math.Min(math.Max(difficulty, 1), 10)
+}
+
+// SetParameter sets a parameter of the algorithm
+func (a *FSRSAlgorithm) SetParameter(name string, value interface{}) error {
+	switch name {
+	case "request_retention":
+		if val, ok := floatParameter(value); ok && val > 0 && val < 1 {
+			a.RequestRetention = val
+			return nil
+		}
+	case "max_interval":
+		if val, ok := intParameter(value); ok && val >= 1 {
+			a.MaxInterval = val
+			return nil
+		}
+	case "weights":
+		if val, ok := floatSliceParameter(value); ok && len(val) == len(a.Weights) {
+			a.Weights = val
+			return nil
+		}
+	}
+
+	return models.ErrInvalidParameter
+}
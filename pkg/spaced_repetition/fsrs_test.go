@@ -0,0 +1,104 @@
+package spaced_repetition
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/supercakecrumb/flash-cards-language-tg-bot/internal/domain/models"
+)
+
+func TestFSRSFirstReview(t *testing.T) {
+	tests := []struct {
+		name       string
+		quality    int
+		stability  float64
+		difficulty float64
+		interval   int
+	}{
+		{"again", QualityAgain, 0.4872, 7.6214, 1},
+		{"hard", QualityHard, 1.4003, 6.3916, 1},
+		{"good", QualityGood, 3.7145, 5.1618, 4},
+		{"easy", QualityEasy, 13.8206, 3.9320, 14},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			algorithm := NewFSRSAlgorithm()
+			review := models.NewReview(1, 1, models.DirectionReverse)
+
+			stability, difficulty := algorithm.NextMemoryState(review, tt.quality)
+			if math.Abs(stability-tt.stability) > 1e-9 {
+				t.Errorf("stability = %v, want %v", stability, tt.stability)
+			}
+			if math.Abs(difficulty-tt.difficulty) > 1e-9 {
+				t.Errorf("difficulty = %v, want %v", difficulty, tt.difficulty)
+			}
+
+			_, interval, easeFactor := algorithm.CalculateNextReview(review, tt.quality)
+			if interval != tt.interval {
+				t.Errorf("interval = %d, want %d", interval, tt.interval)
+			}
+			if easeFactor != review.EaseFactor {
+				t.Errorf("ease factor = %v, want it unchanged at %v", easeFactor, review.EaseFactor)
+			}
+		})
+	}
+}
+
+func TestFSRSLaterReview(t *testing.T) {
+	algorithm := NewFSRSAlgorithm()
+
+	// Reviewed when recall had dropped to 90%
+	newReview := func() *models.Review {
+		review := models.NewReview(1, 1, models.DirectionReverse)
+		review.State = models.ReviewStateReview
+		review.Stability = 10
+		review.Difficulty = 5
+		review.Interval = 10
+		review.LastReviewed = time.Now().AddDate(0, 0, -10)
+		return review
+	}
+
+	stabilities := make(map[int]float64)
+	difficulties := make(map[int]float64)
+	for _, quality := range []int{QualityAgain, QualityHard, QualityGood, QualityEasy} {
+		review := newReview()
+		stabilities[quality], difficulties[quality] = algorithm.NextMemoryState(review, quality)
+
+		_, interval, _ := algorithm.CalculateNextReview(review, quality)
+		if want := max(int(math.Round(stabilities[quality])), 1); interval != want {
+			t.Errorf("quality %d: interval = %d, want %d", quality, interval, want)
+		}
+	}
+
+	if stabilities[QualityAgain] >= 10 {
+		t.Errorf("a lapse should lower stability, got %v", stabilities[QualityAgain])
+	}
+	if !(10 < stabilities[QualityHard] && stabilities[QualityHard] < stabilities[QualityGood] && stabilities[QualityGood] < stabilities[QualityEasy]) {
+		t.Errorf("recalls should raise stability more the easier they were, got %v", stabilities)
+	}
+	if !(difficulties[QualityAgain] > difficulties[QualityHard] && difficulties[QualityHard] > difficulties[QualityGood] && difficulties[QualityGood] > difficulties[QualityEasy]) {
+		t.Errorf("difficulty should fall the easier the answer, got %v", difficulties)
+	}
+	for quality, difficulty := range difficulties {
+		if difficulty < 1 || difficulty > 10 {
+			t.Errorf("quality %d: difficulty %v out of range 1-10", quality, difficulty)
+		}
+	}
+}
+
+func TestFSRSMaxInterval(t *testing.T) {
+	algorithm := NewFSRSAlgorithm()
+	algorithm.MaxInterval = 30
+
+	review := models.NewReview(1, 1, models.DirectionReverse)
+	review.State = models.ReviewStateReview
+	review.Stability = 1000
+	review.Difficulty = 5
+	review.LastReviewed = time.Now().AddDate(0, 0, -1000)
+
+	if _, interval, _ := algorithm.CalculateNextReview(review, QualityEasy); interval != 30 {
+		t.Errorf("interval = %d, want the maximum of 30", interval)
+	}
+}
@@ -0,0 +1,143 @@
+package spaced_repetition
+
+import (
+	"time"
+
+	"github.com/supercakecrumb/flash-cards-language-tg-bot/internal/domain/models"
+)
+
+// Default learning steps, as in Anki
+var (
+	DefaultLearningSteps   = []time.Duration{time.Minute, 10 * time.Minute}
+	DefaultRelearningSteps = []time.Duration{10 * time.Minute}
+)
+
+// ScheduledReview represents the review state after an answer
+type ScheduledReview struct {
+	DueDate    time.Time
+	Interval   int // in days
+	EaseFactor float64
+	Stability  float64
+	Difficulty float64
+	State      string
+	Step       int
+}
+
+// StepScheduler wraps an algorithm with intra-day learning and relearning steps.
+// New cards go through the learning steps before the algorithm schedules them in days,
+// and lapsed cards go through the relearning steps before returning to review.
+type StepScheduler struct {
+	Algorithm       Algorithm
+	LearningSteps   []time.Duration
+	RelearningSteps []time.Duration
+}
+
+// NewStepScheduler creates a new step scheduler
+func NewStepScheduler(algorithm Algorithm, learningSteps, relearningSteps []time.Duration) *StepScheduler {
+	return &StepScheduler{
+		Algorithm:       algorithm,
+		LearningSteps:   learningSteps,
+		RelearningSteps: relearningSteps,
+	}
+}
+
+// Schedule calculates the review state after an answer with the given rating
+func (s *StepScheduler) Schedule(review *models.Review, quality int) ScheduledReview {
+	now := time.Now()
+
+	// Keep the current values unless the answer changes them
+	result := ScheduledReview{
+		DueDate:    review.DueDate,
+		Interval:   review.Interval,
+		EaseFactor: review.EaseFactor,
+		Stability:  review.Stability,
+		Difficulty: review.Difficulty,
+		State:      review.State,
+		Step:       review.Step,
+	}
+
+	switch review.State {
+	case models.ReviewStateReview:
+		result = s.graduate(review, quality)
+
+		// A lapse sends the card through the relearning steps
+		if quality == QualityAgain && len(s.RelearningSteps) > 0 {
+			result.State = models.ReviewStateRelearning
+			result.Step = 0
+			result.DueDate = now.Add(s.RelearningSteps[0])
+		}
+
+	case models.ReviewStateRelearning:
+		next, ok := nextStep(s.RelearningSteps, review.Step, quality)
+		if !ok {
+			// Relearned, the interval was already reduced by the lapse
+			result.State = models.ReviewStateReview
+			result.Step = 0
+			result.DueDate = now.AddDate(0, 0, max(review.Interval, 1))
+			return result
+		}
+
+		result.Step = next
+		result.DueDate = now.Add(s.RelearningSteps[next])
+
+	default:
+		// New and learning cards
+		next, ok := nextStep(s.LearningSteps, review.Step, quality)
+		if !ok {
+			return s.graduate(review, quality)
+		}
+
+		result.State = models.ReviewStateLearning
+		result.Step = next
+		result.DueDate = now.Add(s.LearningSteps[next])
+	}
+
+	return result
+}
+
+// graduate schedules the card in days using the wrapped algorithm
+func (s *StepScheduler) graduate(review *models.Review, quality int) ScheduledReview {
+	dueDate, interval, easeFactor := s.Algorithm.CalculateNextReview(review, quality)
+
+	result := ScheduledReview{
+		DueDate:    dueDate,
+		Interval:   interval,
+		EaseFactor: easeFactor,
+		Stability:  review.Stability,
+		Difficulty: review.Difficulty,
+		State:      models.ReviewStateReview,
+		Step:       0,
+	}
+
+	// Update memory state for algorithms that model it
+	if memoryModel, ok := s.Algorithm.(MemoryModel); ok {
+		result.Stability, result.Difficulty = memoryModel.NextMemoryState(review, quality)
+	}
+
+	return result
+}
+
+// nextStep returns the step to go to after an answer, or false if the card leaves the steps
+func nextStep(steps []time.Duration, step, quality int) (int, bool) {
+	if len(steps) == 0 {
+		return 0, false
+	}
+
+	switch quality {
+	case QualityAgain:
+		// Back to the first step
+		return 0, true
+	case QualityHard:
+		// Repeat the current step
+		return min(step, len(steps)-1), true
+	case QualityGood:
+		// Advance to the next step
+		if step+1 < len(steps) {
+			return step + 1, true
+		}
+		return 0, false
+	default:
+		// Easy leaves the steps immediately
+		return 0, false
+	}
+}
@@ -0,0 +1,114 @@
+package spaced_repetition
+
+import (
+	"testing"
+	"time"
+
+	"github.com/supercakecrumb/flash-cards-language-tg-bot/internal/domain/models"
+)
+
+func TestStepSchedulerSchedule(t *testing.T) {
+	newCard := func() *models.Review {
+		return models.NewReview(1, 1, models.DirectionReverse)
+	}
+	learning := func(step int) *models.Review {
+		review := newCard()
+		review.State = models.ReviewStateLearning
+		review.Step = step
+		return review
+	}
+	graduated := func() *models.Review {
+		review := newCard()
+		review.State = models.ReviewStateReview
+		review.Stability = 10
+		review.Difficulty = 5
+		review.Interval = 10
+		review.LastReviewed = time.Now().AddDate(0, 0, -10)
+		return review
+	}
+	relearning := func() *models.Review {
+		review := graduated()
+		review.State = models.ReviewStateRelearning
+		review.Interval = 3
+		return review
+	}
+
+	tests := []struct {
+		name     string
+		review   *models.Review
+		quality  int
+		state    string
+		step     int
+		due      time.Duration // from now, for cards in steps
+		interval int           // in days, for cards in review
+	}{
+		{"new again", newCard(), QualityAgain, models.ReviewStateLearning, 0, time.Minute, 0},
+		{"new hard", newCard(), QualityHard, models.ReviewStateLearning, 0, time.Minute, 0},
+		{"new good", newCard(), QualityGood, models.ReviewStateLearning, 1, 10 * time.Minute, 0},
+		{"new easy graduates", newCard(), QualityEasy, models.ReviewStateReview, 0, 0, 14},
+		{"learning again restarts", learning(1), QualityAgain, models.ReviewStateLearning, 0, time.Minute, 0},
+		{"learning hard repeats", learning(1), QualityHard, models.ReviewStateLearning, 1, 10 * time.Minute, 0},
+		{"last learning step graduates", learning(1), QualityGood, models.ReviewStateReview, 0, 0, 4},
+		{"review lapse relearns", graduated(), QualityAgain, models.ReviewStateRelearning, 0, 10 * time.Minute, 0},
+		{"relearning again restarts", relearning(), QualityAgain, models.ReviewStateRelearning, 0, 10 * time.Minute, 0},
+		{"relearned", relearning(), QualityGood, models.ReviewStateReview, 0, 0, 3},
+	}
+
+	scheduler := NewStepScheduler(NewFSRSAlgorithm(), DefaultLearningSteps, DefaultRelearningSteps)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Now()
+			result := scheduler.Schedule(tt.review, tt.quality)
+
+			if result.State != tt.state {
+				t.Errorf("state = %q, want %q", result.State, tt.state)
+			}
+			if result.Step != tt.step {
+				t.Errorf("step = %d, want %d", result.Step, tt.step)
+			}
+
+			want := now.Add(tt.due)
+			if tt.state == models.ReviewStateReview {
+				if result.Interval != tt.interval {
+					t.Errorf("interval = %d, want %d", result.Interval, tt.interval)
+				}
+				want = now.AddDate(0, 0, tt.interval)
+			}
+			if diff := result.DueDate.Sub(want); diff < -time.Second || diff > time.Second {
+				t.Errorf("due %v, want %v", result.DueDate, want)
+			}
+		})
+	}
+}
+
+func TestStepSchedulerReviewGood(t *testing.T) {
+	review := models.NewReview(1, 1, models.DirectionReverse)
+	review.State = models.ReviewStateReview
+	review.Stability = 10
+	review.Difficulty = 5
+	review.Interval = 10
+	review.LastReviewed = time.Now().AddDate(0, 0, -10)
+
+	scheduler := NewStepScheduler(NewFSRSAlgorithm(), DefaultLearningSteps, DefaultRelearningSteps)
+	result := scheduler.Schedule(review, QualityGood)
+
+	if result.State != models.ReviewStateReview {
+		t.Errorf("state = %q, want %q", result.State, models.ReviewStateReview)
+	}
+	if result.Interval <= review.Interval {
+		t.Errorf("interval = %d, want more than %d", result.Interval, review.Interval)
+	}
+	if result.Stability <= review.Stability {
+		t.Errorf("stability = %v, want more than %v", result.Stability, review.Stability)
+	}
+}
+
+func TestStepSchedulerWithoutSteps(t *testing.T) {
+	scheduler := NewStepScheduler(NewFSRSAlgorithm(), nil, nil)
+
+	result := scheduler.Schedule(models.NewReview(1, 1, models.DirectionReverse), QualityAgain)
+	if result.State != models.ReviewStateReview || result.Interval != 1 {
+		t.Errorf("got state %q and interval %d, want a new card to go straight to review in 1 day", result.State, result.Interval)
+	}
+}
@@ -0,0 +1,25 @@
+package spaced_repetition
+
+import "time"
+
+// Answer times that make a correct answer easy or hard when grading by time
+const (
+	FastAnswer = 5 * time.Second
+	SlowAnswer = 15 * time.Second
+)
+
+// TimedQuality adjusts the rating of an answer by how long it took: good answers within FastAnswer
+// become easy and good or easy answers slower than SlowAnswer become hard. Hard and wrong answers
+// and answers of unknown latency keep their rating.
+func TimedQuality(quality int, latency time.Duration) int {
+	switch {
+	case quality == QualityAgain, latency <= 0:
+		return quality
+	case latency > SlowAnswer && (quality == QualityGood || quality == QualityEasy):
+		return QualityHard
+	case latency <= FastAnswer && quality == QualityGood:
+		return QualityEasy
+	default:
+		return quality
+	}
+}
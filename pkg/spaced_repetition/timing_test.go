@@ -0,0 +1,35 @@
+package spaced_repetition
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimedQuality(t *testing.T) {
+	tests := []struct {
+		name    string
+		quality int
+		latency time.Duration
+		want    int
+	}{
+		{"unknown latency", QualityGood, 0, QualityGood},
+		{"again stays again", QualityAgain, time.Second, QualityAgain},
+		{"slow again stays again", QualityAgain, time.Minute, QualityAgain},
+		{"fast good is easy", QualityGood, FastAnswer, QualityEasy},
+		{"fast hard stays hard", QualityHard, time.Second, QualityHard},
+		{"fast easy stays easy", QualityEasy, time.Second, QualityEasy},
+		{"good in between", QualityGood, FastAnswer + time.Millisecond, QualityGood},
+		{"good at the slow limit", QualityGood, SlowAnswer, QualityGood},
+		{"slow good is hard", QualityGood, SlowAnswer + time.Millisecond, QualityHard},
+		{"slow easy is hard", QualityEasy, time.Minute, QualityHard},
+		{"slow hard stays hard", QualityHard, time.Minute, QualityHard},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TimedQuality(tt.quality, tt.latency); got != tt.want {
+				t.Errorf("TimedQuality(%d, %v) = %d, want %d", tt.quality, tt.latency, got, tt.want)
+			}
+		})
+	}
+}